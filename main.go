@@ -2,8 +2,12 @@ package main
 
 import (
 	"blog_api/src/cmd"
+	"os"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(cmd.Migrate(os.Args[2:]))
+	}
 	cmd.Run()
 }
@@ -0,0 +1,2 @@
+-- 回滚 001_01_create_frined_link.sql
+DROP TABLE IF EXISTS friend_link;
@@ -0,0 +1,2 @@
+-- 回滚 001_02_create_friend_rss.sql
+DROP TABLE IF EXISTS friend_rss;
@@ -0,0 +1,2 @@
+-- 回滚 001_03_create_rss_post.sql
+DROP TABLE IF EXISTS friend_rss_post;
@@ -0,0 +1,2 @@
+-- 回滚 002_01_create_image_repo.sql
+DROP TABLE IF EXISTS images;
@@ -0,0 +1,2 @@
+-- 回滚 003_01_create_moments_table.sql
+DROP TABLE IF EXISTS moments;
@@ -0,0 +1,2 @@
+-- 回滚 003_02_create_moments_media.sql
+DROP TABLE IF EXISTS moments_media;
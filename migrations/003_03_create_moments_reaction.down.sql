@@ -0,0 +1,2 @@
+-- 回滚 003_03_create_moments_reaction.sql
+DROP TABLE IF EXISTS moment_reactions;
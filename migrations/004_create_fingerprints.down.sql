@@ -0,0 +1,2 @@
+-- 回滚 004_create_fingerprints.sql
+DROP TABLE IF EXISTS fingerprints;
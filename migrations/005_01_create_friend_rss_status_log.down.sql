@@ -0,0 +1,2 @@
+-- 回滚 005_01_create_friend_rss_status_log.sql
+DROP TABLE IF EXISTS friend_rss_status_log;
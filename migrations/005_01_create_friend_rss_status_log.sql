@@ -0,0 +1,13 @@
+-- 记录 RSS 订阅源的状态变迁
+CREATE TABLE IF NOT EXISTS friend_rss_status_log (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    rss_id INTEGER NOT NULL,
+    from_status TEXT NOT NULL,
+    to_status TEXT NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    created_at INTEGER NOT NULL DEFAULT (strftime('%s','now')),
+
+    FOREIGN KEY (rss_id) REFERENCES friend_rss(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_friend_rss_status_log_rss_id ON friend_rss_status_log (rss_id, created_at);
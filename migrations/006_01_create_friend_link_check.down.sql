@@ -0,0 +1,2 @@
+-- 回滚 006_01_create_friend_link_check.sql
+DROP TABLE IF EXISTS friend_link_check;
@@ -0,0 +1,16 @@
+-- 记录每次友链爬取的检查结果
+CREATE TABLE IF NOT EXISTS friend_link_check (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    friend_link_id INTEGER NOT NULL,
+    status TEXT NOT NULL,
+    http_status INTEGER NOT NULL DEFAULT 0,
+    latency_ms INTEGER NOT NULL DEFAULT 0,
+    redirect_url TEXT NOT NULL DEFAULT '',
+    error TEXT NOT NULL DEFAULT '',
+    checked_at INTEGER NOT NULL DEFAULT (strftime('%s','now')),
+
+    FOREIGN KEY (friend_link_id) REFERENCES friend_link(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_friend_link_check_link_id ON friend_link_check (friend_link_id, checked_at);
+CREATE INDEX IF NOT EXISTS idx_friend_link_check_checked_at ON friend_link_check (checked_at);
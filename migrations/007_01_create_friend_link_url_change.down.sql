@@ -0,0 +1,2 @@
+-- 回滚 007_01_create_friend_link_url_change.sql
+DROP TABLE IF EXISTS friend_link_url_change;
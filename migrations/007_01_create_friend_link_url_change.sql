@@ -0,0 +1,26 @@
+-- 友链地址变更记录：跟踪永久重定向、待审核的地址变更以及历史地址
+CREATE TABLE IF NOT EXISTS friend_link_url_change (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    friend_link_id INTEGER NOT NULL,
+    old_url TEXT NOT NULL,
+    new_url TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'observing' CHECK ( status IN (
+        'observing',
+        'pending',
+        'applied',
+        'approved',
+        'rejected',
+        'dismissed',
+        'manual'
+    )),
+    hits INTEGER NOT NULL DEFAULT 1,
+    reason TEXT NOT NULL DEFAULT '',
+    first_seen_at INTEGER NOT NULL DEFAULT (strftime('%s','now')),
+    last_seen_at INTEGER NOT NULL DEFAULT (strftime('%s','now')),
+    resolved_at INTEGER NOT NULL DEFAULT 0,
+
+    FOREIGN KEY (friend_link_id) REFERENCES friend_link(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_friend_link_url_change_link_id ON friend_link_url_change (friend_link_id, status);
+CREATE INDEX IF NOT EXISTS idx_friend_link_url_change_status ON friend_link_url_change (status);
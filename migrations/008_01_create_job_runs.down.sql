@@ -0,0 +1,2 @@
+-- 回滚 008_01_create_job_runs.sql
+DROP TABLE IF EXISTS job_runs;
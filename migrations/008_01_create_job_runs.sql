@@ -0,0 +1,19 @@
+-- 记录后台任务（定时、启动时与手动触发）的执行历史
+CREATE TABLE IF NOT EXISTS job_runs (
+    id TEXT PRIMARY KEY,
+    job TEXT NOT NULL,
+    target INTEGER NOT NULL DEFAULT 0,
+    trigger TEXT NOT NULL DEFAULT 'cron' CHECK (trigger IN ('cron', 'startup', 'manual')),
+    state TEXT NOT NULL CHECK (state IN ('queued', 'running', 'succeeded', 'failed', 'skipped')),
+    total INTEGER NOT NULL DEFAULT 0,
+    done INTEGER NOT NULL DEFAULT 0,
+    failed INTEGER NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    errors TEXT NOT NULL DEFAULT '[]',
+    created_at INTEGER NOT NULL DEFAULT (strftime('%s','now')),
+    started_at INTEGER NOT NULL DEFAULT 0,
+    finished_at INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_runs_job_created_at ON job_runs (job, created_at);
+CREATE INDEX IF NOT EXISTS idx_job_runs_created_at ON job_runs (created_at);
@@ -0,0 +1,2 @@
+-- 回滚 009_01_create_friend_link_application.sql
+DROP TABLE IF EXISTS friend_link_application;
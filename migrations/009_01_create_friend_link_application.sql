@@ -0,0 +1,29 @@
+-- 友链申请：通过邮箱令牌提交的友链在审核前保持 pending，记录预检结果与审核意见
+-- 不设外键：拒绝申请时会删除对应友链，申请记录仍需保留
+CREATE TABLE IF NOT EXISTS friend_link_application (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    friend_link_id INTEGER NOT NULL DEFAULT 0,
+    email TEXT NOT NULL,
+    website_name TEXT NOT NULL,
+    website_url TEXT NOT NULL,
+    website_icon_url TEXT NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    friends_page_url TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'pending' CHECK ( status IN (
+        'pending',
+        'approved',
+        'rejected'
+    )),
+    reason TEXT NOT NULL DEFAULT '',
+    reachable BOOLEAN,
+    http_status INTEGER NOT NULL DEFAULT 0,
+    check_error TEXT NOT NULL DEFAULT '',
+    has_backlink BOOLEAN,
+    duplicate_of TEXT NOT NULL DEFAULT '[]',
+    checked_at INTEGER NOT NULL DEFAULT 0,
+    created_at INTEGER NOT NULL DEFAULT (strftime('%s','now')),
+    reviewed_at INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_friend_link_application_status ON friend_link_application (status, created_at);
+CREATE INDEX IF NOT EXISTS idx_friend_link_application_email ON friend_link_application (email, created_at);
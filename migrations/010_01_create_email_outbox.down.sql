@@ -0,0 +1,2 @@
+-- 回滚 010_01_create_email_outbox.sql
+DROP TABLE IF EXISTS email_outbox;
@@ -0,0 +1,2 @@
+-- 回滚 011_01_create_tokens.sql
+DROP TABLE IF EXISTS tokens;
@@ -0,0 +1,12 @@
+-- 短期令牌：反机器人令牌、邮箱验证码与邮箱令牌，持久化后重启不会失效
+-- kind 区分令牌类型，过期记录由后台定期清理
+CREATE TABLE IF NOT EXISTS tokens (
+    kind TEXT NOT NULL,
+    key TEXT NOT NULL,
+    value TEXT NOT NULL DEFAULT '',
+    expires_at INTEGER NOT NULL,
+    created_at INTEGER NOT NULL DEFAULT (strftime('%s','now')),
+    PRIMARY KEY (kind, key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_tokens_expires_at ON tokens (expires_at);
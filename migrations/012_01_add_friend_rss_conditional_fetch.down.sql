@@ -0,0 +1,3 @@
+-- 回滚 012_01_add_friend_rss_conditional_fetch.sql
+ALTER TABLE friend_rss DROP COLUMN last_modified;
+ALTER TABLE friend_rss DROP COLUMN etag;
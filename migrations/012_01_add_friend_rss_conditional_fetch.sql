@@ -0,0 +1,4 @@
+-- 订阅源的 ETag 与 Last-Modified，用于条件请求
+-- skip-if-columns: friend_rss.etag friend_rss.last_modified
+ALTER TABLE friend_rss ADD COLUMN etag TEXT NOT NULL DEFAULT '';
+ALTER TABLE friend_rss ADD COLUMN last_modified TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,6 @@
+-- 回滚 012_02_add_friend_rss_health.sql
+ALTER TABLE friend_rss DROP COLUMN fail_count;
+ALTER TABLE friend_rss DROP COLUMN last_http_status;
+ALTER TABLE friend_rss DROP COLUMN last_error;
+ALTER TABLE friend_rss DROP COLUMN last_success_at;
+ALTER TABLE friend_rss DROP COLUMN last_fetched_at;
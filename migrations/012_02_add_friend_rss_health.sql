@@ -0,0 +1,7 @@
+-- 订阅源的抓取健康状态
+-- skip-if-columns: friend_rss.last_fetched_at friend_rss.last_success_at friend_rss.last_error friend_rss.last_http_status friend_rss.fail_count
+ALTER TABLE friend_rss ADD COLUMN last_fetched_at INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE friend_rss ADD COLUMN last_success_at INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE friend_rss ADD COLUMN last_error TEXT NOT NULL DEFAULT '';
+ALTER TABLE friend_rss ADD COLUMN last_http_status INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE friend_rss ADD COLUMN fail_count INTEGER NOT NULL DEFAULT 0;
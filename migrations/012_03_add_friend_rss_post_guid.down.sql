@@ -0,0 +1,5 @@
+-- 回滚 012_03_add_friend_rss_post_guid.sql
+DROP INDEX IF EXISTS idx_friend_rss_post_link;
+DROP INDEX IF EXISTS idx_friend_rss_post_guid;
+ALTER TABLE friend_rss_post DROP COLUMN updated_at;
+ALTER TABLE friend_rss_post DROP COLUMN guid;
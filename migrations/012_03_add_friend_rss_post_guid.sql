@@ -0,0 +1,6 @@
+-- 文章的 GUID 与更新时间，按 GUID 或规范化后的链接合并文章
+-- skip-if-columns: friend_rss_post.guid friend_rss_post.updated_at
+ALTER TABLE friend_rss_post ADD COLUMN guid TEXT NOT NULL DEFAULT '';
+ALTER TABLE friend_rss_post ADD COLUMN updated_at INTEGER NOT NULL DEFAULT 0;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_friend_rss_post_guid ON friend_rss_post (rss_id, guid) WHERE guid != '';
+CREATE INDEX IF NOT EXISTS idx_friend_rss_post_link ON friend_rss_post (link);
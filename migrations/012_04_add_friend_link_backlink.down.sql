@@ -0,0 +1,5 @@
+-- 回滚 012_04_add_friend_link_backlink.sql
+ALTER TABLE friend_link DROP COLUMN backlink_last_seen_at;
+ALTER TABLE friend_link DROP COLUMN backlink_checked_at;
+ALTER TABLE friend_link DROP COLUMN has_backlink;
+ALTER TABLE friend_link DROP COLUMN friends_page_url;
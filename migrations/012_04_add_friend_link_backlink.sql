@@ -0,0 +1,6 @@
+-- 友链页面地址与反向链接检查结果
+-- skip-if-columns: friend_link.friends_page_url friend_link.has_backlink friend_link.backlink_checked_at friend_link.backlink_last_seen_at
+ALTER TABLE friend_link ADD COLUMN friends_page_url TEXT NOT NULL DEFAULT '';
+ALTER TABLE friend_link ADD COLUMN has_backlink INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE friend_link ADD COLUMN backlink_checked_at INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE friend_link ADD COLUMN backlink_last_seen_at INTEGER NOT NULL DEFAULT 0;
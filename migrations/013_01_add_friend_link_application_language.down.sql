@@ -0,0 +1,2 @@
+-- 回滚 013_01_add_friend_link_application_language.sql
+ALTER TABLE friend_link_application DROP COLUMN language;
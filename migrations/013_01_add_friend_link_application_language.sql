@@ -0,0 +1,3 @@
+-- 友链申请人的语言，用于发送对应语言的通知邮件
+-- skip-if-columns: friend_link_application.language
+ALTER TABLE friend_link_application ADD COLUMN language TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+-- 回滚 014_01_add_friend_rss_post_link_key.sql
+DROP INDEX IF EXISTS idx_friend_rss_post_link_key;
+ALTER TABLE friend_rss_post DROP COLUMN link_key;
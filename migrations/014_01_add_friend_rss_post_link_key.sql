@@ -0,0 +1,4 @@
+-- 规范化后的文章链接，用于按链接判断文章是否已属于其他订阅源
+-- 已有文章由程序在迁移完成后回填
+ALTER TABLE friend_rss_post ADD COLUMN link_key TEXT NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_friend_rss_post_link_key ON friend_rss_post (link_key);
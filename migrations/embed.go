@@ -0,0 +1,15 @@
+// Package migrations embeds the SQL migration files so the binary does not depend on the working directory.
+//
+// Each NNN_name.sql file is applied once and recorded in schema_migrations;
+// the optional NNN_name.down.sql next to it reverts it. A migration that only
+// adds columns starts with a "-- skip-if-columns: table.column ..." line so
+// databases that already have those columns record it without running it.
+// Files in fts5/ are applied on every start when the SQLite driver supports FTS5.
+package migrations
+
+import "embed"
+
+// FS holds the versioned migrations and the fts5 migrations.
+//
+//go:embed *.sql fts5/*.sql
+var FS embed.FS
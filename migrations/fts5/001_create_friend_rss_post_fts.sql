@@ -0,0 +1,33 @@
+-- 文章全文索引（外部内容表，由触发器维护）
+-- trigram 分词器可直接匹配中文子串，查询词至少需要 3 个字符
+CREATE VIRTUAL TABLE IF NOT EXISTS friend_rss_post_fts USING fts5(
+    title,
+    description,
+    author,
+    content='friend_rss_post',
+    content_rowid='id',
+    tokenize='trigram'
+);
+
+CREATE TRIGGER IF NOT EXISTS trg_friend_rss_post_fts_insert
+AFTER INSERT ON friend_rss_post
+BEGIN
+  INSERT INTO friend_rss_post_fts (rowid, title, description, author)
+  VALUES (new.id, new.title, new.description, new.author);
+END;
+
+CREATE TRIGGER IF NOT EXISTS trg_friend_rss_post_fts_delete
+AFTER DELETE ON friend_rss_post
+BEGIN
+  INSERT INTO friend_rss_post_fts (friend_rss_post_fts, rowid, title, description, author)
+  VALUES ('delete', old.id, old.title, old.description, old.author);
+END;
+
+CREATE TRIGGER IF NOT EXISTS trg_friend_rss_post_fts_update
+AFTER UPDATE OF title, description, author ON friend_rss_post
+BEGIN
+  INSERT INTO friend_rss_post_fts (friend_rss_post_fts, rowid, title, description, author)
+  VALUES ('delete', old.id, old.title, old.description, old.author);
+  INSERT INTO friend_rss_post_fts (rowid, title, description, author)
+  VALUES (new.id, new.title, new.description, new.author);
+END;
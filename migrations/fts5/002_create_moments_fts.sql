@@ -0,0 +1,26 @@
+-- 动态全文索引（外部内容表，由触发器维护）
+CREATE VIRTUAL TABLE IF NOT EXISTS moments_fts USING fts5(
+    content,
+    content='moments',
+    content_rowid='id',
+    tokenize='trigram'
+);
+
+CREATE TRIGGER IF NOT EXISTS trg_moments_fts_insert
+AFTER INSERT ON moments
+BEGIN
+  INSERT INTO moments_fts (rowid, content) VALUES (new.id, new.content);
+END;
+
+CREATE TRIGGER IF NOT EXISTS trg_moments_fts_delete
+AFTER DELETE ON moments
+BEGIN
+  INSERT INTO moments_fts (moments_fts, rowid, content) VALUES ('delete', old.id, old.content);
+END;
+
+CREATE TRIGGER IF NOT EXISTS trg_moments_fts_update
+AFTER UPDATE OF content ON moments
+BEGIN
+  INSERT INTO moments_fts (moments_fts, rowid, content) VALUES ('delete', old.id, old.content);
+  INSERT INTO moments_fts (rowid, content) VALUES (new.id, new.content);
+END;
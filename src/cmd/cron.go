@@ -5,50 +5,65 @@ import (
 	"blog_api/src/model"
 	friendsRepositories "blog_api/src/repositories/friend"
 	"blog_api/src/service"
+	backupService "blog_api/src/service/backup"
 	crawlerService "blog_api/src/service/crawler"
+	jobService "blog_api/src/service/job"
+	"fmt"
 	"log"
+	"sync"
 
 	"github.com/robfig/cron/v3"
 	"gorm.io/gorm"
 )
 
 // RunFriendLinkCrawlerJob 执行友链爬取并发现 RSS 订阅源（并发模式）
-func RunFriendLinkCrawlerJob(db *gorm.DB) {
-	log.Println("[Cron] 正在运行友链爬取任务（并发模式）...")
-	isDied := false
-	opts := model.FriendLinkQueryOptions{
-		Statuses: []string{"ignored"},
-		NotIn:    true,
-		IsDied:   &isDied,
-	}
-	resp, err := friendsRepositories.QueryFriendLinks(db, opts)
-	if err != nil {
-		log.Printf("[Cron] 获取全部友链失败： %v", err)
-		return
+// linkID 大于 0 时只爬取该友链（包括已忽略或失效的友链）
+func RunFriendLinkCrawlerJob(db *gorm.DB, run *jobService.Run, linkID int) error {
+	var links []model.FriendWebsite
+	if linkID > 0 {
+		log.Printf("[Cron] 正在爬取友链 %d...", linkID)
+		link, err := friendsRepositories.GetFriendLinkByID(db, linkID)
+		if err != nil {
+			return fmt.Errorf("获取友链 %d 失败: %w", linkID, err)
+		}
+		links = []model.FriendWebsite{link}
+	} else {
+		log.Println("[Cron] 正在运行友链爬取任务（并发模式）...")
+		isDied := false
+		// 待审核的友链在通过前不爬取，避免状态被爬取结果覆盖
+		opts := model.FriendLinkQueryOptions{
+			Statuses: []string{"ignored", "pending"},
+			NotIn:    true,
+			IsDied:   &isDied,
+		}
+		resp, err := friendsRepositories.QueryFriendLinks(db, opts)
+		if err != nil {
+			log.Printf("[Cron] 获取全部友链失败： %v", err)
+			return err
+		}
+		links = resp.Links
 	}
-	links := resp.Links
 
 	if len(links) == 0 {
 		log.Println("[Cron] 没有需要爬取的友链")
-		return
+		return nil
 	}
+	run.SetTotal(len(links))
 
 	// 使用并发爬虫
-	results := crawlerService.CrawlWebsitesConcurrently(links)
+	results := crawlerService.CrawlWebsitesConcurrently(run.Context(), links)
 
 	// 处理爬取结果
 	for _, crawlResult := range results {
 		link := crawlResult.Link
 		result := crawlResult.Result
-
-		err := friendsRepositories.UpdateFriendLink(db, link, result)
-		if err != nil {
-			log.Printf("[Cron] 在 cron 任务中更新友链 %s 失败: %v", link.Name, err)
+		if !applyCrawlResult(db, run, link, result) {
+			continue
 		}
 		// 更新友链后，发现并插入 RSS 订阅源
 		if link.EnableRss && len(result.RssURLs) > 0 {
 			for _, rssURL := range result.RssURLs {
-				name, err := crawlerService.GetRssTitle(rssURL)
+				name, err := crawlerService.GetRssTitle(run.Context(), rssURL)
 				if err != nil {
 					log.Printf("[Cron] 获取 RSS 标题失败 %s: %v", rssURL, err)
 					continue
@@ -61,10 +76,11 @@ func RunFriendLinkCrawlerJob(db *gorm.DB) {
 		}
 	}
 	log.Println("[Cron] 友链爬取任务完成")
+	return nil
 }
 
 // RunDiedFriendLinkCheckJob 执行失效友链的检查（并发模式）
-func RunDiedFriendLinkCheckJob(db *gorm.DB) {
+func RunDiedFriendLinkCheckJob(db *gorm.DB, run *jobService.Run) error {
 	log.Println("[Cron] 正在运行失效友链检查任务（并发模式）...")
 	isDied := true
 	opts := model.FriendLinkQueryOptions{
@@ -73,83 +89,232 @@ func RunDiedFriendLinkCheckJob(db *gorm.DB) {
 	resp, err := friendsRepositories.QueryFriendLinks(db, opts)
 	if err != nil {
 		log.Printf("[Cron] 获取全部 died 友链失败： %v", err)
-		return
+		return err
 	}
 	links := resp.Links
 
 	if len(links) == 0 {
 		log.Println("[Cron] 没有需要检查的失效友链")
-		return
+		return nil
 	}
+	run.SetTotal(len(links))
 
 	// 使用并发爬虫
-	results := crawlerService.CrawlWebsitesConcurrently(links)
+	results := crawlerService.CrawlWebsitesConcurrently(run.Context(), links)
 
-	// 处理爬取结果
+	// 处理爬取结果，如果链接仍然有效，状态将更新为"存活"并重置计数
 	for _, crawlResult := range results {
-		link := crawlResult.Link
-		result := crawlResult.Result
-		// 如果链接仍然有效，状态将更新为"存活"并重置计数
-		err := friendsRepositories.UpdateFriendLink(db, link, result)
-		if err != nil {
-			log.Printf("[Cron] 在 cron 任务中更新失效友链 %s 失败: %v", link.Name, err)
-		}
+		applyCrawlResult(db, run, crawlResult.Link, crawlResult.Result)
 	}
 	log.Println("[Cron] 失效友链检查任务完成")
+	return nil
+}
+
+// applyCrawlResult 保存一条爬取结果并上报进度，返回结果是否已保存
+func applyCrawlResult(db *gorm.DB, run *jobService.Run, link model.FriendWebsite, result model.CrawlResult) bool {
+	item := fmt.Sprintf("friend_link %d (%s)", link.ID, link.Name)
+	if result.Status == "disallowed" {
+		log.Printf("[Cron] 友链 %s 被 robots.txt 禁止抓取，保持原状态", link.Name)
+		run.ItemFailed(item, crawlerService.ErrRobotsDisallowed)
+		return false
+	}
+
+	if err := friendsRepositories.UpdateFriendLink(db, link, result); err != nil {
+		log.Printf("[Cron] 在 cron 任务中更新友链 %s 失败: %v", link.Name, err)
+		run.ItemFailed(item, err)
+		return false
+	}
+	crawlerService.ApplyRedirectPolicy(db, link, result)
+
+	if result.Status != "survival" {
+		run.ItemFailed(item, fmt.Errorf("%s: %s", result.Status, result.Error))
+	} else {
+		run.ItemDone()
+	}
+	return true
 }
 
 // RunRssParserJob 获取所有 RSS 订阅源并解析它们（并发模式）
-func RunRssParserJob(db *gorm.DB) {
+// rssID 大于 0 时只解析该订阅源（包括已暂停的订阅源）
+func RunRssParserJob(db *gorm.DB, run *jobService.Run, rssID int) error {
+	if rssID > 0 {
+		log.Printf("[Cron] 正在解析 RSS 订阅源 %d...", rssID)
+		feed, err := friendsRepositories.GetFriendRssByID(db, rssID)
+		if err != nil {
+			return fmt.Errorf("获取 RSS 订阅源 %d 失败: %w", rssID, err)
+		}
+		run.SetTotal(1)
+		if err := crawlerService.ParseRssFeed(run.Context(), db, feed.ID, feed.RssURL); err != nil {
+			run.ItemFailed(feed.RssURL, err)
+			return nil
+		}
+		run.ItemDone()
+		return nil
+	}
+
 	log.Println("[Cron] 正在运行 RSS 解析任务（并发模式）...")
 	opts := model.FriendRssQueryOptions{Status: "valid"}
 	resp, err := friendsRepositories.QueryFriendRss(db, opts)
 	if err != nil {
 		log.Printf("[Cron] 获取所有 RSS 订阅源失败: %v", err)
-		return
+		return err
 	}
 	rssFeeds := resp.Feeds
 
 	if len(rssFeeds) == 0 {
 		log.Println("[Cron] 没有需要解析的 RSS 订阅源")
-		return
+		return nil
 	}
+	run.SetTotal(len(rssFeeds))
 
 	// 使用并发解析
-	crawlerService.ParseRssFeedsConcurrently(rssFeeds, func(friendRssID int, rssURL string) {
-		crawlerService.ParseRssFeed(db, friendRssID, rssURL)
+	ctx := run.Context()
+	crawlerService.ParseRssFeedsConcurrently(ctx, rssFeeds, func(friendRssID int, rssURL string) {
+		if err := crawlerService.ParseRssFeed(ctx, db, friendRssID, rssURL); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			run.ItemFailed(rssURL, err)
+			return
+		}
+		run.ItemDone()
 	})
 	log.Println("[Cron] RSS 解析任务完成")
+	return nil
+}
+
+// RunRssPruneJob 按保留策略清理过期的 RSS 文章
+func RunRssPruneJob(db *gorm.DB, run *jobService.Run) error {
+	policy := config.GetConfig().Crawler.RssRetention
+	if !policy.Enabled() {
+		return nil
+	}
+	log.Println("[Cron] 正在运行 RSS 文章清理任务...")
+	report, err := friendsRepositories.PruneRssPosts(db, policy, false, 0)
+	if err != nil {
+		log.Printf("[Cron] 清理 RSS 文章失败: %v", err)
+		return err
+	}
+	run.SetTotal(report.Total)
+	run.AddDone(report.Total)
+	log.Printf("[Cron] RSS 文章清理任务完成，共删除 %d 篇文章", report.Total)
+	return nil
 }
 
 // RunImageCheckJob 执行图片资源检查任务
-func RunImageCheckJob(db *gorm.DB) {
+func RunImageCheckJob(db *gorm.DB, run *jobService.Run) error {
 	log.Println("[Cron] 正在运行图片资源检查任务...")
-	crawlerService.CheckImagesHealth(db)
+	if err := crawlerService.CheckImagesHealth(run.Context(), db, run); err != nil {
+		return err
+	}
 	log.Println("[Cron] 图片资源检查任务完成")
+	return nil
 }
 
-// StartCronJobs 初始化并启动 cron 任务
-func StartCronJobs(db *gorm.DB) {
-	c := cron.New()
+// RunBackupJob 执行数据库备份任务
+func RunBackupJob(db *gorm.DB, run *jobService.Run) error {
+	log.Println("[Cron] 正在运行数据库备份任务...")
+	backupConf := config.GetConfig().Data.Backup
+	run.SetTotal(1)
+	if _, err := backupService.Create(db, backupConf.Path, backupConf.Keep); err != nil {
+		return err
+	}
+	run.ItemDone()
+	log.Println("[Cron] 数据库备份任务完成")
+	return nil
+}
 
-	// 安排友链爬取任务每 6 小时运行一次
-	c.AddFunc("0 */6 * * *", func() {
-		RunFriendLinkCrawlerJob(db)
+// registerJobs 将各任务注册到任务中心，定时、启动时与手动触发都经由任务中心执行
+func registerJobs(db *gorm.DB) {
+	jobService.Init(db)
+	jobService.Register(jobService.FriendCrawl, "爬取友链并发现 RSS 订阅源", true, func(run *jobService.Run, target int) error {
+		return RunFriendLinkCrawlerJob(db, run, target)
 	})
-
-	// 安排失效友链检查任务每 24 小时运行一次
-	c.AddFunc("0 0 * * *", func() {
-		RunDiedFriendLinkCheckJob(db)
+	jobService.Register(jobService.FriendDiedCheck, "检查失效友链是否恢复", false, func(run *jobService.Run, target int) error {
+		return RunDiedFriendLinkCheckJob(db, run)
 	})
-
-	// 安排 RSS 解析任务每 3 小时运行一次
-	c.AddFunc("0 */3 * * *", func() {
-		RunRssParserJob(db)
+	jobService.Register(jobService.RssParse, "拉取并解析 RSS 订阅源", true, func(run *jobService.Run, target int) error {
+		return RunRssParserJob(db, run, target)
+	})
+	jobService.Register(jobService.RssPrune, "按保留策略清理 RSS 文章", false, func(run *jobService.Run, target int) error {
+		return RunRssPruneJob(db, run)
+	})
+	jobService.Register(jobService.ImageCheck, "检查图片资源是否可访问", false, func(run *jobService.Run, target int) error {
+		return RunImageCheckJob(db, run)
 	})
+	jobService.Register(jobService.DBBackup, "备份数据库并轮换旧备份", false, func(run *jobService.Run, target int) error {
+		return RunBackupJob(db, run)
+	})
+}
+
+// runJob 同步执行已注册的任务，上一次运行未结束时跳过
+func runJob(name, trigger string) {
+	if _, err := jobService.Execute(name, 0, trigger); err != nil {
+		log.Printf("[Cron] 执行任务 %s 失败: %v", name, err)
+	}
+}
+
+// scheduledJob 已安排的定时任务
+type scheduledJob struct {
+	id       cron.EntryID
+	schedule string
+}
+
+// jobScheduler 按 cron_conf 安排已注册的任务，配置重新加载时增量调整
+type jobScheduler struct {
+	mu      sync.Mutex
+	cron    *cron.Cron
+	entries map[string]scheduledJob
+}
 
-	// 安排图片资源检查任务每 24 小时运行一次
-	c.AddFunc("30 0 * * *", func() {
-		RunImageCheckJob(db)
+// apply 使定时任务与配置一致：移除被禁用的任务，重新安排执行时间变化的任务
+// 执行时间无效时保留原有安排
+func (s *jobScheduler) apply(cronConf model.CronConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, info := range jobService.Jobs() {
+		name := info.Name
+		jobConf, configured := cronConf[name]
+		current, scheduled := s.entries[name]
+
+		if !configured || !jobConf.Enabled() {
+			if scheduled {
+				s.cron.Remove(current.id)
+				delete(s.entries, name)
+				log.Printf("[Cron] 已停用定时任务 %s", name)
+			}
+			continue
+		}
+		if scheduled && current.schedule == jobConf.Schedule {
+			continue
+		}
+
+		id, err := s.cron.AddFunc(jobConf.Schedule, func() {
+			runJob(name, "cron")
+		})
+		if err != nil {
+			log.Printf("[Cron] 定时任务 %s 的执行时间 %q 无效，保持原有安排: %v", name, jobConf.Schedule, err)
+			continue
+		}
+		if scheduled {
+			s.cron.Remove(current.id)
+		}
+		s.entries[name] = scheduledJob{id: id, schedule: jobConf.Schedule}
+		log.Printf("[Cron] 已安排定时任务 %s: %s", name, jobConf.Schedule)
+	}
+}
+
+// StartCronJobs 初始化并启动 cron 任务，返回调度器以便关闭时停止
+// 各任务的执行时间与启停由 cron_conf 配置，配置重新加载后立即生效
+func StartCronJobs(db *gorm.DB) *cron.Cron {
+	registerJobs(db)
+	c := cron.New()
+
+	scheduler := &jobScheduler{cron: c, entries: make(map[string]scheduledJob)}
+	scheduler.apply(config.GetConfig().Cron)
+	config.OnReload(func(cfg *model.Config) {
+		scheduler.apply(cfg.Cron)
 	})
 
 	// 如果启用了状态日志，则安排任务
@@ -168,8 +333,14 @@ func StartCronJobs(db *gorm.DB) {
 			if config.GetConfig().EnableStatusLog {
 				service.LogSystemStatus(db)
 			}
-			RunFriendLinkCrawlerJob(db)
-			RunRssParserJob(db)
+			// 在 cron_conf 中被停用的任务启动时也不运行
+			for _, name := range []string{jobService.FriendCrawl, jobService.RssParse} {
+				if jobConf, ok := config.GetConfig().Cron[name]; !ok || !jobConf.Enabled() {
+					log.Printf("[Cron] 定时任务 %s 已停用，跳过启动时扫描", name)
+					continue
+				}
+				runJob(name, "startup")
+			}
 		}()
 	} else {
 		log.Println("[Cron] 根据 CRON_SCAN_ON_STARTUP 设置跳过初始扫描")
@@ -177,4 +348,5 @@ func StartCronJobs(db *gorm.DB) {
 
 	log.Println("[Cron] 正在启动 cron 任务...")
 	c.Start()
+	return c
 }
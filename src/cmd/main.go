@@ -7,9 +7,15 @@ import (
 	friendsRepositories "blog_api/src/repositories/friend"
 	"blog_api/src/service"
 	botService "blog_api/src/service/bot"
+	jobService "blog_api/src/service/job"
 	"blog_api/src/service/oss"
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
+	"os/signal"
+	"syscall"
 	"time"
 )
 
@@ -34,19 +40,57 @@ func Run() {
 	if err := oss.ValidateOSSConfig(); err != nil {
 		log.Printf("[main][OSS]配置校验失败: %v", err)
 	}
+	service.InitTokenStore(db, cfg.Safe)
+	service.StartEmailWorker(db)
 	router := cmd.SetupRouter(db, cfg, startTime)
 
+	addr := fmt.Sprintf("%s:%s", cfg.ListenAddress, cfg.Port)
+	srv := &http.Server{Addr: addr, Handler: router}
 	go func() {
-		addr := fmt.Sprintf("%s:%s", cfg.ListenAddress, cfg.Port)
 		log.Printf("[main][Http]HTTP 服务器启动于 %s", addr)
-		if err := router.Run(addr); err != nil {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			log.Fatalf("[main][Http]启动 HTTP 服务器失败: %v", err)
 		}
 	}()
 
 	botService.StartListeners(db, cfg)
-	StartCronJobs(db)
+	scheduler := StartCronJobs(db)
 	log.Println("[main][App]应用程序启动成功。HTTP 服务器和 cron 任务正在运行。")
 
-	select {}
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	<-ctx.Done()
+	stop()
+
+	timeout := time.Duration(cfg.ShutdownTimeout) * time.Second
+	log.Printf("[main][App]收到退出信号，开始优雅关闭（最长等待 %s）", timeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// 先停止调度新的定时任务，再依次关闭 HTTP 服务器、后台任务、机器人与邮件发送
+	cronCtx := scheduler.Stop()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("[main][Http]关闭 HTTP 服务器失败: %v", err)
+	}
+	if err := jobService.Shutdown(shutdownCtx); err != nil {
+		log.Printf("[main][Job]等待后台任务结束超时: %v", err)
+	}
+	if err := botService.StopListeners(shutdownCtx); err != nil {
+		log.Printf("[main][Bot]等待机器人处理消息超时: %v", err)
+	}
+	if err := service.StopEmailWorker(shutdownCtx); err != nil {
+		log.Printf("[main][Email]等待邮件发送结束超时: %v", err)
+	}
+	service.StopTokenSweeper()
+	select {
+	case <-cronCtx.Done():
+	case <-shutdownCtx.Done():
+		log.Println("[main][Cron]等待定时任务结束超时")
+	}
+
+	if sqlDB, err := db.DB(); err == nil {
+		if err := sqlDB.Close(); err != nil {
+			log.Printf("[main]关闭数据库失败: %v", err)
+		}
+	}
+	log.Println("[main][App]应用程序已退出")
 }
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"blog_api/migrations"
+	"blog_api/src/config"
+	"blog_api/src/repositories"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+const migrateUsage = `用法:
+  blog_api migrate status      列出迁移及其应用状态
+  blog_api migrate up          应用尚未执行的迁移并更新全文索引
+  blog_api migrate down [N]    回滚最近应用的 N 个迁移（默认 1）`
+
+// Migrate 处理 migrate 子命令，返回进程退出码
+func Migrate(args []string) int {
+	if len(args) == 0 || (args[0] != "status" && args[0] != "up" && args[0] != "down") {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Printf("[migrate]加载配置失败: %v", err)
+		return 1
+	}
+	db, err := repositories.OpenDB(cfg)
+	if err != nil {
+		log.Printf("[migrate]打开数据库失败: %v", err)
+		return 1
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+
+	switch args[0] {
+	case "status":
+		statuses, err := repositories.MigrationStatuses(db, migrations.FS)
+		if err != nil {
+			log.Printf("[migrate]读取迁移状态失败: %v", err)
+			return 1
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tSTATUS\tAPPLIED AT\tDOWN")
+		for _, status := range statuses {
+			state, appliedAt, down := "pending", "-", "no"
+			if status.Applied {
+				state = "applied"
+				appliedAt = time.Unix(status.AppliedAt, 0).Format("2006-01-02 15:04:05")
+			}
+			if status.Modified {
+				state = "applied (modified)"
+			}
+			if status.Missing {
+				state = "applied (file missing)"
+			}
+			if status.HasDown {
+				down = "yes"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", status.Version, state, appliedAt, down)
+		}
+		w.Flush()
+	case "up":
+		if err := repositories.Migrate(db); err != nil {
+			log.Printf("[migrate]应用迁移失败: %v", err)
+			return 1
+		}
+		fmt.Println("所有迁移均已应用")
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps <= 0 {
+				fmt.Fprintf(os.Stderr, "无效的回滚数量: %s\n", args[1])
+				return 2
+			}
+		}
+		reverted, err := repositories.MigrateDown(db, migrations.FS, steps)
+		for _, version := range reverted {
+			fmt.Printf("已回滚: %s\n", version)
+		}
+		if err != nil {
+			log.Printf("[migrate]回滚迁移失败: %v", err)
+			return 1
+		}
+		if len(reverted) == 0 {
+			fmt.Println("没有可回滚的迁移")
+		}
+	}
+	return 0
+}
@@ -38,32 +38,47 @@ func registerRoutes(router *gin.Engine, db *gorm.DB, cfg *model.Config, startTim
 	mediaHandler := handlerAction.NewMediaHandler(db)
 	configHandler := handlerAction.NewConfigHandler()
 	fingerprintHandler := authHandler.NewFingerprintHandler(db)
+	searchHandler := handler.NewSearchHandler(db)
+	friendURLChangeHandler := handlerAction.NewFriendURLChangeHandler(db)
+	friendApplicationHandler := handlerAction.NewFriendApplicationHandler(db)
+	emailHandler := handlerAction.NewEmailHandler(db)
+	jobHandler := handlerAction.NewJobHandler(db)
+	backupHandler := handlerAction.NewBackupHandler(db)
+	transferHandler := handlerAction.NewTransferHandler(db)
+	memosHandler := handler.NewMemosHandler(db)
 
 	// API routes
 	apiGroup := router.Group("/api")
 	{
 		verifyGroup := apiGroup.Group("/verify")
 		{
-			verifyGroup.POST("/passwd", authHandlerInstance.Login)
-			verifyGroup.POST("/email", middleware.AntiBotAuth(), verifyHandler.SendEmailCode)
+			verifyGroup.POST("/passwd", middleware.RateLimit("verify_passwd"), authHandlerInstance.Login)
+			verifyGroup.POST("/email", middleware.RateLimit("verify_email"), middleware.AntiBotAuth(), verifyHandler.SendEmailCode)
 			verifyGroup.POST("/turnstile", middleware.TurnstileVerify(), verifyHandler.IssueVerifyToken)
-			verifyGroup.POST("/fingerprint", middleware.AntiBotAuth(), fingerprintHandler.CreateFingerprint)
+			verifyGroup.POST("/fingerprint", middleware.RateLimit("verify_fingerprint"), middleware.AntiBotAuth(), fingerprintHandler.CreateFingerprint)
 		}
 		publicGroup := apiGroup.Group("/public")
 		{
 			publicGroup.GET("/verify_conf", verifyPublicHandler.GetVerifyConfig)
 			publicGroup.GET("/friend/", friendLinkHandler.GetAllFriendLinks)
 			publicGroup.GET("/friend/self", middleware.FriendLinkAuth(), friendLinkHandler.GetFriendLinkByEmailToken)
+			publicGroup.GET("/friend/application", middleware.FriendLinkAuth(), friendLinkHandler.GetFriendApplicationByEmailToken)
 			publicGroup.GET("/friend/:id", friendLinkHandler.GetFriendLinkByID)
 			publicGroup.POST("/friend", middleware.FriendLinkAuth(), updataHandler.CreateFriendLink)
 			publicGroup.PUT("/friend/:id", middleware.FriendLinkAuth(), updataHandler.EditFriendLink)
 			publicGroup.GET("/rss/", rssPostHandler.GetRssPosts)
+			publicGroup.GET("/rss/rss.xml", rssPostHandler.GetRssFeed)
+			publicGroup.GET("/rss/atom.xml", rssPostHandler.GetAtomFeed)
+			publicGroup.GET("/rss/feed.json", rssPostHandler.GetJSONFeed)
+			publicGroup.GET("/rss/opml", rssPostHandler.GetOPML)
 			publicGroup.GET("/image/*id", imagePublicHandler.GetImage)
 			publicGroup.GET("/moments/", momentHandler.GetMoments)
-			publicGroup.POST("/moments/:id/reactions", middleware.AntiBotAuth(), middleware.FingerprintAuth(), momentReactionHandler.AddReaction)
-			publicGroup.DELETE("/moments/:id/reactions", middleware.AntiBotAuth(), middleware.FingerprintAuth(), momentReactionHandler.DeleteReaction)
+			publicGroup.GET("/search", searchHandler.Search)
+			publicGroup.POST("/moments/:id/reactions", middleware.AntiBotAuth(), middleware.FingerprintAuth(), middleware.RateLimit("reactions"), momentReactionHandler.AddReaction)
+			publicGroup.DELETE("/moments/:id/reactions", middleware.AntiBotAuth(), middleware.FingerprintAuth(), middleware.RateLimit("reactions"), momentReactionHandler.DeleteReaction)
 		}
 		apiGroup.GET("/status", middleware.JWTAuth(), statusHandler.GetSystemStatus)
+		apiGroup.GET("/v1/memos", memosHandler.ListMemos)
 
 		actionGroup := apiGroup.Group("/action")
 		actionGroup.Use(middleware.JWTAuth())
@@ -71,6 +86,13 @@ func registerRoutes(router *gin.Engine, db *gorm.DB, cfg *model.Config, startTim
 			friendActionGroup := actionGroup.Group("/friend")
 			{
 				friendActionGroup.GET("", friendLinkHandler.GetFullFriendLinks)
+				friendActionGroup.GET("/url_changes", friendURLChangeHandler.GetURLChanges)
+				friendActionGroup.POST("/url_changes/:id/approve", friendURLChangeHandler.ApproveURLChange)
+				friendActionGroup.POST("/url_changes/:id/reject", friendURLChangeHandler.RejectURLChange)
+				friendActionGroup.GET("/applications", friendApplicationHandler.GetApplications)
+				friendActionGroup.POST("/applications/:id/approve", friendApplicationHandler.ApproveApplication)
+				friendActionGroup.POST("/applications/:id/reject", friendApplicationHandler.RejectApplication)
+				friendActionGroup.POST("/applications/:id/recheck", friendApplicationHandler.RecheckApplication)
 				friendActionGroup.GET("/:id", friendLinkHandler.GetFullFriendLinkByID)
 				friendActionGroup.POST("", updataHandler.CreateFriendLink)
 				friendActionGroup.PUT("/:id", updataHandler.EditFriendLink)
@@ -79,7 +101,9 @@ func registerRoutes(router *gin.Engine, db *gorm.DB, cfg *model.Config, startTim
 			rssActionGroup := actionGroup.Group("/rss")
 			{
 				rssActionGroup.GET("", RssHandler.GetRss)
+				rssActionGroup.GET("/prune", RssHandler.PreviewPruneRss)
 				rssActionGroup.POST("", RssHandler.CreateRss)
+				rssActionGroup.POST("/opml", RssHandler.ImportOPML)
 				rssActionGroup.PUT("/:id", RssHandler.EditRss)
 				rssActionGroup.DELETE("/:id", RssHandler.DeleteFriendRss)
 			}
@@ -98,7 +122,34 @@ func registerRoutes(router *gin.Engine, db *gorm.DB, cfg *model.Config, startTim
 				resourceActionGroup.DELETE("/local/*file_path", resourceHandler.DeleteResourceLocal)
 				resourceActionGroup.DELETE("/oss/*file_path", resourceHandler.DeleteResourceOSS)
 			}
+			jobActionGroup := actionGroup.Group("/jobs")
+			{
+				jobActionGroup.GET("", jobHandler.GetJobs)
+				jobActionGroup.GET("/runs", jobHandler.GetJobRuns)
+				jobActionGroup.GET("/runs/:id", jobHandler.GetJobRun)
+				jobActionGroup.POST("/:name/run", jobHandler.TriggerJob)
+			}
+			emailActionGroup := actionGroup.Group("/emails")
+			{
+				emailActionGroup.GET("", emailHandler.GetEmails)
+				emailActionGroup.GET("/:id", emailHandler.GetEmail)
+				emailActionGroup.POST("/:id/retry", emailHandler.RetryEmail)
+			}
+			backupActionGroup := actionGroup.Group("/backups")
+			{
+				backupActionGroup.GET("", backupHandler.GetBackups)
+				backupActionGroup.POST("", backupHandler.CreateBackup)
+				backupActionGroup.GET("/snapshot", backupHandler.DownloadSnapshot)
+				backupActionGroup.POST("/restore", backupHandler.RestoreBackup)
+				backupActionGroup.GET("/:name", backupHandler.DownloadBackup)
+				backupActionGroup.DELETE("/:name", backupHandler.DeleteBackup)
+			}
+			actionGroup.GET("/export", transferHandler.Export)
+			actionGroup.POST("/import", transferHandler.Import)
+			actionGroup.POST("/import/memos", transferHandler.ImportMemos)
+			actionGroup.POST("/import/friends", transferHandler.ImportFriends)
 			actionGroup.PUT("/config", configHandler.UpdateConfig)
+			actionGroup.GET("/search", searchHandler.AdminSearch)
 			momentsActionGroup := actionGroup.Group("/moments")
 			{
 				momentsActionGroup.GET("", momentActionHandler.GetMoments)
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"blog_api/src/model"
+	"log"
 	"net/http"
 	"time"
 
@@ -22,6 +23,11 @@ func NotImplemented(c *gin.Context) {
 func SetupRouter(db *gorm.DB, cfg *model.Config, startTime time.Time) *gin.Engine {
 	gin.SetMode(gin.ReleaseMode)
 	router := gin.Default()
+	// 默认不信任任何代理，否则客户端可以伪造 X-Forwarded-For 绕过按 IP 的限流与登录锁定
+	if err := router.SetTrustedProxies(cfg.Safe.TrustedProxies); err != nil {
+		log.Printf("[router][ERR] safe_conf.trusted_proxies 无效，将不信任任何代理: %v", err)
+		_ = router.SetTrustedProxies(nil)
+	}
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     cfg.Safe.CorsAllowHostlist,
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
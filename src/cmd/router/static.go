@@ -36,6 +36,20 @@ func staticFileHandler(cfg *model.Config) gin.HandlerFunc {
 			}
 		}
 
+		// 备份目录与邮件写入目录位于 data 下时禁止直接访问
+		for _, privateDir := range []string{cfg.Data.Backup.Path, cfg.Email.SinkPath} {
+			if privateDir == "" {
+				continue
+			}
+			if rel, err := filepath.Rel(dir, privateDir); err == nil && !strings.HasPrefix(rel, "..") {
+				privatePrefix := "/" + filepath.ToSlash(rel)
+				if reqPath == privatePrefix || strings.HasPrefix(reqPath, privatePrefix+"/") {
+					c.String(http.StatusForbidden, "Forbidden")
+					return
+				}
+			}
+		}
+
 		fsPath := filepath.Join(dir, reqPath)
 		info, err := os.Stat(fsPath)
 
@@ -18,8 +18,39 @@ var (
 	globalConfig *model.Config
 	once         sync.Once
 	v            *viper.Viper // 全局 viper 实例
+
+	reloadMu    sync.Mutex
+	reloadHooks []func(cfg *model.Config)
 )
 
+// defaultCronSchedules 各定时任务的默认执行时间
+var defaultCronSchedules = map[string]string{
+	"friend_crawl":      "0 */6 * * *",
+	"friend_died_check": "0 0 * * *",
+	"rss_parse":         "0 */3 * * *",
+	"rss_prune":         "0 1 * * *",
+	"image_check":       "30 0 * * *",
+	"db_backup":         "0 4 * * *",
+}
+
+// defaultRateLimitPolicies 各接口的默认限流策略
+var defaultRateLimitPolicies = map[string][]model.RateLimitRule{
+	"verify_email": {
+		{Key: "ip", Burst: 5, PerMinute: 1},
+		{Key: "email", Burst: 3, PerMinute: 0.2},
+	},
+	"verify_fingerprint": {
+		{Key: "ip", Burst: 10, PerMinute: 2},
+	},
+	"verify_passwd": {
+		{Key: "ip", Burst: 10, PerMinute: 5},
+	},
+	"reactions": {
+		{Key: "ip", Burst: 60, PerMinute: 30},
+		{Key: "fingerprint", Burst: 30, PerMinute: 15},
+	},
+}
+
 // Load 加载所有配置 (单例模式)
 // 配置加载顺序:
 // 环境变量会覆盖配置文件中的同名设置
@@ -112,6 +143,7 @@ func unmarshalConfig(cfg *model.Config) error {
 	cfg.CronScanOnStartup = v.GetBool("CRON_SCAN_ON_STARTUP")
 	cfg.EnableStatusLog = v.GetBool("ENABLE_STATUS_LOG")
 	cfg.IsDev = parseEnvBool(v.GetString("IS_DEV"))
+	cfg.ShutdownTimeout = v.GetInt("SHUTDOWN_TIMEOUT_SECONDS")
 
 	// 设置默认值
 	if cfg.Port == "" {
@@ -123,6 +155,9 @@ func unmarshalConfig(cfg *model.Config) error {
 	if cfg.ConfigPath == "" {
 		cfg.ConfigPath = "data/config"
 	}
+	if cfg.ShutdownTimeout <= 0 {
+		cfg.ShutdownTimeout = 30
+	}
 
 	// 解析系统配置
 	if err := v.UnmarshalKey("system_conf", cfg); err != nil {
@@ -133,6 +168,50 @@ func unmarshalConfig(cfg *model.Config) error {
 	if cfg.Data.Database.Path != "" {
 		cfg.Safe.ExcludePaths = append(cfg.Safe.ExcludePaths, cfg.Data.Database.Path)
 	}
+	if cfg.Data.Backup.Path == "" {
+		cfg.Data.Backup.Path = "data/backup"
+	}
+	if cfg.Data.Backup.Keep <= 0 {
+		cfg.Data.Backup.Keep = 7
+	}
+	cfg.Safe.ExcludePaths = append(cfg.Safe.ExcludePaths, cfg.Data.Backup.Path)
+	cfg.Safe.TokenStore = strings.ToLower(strings.TrimSpace(cfg.Safe.TokenStore))
+	if cfg.Safe.TokenStore == "" {
+		cfg.Safe.TokenStore = "sqlite"
+	}
+	if cfg.Safe.TokenSweepSeconds <= 0 {
+		cfg.Safe.TokenSweepSeconds = 300
+	}
+	applyRateLimitDefaults(&cfg.Safe.RateLimit)
+
+	cfg.Email.Language = strings.TrimSpace(cfg.Email.Language)
+	if cfg.Email.Language == "" {
+		cfg.Email.Language = "zh-CN"
+	}
+	cfg.Email.Transport = strings.ToLower(strings.TrimSpace(cfg.Email.Transport))
+	if cfg.Email.Transport == "" {
+		cfg.Email.Transport = "smtp"
+	}
+	if cfg.Email.SinkPath == "" {
+		cfg.Email.SinkPath = "mail"
+	}
+	// 邮件中含有验证码与令牌，写入 data 下时同样禁止通过静态文件或资源接口访问
+	cfg.Safe.ExcludePaths = append(cfg.Safe.ExcludePaths, cfg.Email.SinkPath)
+	if cfg.Email.MaxAttempts <= 0 {
+		cfg.Email.MaxAttempts = 6
+	}
+	if cfg.Email.RetryBaseSeconds <= 0 {
+		cfg.Email.RetryBaseSeconds = 30
+	}
+	if cfg.Email.RetryMaxSeconds <= 0 {
+		cfg.Email.RetryMaxSeconds = 3600
+	}
+	if cfg.Email.RetryMaxSeconds < cfg.Email.RetryBaseSeconds {
+		cfg.Email.RetryMaxSeconds = cfg.Email.RetryBaseSeconds
+	}
+	if cfg.Email.LogRetentionDays <= 0 {
+		cfg.Email.LogRetentionDays = 30
+	}
 
 	// 设置爬虫默认并发数
 	if cfg.Crawler.Concurrency <= 0 {
@@ -141,6 +220,68 @@ func unmarshalConfig(cfg *model.Config) error {
 	if cfg.Crawler.RssTimeoutSeconds <= 0 {
 		cfg.Crawler.RssTimeoutSeconds = 15
 	}
+	if cfg.Crawler.RssTimeoutThreshold <= 0 {
+		cfg.Crawler.RssTimeoutThreshold = 3
+	}
+	if cfg.Crawler.RssErrorThreshold <= 0 {
+		cfg.Crawler.RssErrorThreshold = 8
+	}
+	if cfg.Crawler.RssErrorThreshold < cfg.Crawler.RssTimeoutThreshold {
+		cfg.Crawler.RssErrorThreshold = cfg.Crawler.RssTimeoutThreshold
+	}
+	if cfg.Crawler.MaxRedirects <= 0 {
+		cfg.Crawler.MaxRedirects = 5
+	}
+	if cfg.Crawler.RedirectConfirmCount <= 0 {
+		cfg.Crawler.RedirectConfirmCount = 3
+	}
+	cfg.Crawler.UserAgent = strings.TrimSpace(cfg.Crawler.UserAgent)
+	if cfg.Crawler.UserAgent == "" {
+		cfg.Crawler.UserAgent = "blog_api_webCrawler/1.0"
+		if cfg.Feed.SiteURL != "" {
+			cfg.Crawler.UserAgent += " (+" + cfg.Feed.SiteURL + ")"
+		}
+	}
+	if cfg.Crawler.RobotsCacheMinutes <= 0 {
+		cfg.Crawler.RobotsCacheMinutes = 60
+	}
+	if cfg.Crawler.PerHostConcurrency <= 0 {
+		cfg.Crawler.PerHostConcurrency = 2
+	}
+	if cfg.Crawler.PerHostDelayMs <= 0 {
+		cfg.Crawler.PerHostDelayMs = 500
+	}
+	// 保留策略默认不限制
+	if cfg.Crawler.RssRetention.MaxAgeDays < 0 {
+		cfg.Crawler.RssRetention.MaxAgeDays = 0
+	}
+	if cfg.Crawler.RssRetention.MaxPostsPerFeed < 0 {
+		cfg.Crawler.RssRetention.MaxPostsPerFeed = 0
+	}
+	if cfg.Crawler.RssRetention.MaxTotalPosts < 0 {
+		cfg.Crawler.RssRetention.MaxTotalPosts = 0
+	}
+
+	// 设置聚合订阅默认值
+	if cfg.Feed.Title == "" {
+		cfg.Feed.Title = "Friend Circle"
+	}
+	if cfg.Feed.Limit <= 0 {
+		cfg.Feed.Limit = 50
+	}
+
+	// 补全定时任务默认配置
+	if cfg.Cron == nil {
+		cfg.Cron = make(model.CronConfig)
+	}
+	for name, schedule := range defaultCronSchedules {
+		job := cfg.Cron[name]
+		job.Schedule = strings.TrimSpace(job.Schedule)
+		if job.Schedule == "" {
+			job.Schedule = schedule
+		}
+		cfg.Cron[name] = job
+	}
 
 	// 从环境变量加载覆盖敏感信息
 	if telegramBotToken := v.GetString("TELEGRAM_BOT_TOKEN"); telegramBotToken != "" {
@@ -181,6 +322,49 @@ func unmarshalConfig(cfg *model.Config) error {
 	return nil
 }
 
+// applyRateLimitDefaults 补全限流配置，未配置的策略使用 defaultRateLimitPolicies
+func applyRateLimitDefaults(conf *model.RateLimitConfig) {
+	if !v.IsSet("system_conf.safe_conf.rate_limit.enable") {
+		conf.Enable = true
+	}
+	if conf.Policies == nil {
+		conf.Policies = make(map[string][]model.RateLimitRule)
+	}
+	for name, rules := range defaultRateLimitPolicies {
+		if _, ok := conf.Policies[name]; !ok {
+			conf.Policies[name] = append([]model.RateLimitRule(nil), rules...)
+		}
+	}
+	for name, rules := range conf.Policies {
+		valid := rules[:0]
+		for _, rule := range rules {
+			rule.Key = strings.ToLower(strings.TrimSpace(rule.Key))
+			switch rule.Key {
+			case "ip", "email", "fingerprint":
+			default:
+				log.Printf("限流策略 %s 中的 key %q 无效，已忽略", name, rule.Key)
+				continue
+			}
+			if rule.Burst <= 0 || !(rule.PerMinute > 0) {
+				log.Printf("限流策略 %s 中 key %s 的 burst 与 per_minute 必须大于 0，已忽略", name, rule.Key)
+				continue
+			}
+			valid = append(valid, rule)
+		}
+		conf.Policies[name] = valid
+	}
+
+	if conf.LoginMaxFailures <= 0 {
+		conf.LoginMaxFailures = 5
+	}
+	if conf.LoginWindowSeconds <= 0 {
+		conf.LoginWindowSeconds = 900
+	}
+	if conf.LoginLockoutSeconds <= 0 {
+		conf.LoginLockoutSeconds = 900
+	}
+}
+
 func parseEnvBool(val string) bool {
 	switch strings.ToLower(strings.TrimSpace(val)) {
 	case "1", "true", "yes", "y", "on", "ture":
@@ -199,9 +383,23 @@ func Reload() error {
 	}
 	globalConfig = newConfig
 	log.Println("配置已重新加载")
+
+	reloadMu.Lock()
+	hooks := append([]func(cfg *model.Config){}, reloadHooks...)
+	reloadMu.Unlock()
+	for _, hook := range hooks {
+		hook(newConfig)
+	}
 	return nil
 }
 
+// OnReload 注册配置重新加载后的回调，例如按新配置重新安排定时任务
+func OnReload(hook func(cfg *model.Config)) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+	reloadHooks = append(reloadHooks, hook)
+}
+
 // UpdateAndSaveConfigs 批量更新并保存配置到 system_config.json
 func UpdateAndSaveConfigs(updates []model.UpdateConfigReq) error {
 	configPath := GetConfig().ConfigPath
@@ -0,0 +1,146 @@
+package handlerAction
+
+import (
+	"blog_api/src/config"
+	"blog_api/src/model"
+	"blog_api/src/repositories"
+	backupService "blog_api/src/service/backup"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// BackupHandler 处理数据库备份、下载与恢复
+type BackupHandler struct {
+	DB *gorm.DB
+}
+
+// NewBackupHandler 创建一个新的 BackupHandler
+func NewBackupHandler(db *gorm.DB) *BackupHandler {
+	return &BackupHandler{DB: db}
+}
+
+// GetBackups 处理 GET /api/action/backups 请求，返回已有的备份（新的在前）
+func (h *BackupHandler) GetBackups(c *gin.Context) {
+	backups, err := backupService.List(config.GetConfig().Data.Backup.Path)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(http.StatusInternalServerError, "获取备份列表失败: "+err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, model.NewSuccessResponse(backups))
+}
+
+// CreateBackup 处理 POST /api/action/backups 请求，立即创建一份备份
+func (h *BackupHandler) CreateBackup(c *gin.Context) {
+	backupConf := config.GetConfig().Data.Backup
+	info, err := backupService.Create(h.DB, backupConf.Path, backupConf.Keep)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(http.StatusInternalServerError, "创建备份失败: "+err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, model.NewSuccessResponse(info))
+}
+
+// DownloadSnapshot 处理 GET /api/action/backups/snapshot 请求，下载当前数据库的一致快照
+func (h *BackupHandler) DownloadSnapshot(c *gin.Context) {
+	path, cleanup, err := backupService.Snapshot(h.DB, config.GetConfig().Data.Backup.Path)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(http.StatusInternalServerError, "生成快照失败: "+err.Error()))
+		return
+	}
+	defer cleanup()
+	c.FileAttachment(path, backupService.SnapshotName())
+}
+
+// DownloadBackup 处理 GET /api/action/backups/:name 请求，下载指定的备份
+func (h *BackupHandler) DownloadBackup(c *gin.Context) {
+	name := c.Param("name")
+	path, err := backupService.Path(config.GetConfig().Data.Backup.Path, name)
+	if err != nil {
+		c.JSON(http.StatusNotFound, model.NewErrorResponse(http.StatusNotFound, "备份不存在"))
+		return
+	}
+	c.FileAttachment(path, name)
+}
+
+// DeleteBackup 处理 DELETE /api/action/backups/:name 请求
+func (h *BackupHandler) DeleteBackup(c *gin.Context) {
+	err := backupService.Delete(config.GetConfig().Data.Backup.Path, c.Param("name"))
+	if errors.Is(err, backupService.ErrBackupNotFound) {
+		c.JSON(http.StatusNotFound, model.NewErrorResponse(http.StatusNotFound, "备份不存在"))
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(http.StatusInternalServerError, "删除备份失败: "+err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, model.NewSuccessResponse(nil))
+}
+
+// maxRestoreSize 恢复请求体的大小上限
+const maxRestoreSize = 1 << 30
+
+// RestoreBackup 处理 POST /api/action/backups/restore 请求，用备份替换当前数据库
+// 可以上传文件（multipart 表单字段 file），也可以通过 JSON {"name": "..."} 指定已有的备份
+// 文件需通过完整性检查，且其迁移版本不能比当前程序新；恢复前会自动备份当前数据库
+func (h *BackupHandler) RestoreBackup(c *gin.Context) {
+	backupConf := config.GetConfig().Data.Backup
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxRestoreSize)
+
+	var src string
+	if c.ContentType() == "multipart/form-data" {
+		file, err := c.FormFile("file")
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, model.NewErrorResponse(http.StatusRequestEntityTooLarge, fmt.Sprintf("备份文件不能超过 %d MB", maxRestoreSize>>20)))
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, model.NewErrorResponse(http.StatusBadRequest, "获取文件失败: "+err.Error()))
+			return
+		}
+		tmp, err := os.CreateTemp("", "blog_api-restore-*.db")
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, model.NewErrorResponse(http.StatusInternalServerError, "保存上传文件失败: "+err.Error()))
+			return
+		}
+		tmp.Close()
+		defer os.Remove(tmp.Name())
+		if err := c.SaveUploadedFile(file, tmp.Name()); err != nil {
+			c.JSON(http.StatusInternalServerError, model.NewErrorResponse(http.StatusInternalServerError, "保存上传文件失败: "+err.Error()))
+			return
+		}
+		src = tmp.Name()
+	} else {
+		var req struct {
+			Name string `json:"name" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, model.NewErrorResponse(http.StatusBadRequest, "请上传备份文件或指定备份名称"))
+			return
+		}
+		path, err := backupService.Path(backupConf.Path, req.Name)
+		if err != nil {
+			c.JSON(http.StatusNotFound, model.NewErrorResponse(http.StatusNotFound, "备份不存在"))
+			return
+		}
+		src = path
+	}
+
+	before, err := backupService.Restore(h.DB, backupConf.Path, backupConf.Keep, src)
+	if errors.Is(err, repositories.ErrInvalidBackup) {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(http.StatusBadRequest, "备份文件无效: "+err.Error()))
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(http.StatusInternalServerError, "恢复数据库失败: "+err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, model.NewSuccessResponse(gin.H{
+		"pre_restore_backup": before,
+	}))
+}
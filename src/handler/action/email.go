@@ -0,0 +1,135 @@
+package handlerAction
+
+import (
+	"blog_api/src/model"
+	emailRepositories "blog_api/src/repositories/email"
+	"blog_api/src/service"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// EmailHandler exposes the email outbox and its delivery log
+type EmailHandler struct {
+	DB *gorm.DB
+}
+
+// NewEmailHandler creates a new email handler
+func NewEmailHandler(db *gorm.DB) *EmailHandler {
+	return &EmailHandler{DB: db}
+}
+
+// GetEmails handles GET /api/action/emails request, newest first and without bodies
+// Query parameters:
+//   - status: queued, sending, sent or failed (optional)
+//   - page / page_size: for pagination (optional, default: 1 / 20)
+func (h *EmailHandler) GetEmails(c *gin.Context) {
+	var req struct {
+		Status   string `form:"status"`
+		Page     int    `form:"page"`
+		PageSize int    `form:"page_size"`
+	}
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "invalid query parameters"))
+		return
+	}
+
+	if req.Status != "" {
+		validStatuses := map[string]bool{
+			"queued":  true,
+			"sending": true,
+			"sent":    true,
+			"failed":  true,
+		}
+		if !validStatuses[req.Status] {
+			c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "invalid status parameter"))
+			return
+		}
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 20
+	}
+	if req.PageSize > 100 {
+		req.PageSize = 100
+	}
+
+	emails, total, err := emailRepositories.QueryEmails(h.DB, req.Status, req.Page, req.PageSize)
+	if err != nil {
+		log.Printf("[handler][email][ERR] 查询投递记录失败: %v", err)
+		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(500, "failed to retrieve emails"))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(model.PaginatedResponse{
+		Items:    emails,
+		Total:    int(total),
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	}))
+}
+
+// GetEmail handles GET /api/action/emails/:id request, including the plain-text and HTML bodies
+func (h *EmailHandler) GetEmail(c *gin.Context) {
+	id, ok := parseEmailID(c)
+	if !ok {
+		return
+	}
+
+	email, err := emailRepositories.GetEmailByID(h.DB, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, model.NewErrorResponse(404, "email not found"))
+			return
+		}
+		log.Printf("[handler][email][ERR] 查询邮件 %d 失败: %v", id, err)
+		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(500, "failed to retrieve email"))
+		return
+	}
+	c.JSON(http.StatusOK, model.NewSuccessResponse(email))
+}
+
+// RetryEmail handles POST /api/action/emails/:id/retry request
+// Only failed emails can be retried; they are queued again with a fresh set of attempts.
+func (h *EmailHandler) RetryEmail(c *gin.Context) {
+	id, ok := parseEmailID(c)
+	if !ok {
+		return
+	}
+
+	if err := service.RetryEmail(h.DB, id); err != nil {
+		if errors.Is(err, emailRepositories.ErrEmailNotFailed) {
+			if _, err := emailRepositories.GetEmailByID(h.DB, id); errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusNotFound, model.NewErrorResponse(404, "email not found"))
+				return
+			}
+			c.JSON(http.StatusConflict, model.NewErrorResponse(409, "only failed emails can be retried"))
+			return
+		}
+		log.Printf("[handler][email][ERR] 重试邮件 %d 失败: %v", id, err)
+		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(500, "failed to retry email"))
+		return
+	}
+
+	email, err := emailRepositories.GetEmailByID(h.DB, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(500, "failed to retrieve email"))
+		return
+	}
+	c.JSON(http.StatusOK, model.NewSuccessResponse(email))
+}
+
+func parseEmailID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id < 1 {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "invalid email ID"))
+		return 0, false
+	}
+	return id, true
+}
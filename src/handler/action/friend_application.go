@@ -0,0 +1,183 @@
+package handlerAction
+
+import (
+	"blog_api/src/model"
+	friendsRepositories "blog_api/src/repositories/friend"
+	"blog_api/src/service"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// FriendApplicationHandler handles review of friend link applications
+type FriendApplicationHandler struct {
+	DB *gorm.DB
+}
+
+// NewFriendApplicationHandler creates a new friend application handler
+func NewFriendApplicationHandler(db *gorm.DB) *FriendApplicationHandler {
+	return &FriendApplicationHandler{DB: db}
+}
+
+// GetApplications handles GET /api/action/friend/applications request
+// Query parameters:
+//   - status: pending, approved or rejected (optional)
+//   - page / page_size: for pagination (optional, default: 1 / 20)
+func (h *FriendApplicationHandler) GetApplications(c *gin.Context) {
+	var req struct {
+		Status   string `form:"status"`
+		Page     int    `form:"page"`
+		PageSize int    `form:"page_size"`
+	}
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "invalid query parameters"))
+		return
+	}
+
+	if req.Status != "" {
+		validStatuses := map[string]bool{
+			"pending":  true,
+			"approved": true,
+			"rejected": true,
+		}
+		if !validStatuses[req.Status] {
+			c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "invalid status parameter"))
+			return
+		}
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 20
+	}
+	if req.PageSize > 100 {
+		req.PageSize = 100
+	}
+
+	apps, total, err := friendsRepositories.QueryFriendLinkApplications(h.DB, req.Status, req.Page, req.PageSize)
+	if err != nil {
+		log.Printf("[handler][friend][ERR] 查询友链申请失败: %v", err)
+		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(500, "failed to retrieve applications"))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(model.PaginatedResponse{
+		Items:    apps,
+		Total:    int(total),
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	}))
+}
+
+// ApproveApplication handles POST /api/action/friend/applications/:id/approve request
+// The friend link goes live and is crawled right away. The optional JSON body {"reason": "..."} is sent to the applicant.
+func (h *FriendApplicationHandler) ApproveApplication(c *gin.Context) {
+	app, ok := h.getPendingApplication(c)
+	if !ok {
+		return
+	}
+	reason, ok := bindReviewReason(c)
+	if !ok {
+		return
+	}
+
+	app, err := service.ApproveFriendLinkApplication(h.DB, app, reason)
+	if err != nil {
+		h.reviewFailed(c, err, "failed to approve application")
+		return
+	}
+	c.JSON(http.StatusOK, model.NewSuccessResponse(app))
+}
+
+// RejectApplication handles POST /api/action/friend/applications/:id/reject request
+// The JSON body {"reason": "..."} is required and sent to the applicant; the pending friend link is deleted.
+func (h *FriendApplicationHandler) RejectApplication(c *gin.Context) {
+	app, ok := h.getPendingApplication(c)
+	if !ok {
+		return
+	}
+	reason, ok := bindReviewReason(c)
+	if !ok {
+		return
+	}
+	if reason == "" {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "reason is required"))
+		return
+	}
+
+	app, err := service.RejectFriendLinkApplication(h.DB, app, reason)
+	if err != nil {
+		h.reviewFailed(c, err, "failed to reject application")
+		return
+	}
+	c.JSON(http.StatusOK, model.NewSuccessResponse(app))
+}
+
+// RecheckApplication handles POST /api/action/friend/applications/:id/recheck request
+// It runs the pre-checks (site reachable, backlink, duplicate domain) again.
+func (h *FriendApplicationHandler) RecheckApplication(c *gin.Context) {
+	app, ok := h.getPendingApplication(c)
+	if !ok {
+		return
+	}
+
+	app, err := service.CheckFriendLinkApplication(c.Request.Context(), h.DB, app)
+	if err != nil {
+		log.Printf("[handler][friend][ERR] 预检友链申请失败: %v", err)
+		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(500, "failed to check application"))
+		return
+	}
+	c.JSON(http.StatusOK, model.NewSuccessResponse(app))
+}
+
+func (h *FriendApplicationHandler) getPendingApplication(c *gin.Context) (model.FriendLinkApplication, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id < 1 {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "invalid application ID"))
+		return model.FriendLinkApplication{}, false
+	}
+
+	app, err := friendsRepositories.GetFriendLinkApplicationByID(h.DB, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, model.NewErrorResponse(404, "application not found"))
+			return app, false
+		}
+		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(500, "failed to retrieve application"))
+		return app, false
+	}
+	if app.Status != "pending" {
+		c.JSON(http.StatusConflict, model.NewErrorResponse(409, "application is not pending"))
+		return app, false
+	}
+	return app, true
+}
+
+func (h *FriendApplicationHandler) reviewFailed(c *gin.Context, err error, message string) {
+	if errors.Is(err, friendsRepositories.ErrFriendLinkApplicationNotPending) {
+		c.JSON(http.StatusConflict, model.NewErrorResponse(409, "application is not pending"))
+		return
+	}
+	log.Printf("[handler][friend][ERR] 审核友链申请失败: %v", err)
+	c.JSON(http.StatusInternalServerError, model.NewErrorResponse(500, message))
+}
+
+// bindReviewReason reads the optional {"reason": "..."} body.
+func bindReviewReason(c *gin.Context) (string, bool) {
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "invalid request body"))
+			return "", false
+		}
+	}
+	return strings.TrimSpace(req.Reason), true
+}
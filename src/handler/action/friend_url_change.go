@@ -0,0 +1,151 @@
+package handlerAction
+
+import (
+	"blog_api/src/model"
+	friendsRepositories "blog_api/src/repositories/friend"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// FriendURLChangeHandler handles review of friend link url changes
+type FriendURLChangeHandler struct {
+	DB *gorm.DB
+}
+
+// NewFriendURLChangeHandler creates a new friend url change handler
+func NewFriendURLChangeHandler(db *gorm.DB) *FriendURLChangeHandler {
+	return &FriendURLChangeHandler{DB: db}
+}
+
+// GetURLChanges handles GET /api/action/friend/url_changes request
+// Query parameters:
+//   - status: observing, pending, applied, approved, rejected, dismissed or manual (optional)
+//   - friend_link_id: only changes of this friend link (optional)
+//   - page / page_size: for pagination (optional, default: 1 / 20)
+func (h *FriendURLChangeHandler) GetURLChanges(c *gin.Context) {
+	var req struct {
+		Status       string `form:"status"`
+		FriendLinkID int    `form:"friend_link_id"`
+		Page         int    `form:"page"`
+		PageSize     int    `form:"page_size"`
+	}
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "invalid query parameters"))
+		return
+	}
+
+	if req.Status != "" {
+		validStatuses := map[string]bool{
+			"observing": true,
+			"pending":   true,
+			"applied":   true,
+			"approved":  true,
+			"rejected":  true,
+			"dismissed": true,
+			"manual":    true,
+		}
+		if !validStatuses[req.Status] {
+			c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "invalid status parameter"))
+			return
+		}
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 20
+	}
+	if req.PageSize > 100 {
+		req.PageSize = 100
+	}
+
+	changes, total, err := friendsRepositories.QueryFriendLinkURLChanges(h.DB, req.FriendLinkID, req.Status, req.Page, req.PageSize)
+	if err != nil {
+		log.Printf("[handler][friend][ERR] 查询友链地址变更失败: %v", err)
+		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(500, "failed to retrieve url changes"))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(model.PaginatedResponse{
+		Items:    changes,
+		Total:    int(total),
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	}))
+}
+
+// ApproveURLChange handles POST /api/action/friend/url_changes/:id/approve request
+// It updates the friend link to the new url.
+func (h *FriendURLChangeHandler) ApproveURLChange(c *gin.Context) {
+	change, ok := h.getPendingChange(c)
+	if !ok {
+		return
+	}
+
+	if err := friendsRepositories.ApplyFriendLinkURLChange(h.DB, change, "approved"); err != nil {
+		if errors.Is(err, friendsRepositories.ErrFriendLinkURLChanged) {
+			c.JSON(http.StatusConflict, model.NewErrorResponse(409, "friend link url has changed, the change was dismissed"))
+			return
+		}
+		log.Printf("[handler][friend][ERR] 批准友链地址变更失败: %v", err)
+		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(500, "failed to approve url change"))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(gin.H{"friend_link_id": change.FriendLinkID, "website_url": change.NewURL}))
+}
+
+// RejectURLChange handles POST /api/action/friend/url_changes/:id/reject request
+// The optional JSON body {"reason": "..."} is kept with the record.
+func (h *FriendURLChangeHandler) RejectURLChange(c *gin.Context) {
+	change, ok := h.getPendingChange(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "invalid request body"))
+			return
+		}
+	}
+
+	if err := friendsRepositories.RejectFriendLinkURLChange(h.DB, change.ID, req.Reason); err != nil {
+		log.Printf("[handler][friend][ERR] 拒绝友链地址变更失败: %v", err)
+		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(500, "failed to reject url change"))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(nil))
+}
+
+func (h *FriendURLChangeHandler) getPendingChange(c *gin.Context) (model.FriendLinkURLChange, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id < 1 {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "invalid url change ID"))
+		return model.FriendLinkURLChange{}, false
+	}
+
+	change, err := friendsRepositories.GetFriendLinkURLChangeByID(h.DB, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, model.NewErrorResponse(404, "url change not found"))
+			return change, false
+		}
+		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(500, "failed to retrieve url change"))
+		return change, false
+	}
+	if change.Status != "pending" {
+		c.JSON(http.StatusConflict, model.NewErrorResponse(409, "url change is not pending"))
+		return change, false
+	}
+	return change, true
+}
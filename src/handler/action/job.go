@@ -0,0 +1,145 @@
+package handlerAction
+
+import (
+	"blog_api/src/model"
+	friendsRepositories "blog_api/src/repositories/friend"
+	jobService "blog_api/src/service/job"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// JobHandler 处理后台任务的手动触发与查询
+type JobHandler struct {
+	DB *gorm.DB
+}
+
+// NewJobHandler 创建一个新的 JobHandler
+func NewJobHandler(db *gorm.DB) *JobHandler {
+	return &JobHandler{DB: db}
+}
+
+// GetJobs 处理 GET /api/action/jobs 请求，返回可触发的任务列表
+func (h *JobHandler) GetJobs(c *gin.Context) {
+	c.JSON(http.StatusOK, model.NewSuccessResponse(jobService.Jobs()))
+}
+
+// TriggerJob 处理 POST /api/action/jobs/:name/run 请求，在后台启动任务并返回运行记录
+// 同一任务正在运行时新的运行会排队（state 为 queued），相同的排队运行只保留一个
+// 请求体可选：{"target": ID}，friend_crawl 的 target 为友链 ID，rss_parse 的 target 为订阅源 ID
+func (h *JobHandler) TriggerJob(c *gin.Context) {
+	name := c.Param("name")
+	var req struct {
+		Target int `json:"target"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, model.NewErrorResponse(http.StatusBadRequest, "无效的请求体: "+err.Error()))
+			return
+		}
+	}
+	if req.Target < 0 {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(http.StatusBadRequest, "无效的 target"))
+		return
+	}
+
+	if req.Target > 0 {
+		if ok := h.checkTarget(c, name, req.Target); !ok {
+			return
+		}
+	}
+
+	run, err := jobService.Trigger(name, req.Target, "manual")
+	if err != nil {
+		switch {
+		case errors.Is(err, jobService.ErrUnknownJob):
+			c.JSON(http.StatusNotFound, model.NewErrorResponse(http.StatusNotFound, fmt.Sprintf("任务 %s 不存在", name)))
+		case errors.Is(err, jobService.ErrTargetNotSupported):
+			c.JSON(http.StatusBadRequest, model.NewErrorResponse(http.StatusBadRequest, fmt.Sprintf("任务 %s 不支持指定 target", name)))
+		default:
+			c.JSON(http.StatusInternalServerError, model.NewErrorResponse(http.StatusInternalServerError, "启动任务失败: "+err.Error()))
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, model.NewSuccessResponse(run))
+}
+
+// checkTarget 检查 target 对应的友链或订阅源是否存在
+func (h *JobHandler) checkTarget(c *gin.Context, name string, target int) bool {
+	var err error
+	switch name {
+	case jobService.FriendCrawl:
+		_, err = friendsRepositories.GetFriendLinkByID(h.DB, target)
+	case jobService.RssParse:
+		_, err = friendsRepositories.GetFriendRssByID(h.DB, target)
+	default:
+		return true
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, model.NewErrorResponse(http.StatusNotFound, fmt.Sprintf("ID 为 %d 的目标不存在", target)))
+		return false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(http.StatusInternalServerError, "检查目标是否存在时出错: "+err.Error()))
+		return false
+	}
+	return true
+}
+
+// GetJobRuns 处理 GET /api/action/jobs/runs 请求，分页返回执行记录（新的在前）
+// Query parameters:
+//   - job: 任务名称（可选）
+//   - state: queued、running、succeeded、failed 或 skipped（可选）
+//   - page / page_size: 分页（可选，默认 1 / 20）
+func (h *JobHandler) GetJobRuns(c *gin.Context) {
+	var req struct {
+		Job      string `form:"job"`
+		State    string `form:"state"`
+		Page     int    `form:"page"`
+		PageSize int    `form:"page_size"`
+	}
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(http.StatusBadRequest, "无效的查询参数: "+err.Error()))
+		return
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 20
+	}
+	if req.PageSize > 100 {
+		req.PageSize = 100
+	}
+
+	runs, total, err := jobService.ListRuns(req.Job, req.State, req.Page, req.PageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(http.StatusInternalServerError, "获取任务执行记录失败: "+err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(model.PaginatedResponse{
+		Items:    runs,
+		Total:    int(total),
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	}))
+}
+
+// GetJobRun 处理 GET /api/action/jobs/runs/:id 请求，返回单次运行的状态、进度与错误
+func (h *JobHandler) GetJobRun(c *gin.Context) {
+	run, ok, err := jobService.GetRun(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(http.StatusInternalServerError, "获取任务执行记录失败: "+err.Error()))
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusNotFound, model.NewErrorResponse(http.StatusNotFound, "运行记录不存在"))
+		return
+	}
+	c.JSON(http.StatusOK, model.NewSuccessResponse(run))
+}
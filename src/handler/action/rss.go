@@ -1,10 +1,13 @@
 package handlerAction
 
 import (
+	"blog_api/src/config"
 	"blog_api/src/model"
 	friendsRepositories "blog_api/src/repositories/friend"
 	crawlerService "blog_api/src/service/crawler"
+	feedService "blog_api/src/service/feed"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 
@@ -12,6 +15,9 @@ import (
 	"gorm.io/gorm"
 )
 
+// rssHistoryLimit 每个订阅源返回的状态变迁记录数量
+const rssHistoryLimit = 10
+
 // FriendRssHandler 处理与 friend_rss 相关的请求
 type FriendRssHandler struct {
 	DB *gorm.DB
@@ -51,7 +57,7 @@ func (h *FriendRssHandler) CreateRss(c *gin.Context) {
 	name := req.Name
 	if name == "" {
 		var err error
-		name, err = crawlerService.GetRssTitle(req.RssURL)
+		name, err = crawlerService.GetRssTitle(c.Request.Context(), req.RssURL)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, model.NewErrorResponse(http.StatusInternalServerError, "无法获取 RSS 标题: "+err.Error()))
 			return
@@ -119,9 +125,11 @@ func (h *FriendRssHandler) EditRss(c *gin.Context) {
 }
 
 // GetRss 处理 GET /api/action/rss 请求
+// 查询参数 with_history=true 时附带每个订阅源最近的状态变迁记录
 func (h *FriendRssHandler) GetRss(c *gin.Context) {
 	// 解析查询参数
 	status := c.Query("status")
+	withHistory, _ := strconv.ParseBool(c.DefaultQuery("with_history", "false"))
 
 	pageStr := c.DefaultQuery("page", "1")
 	page, err := strconv.Atoi(pageStr)
@@ -169,6 +177,21 @@ func (h *FriendRssHandler) GetRss(c *gin.Context) {
 		return
 	}
 
+	if withHistory && len(resp.Feeds) > 0 {
+		rssIDs := make([]int, len(resp.Feeds))
+		for i, feed := range resp.Feeds {
+			rssIDs[i] = feed.ID
+		}
+		histories, err := friendsRepositories.GetFriendRssStatusLogs(h.DB, rssIDs, rssHistoryLimit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, model.NewErrorResponse(500, "获取 RSS 状态记录失败"))
+			return
+		}
+		for i := range resp.Feeds {
+			resp.Feeds[i].History = histories[resp.Feeds[i].ID]
+		}
+	}
+
 	// 构建分页响应
 	paginatedData := model.PaginatedResponse{
 		Items:    resp.Feeds,
@@ -179,3 +202,80 @@ func (h *FriendRssHandler) GetRss(c *gin.Context) {
 
 	c.JSON(http.StatusOK, model.NewSuccessResponse(paginatedData))
 }
+
+// PreviewPruneRss 处理 GET /api/action/rss/prune 请求，预演保留策略会删除哪些文章（不会实际删除）
+// 查询参数 max_age_days / max_posts_per_feed / max_total_posts 可覆盖配置中的策略，limit 控制返回的文章样本数量
+func (h *FriendRssHandler) PreviewPruneRss(c *gin.Context) {
+	policy := config.GetConfig().Crawler.RssRetention
+	overrides := []struct {
+		key    string
+		target *int
+	}{
+		{"max_age_days", &policy.MaxAgeDays},
+		{"max_posts_per_feed", &policy.MaxPostsPerFeed},
+		{"max_total_posts", &policy.MaxTotalPosts},
+	}
+	for _, override := range overrides {
+		value := c.Query(override.key)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "无效的 "+override.key+" 参数"))
+			return
+		}
+		*override.target = n
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit < 0 {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "无效的 limit 参数"))
+		return
+	}
+	if limit > 1000 {
+		limit = 1000
+	}
+
+	report, err := friendsRepositories.PruneRssPosts(h.DB, policy, true, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(500, "预演清理 RSS 文章失败: "+err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(report))
+}
+
+// ImportOPML 处理 POST /api/action/rss/opml 请求，从 OPML 订阅列表导入订阅源
+// 按订阅地址合并 friend_rss，并按站点地址（htmlUrl，缺省时为订阅地址的站点根目录）匹配或创建所属友链
+// Query parameters:
+//   - on_conflict / dry_run: 同 /api/action/import
+func (h *FriendRssHandler) ImportOPML(c *gin.Context) {
+	opts, ok := bindImportOptions(c)
+	if !ok {
+		return
+	}
+	reader, size, cleanup, ok := importSource(c)
+	if !ok {
+		return
+	}
+	defer cleanup()
+
+	data, err := io.ReadAll(io.NewSectionReader(reader, 0, size))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(http.StatusBadRequest, "读取导入文件失败: "+err.Error()))
+		return
+	}
+	entries, err := feedService.ParseOPML(data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(http.StatusBadRequest, "导入文件无效: "+err.Error()))
+		return
+	}
+
+	report := model.NewImportReport(opts.DryRun)
+	if err := friendsRepositories.ImportFriendLinks(h.DB, entries, opts, report); err != nil {
+		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(http.StatusInternalServerError, "导入失败: "+err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, model.NewSuccessResponse(report))
+}
@@ -0,0 +1,255 @@
+package handlerAction
+
+import (
+	"blog_api/src/config"
+	"blog_api/src/model"
+	"blog_api/src/service"
+	fcircleService "blog_api/src/service/fcircle"
+	memosService "blog_api/src/service/memos"
+	transferService "blog_api/src/service/transfer"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// maxImportSize 导入请求体的大小上限
+const maxImportSize = 1 << 30
+
+// TransferHandler 处理全部内容的导出与导入
+type TransferHandler struct {
+	DB *gorm.DB
+}
+
+// NewTransferHandler 创建一个新的 TransferHandler
+func NewTransferHandler(db *gorm.DB) *TransferHandler {
+	return &TransferHandler{DB: db}
+}
+
+func transferDirs() transferService.Dirs {
+	cfg := config.GetConfig()
+	resourcePath := cfg.Data.Resource.Path
+	if resourcePath == "" {
+		resourcePath = "data/"
+	}
+	return transferService.Dirs{Resource: resourcePath, Image: cfg.Data.Image.Path, Resources: service.NewResourceService(cfg)}
+}
+
+// Export 处理 GET /api/action/export 请求，下载友链、订阅源、文章、动态（含媒体与回应）和图片
+// Query parameters:
+//   - format: json（默认）或 zip（附带本地媒体文件）
+func (h *TransferHandler) Export(c *gin.Context) {
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "zip" {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(http.StatusBadRequest, "format 只能是 json 或 zip"))
+		return
+	}
+
+	// 先写入临时文件，导出失败时仍能返回错误响应，且媒体文件不会全部读入内存
+	tmp, err := os.CreateTemp("", "blog_api-export-*")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(http.StatusInternalServerError, "导出失败: "+err.Error()))
+		return
+	}
+	defer func() {
+		tmp.Close()
+		os.Remove(tmp.Name())
+	}()
+
+	contentType := "application/json"
+	if format == "zip" {
+		contentType = "application/zip"
+		err = transferService.ExportZip(h.DB, transferDirs(), tmp)
+	} else {
+		err = transferService.ExportJSON(h.DB, tmp)
+	}
+	var size int64
+	if err == nil {
+		size, err = tmp.Seek(0, io.SeekCurrent)
+	}
+	if err == nil {
+		_, err = tmp.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(http.StatusInternalServerError, "导出失败: "+err.Error()))
+		return
+	}
+
+	fileName := fmt.Sprintf("blog_api-export-%s.%s", time.Now().Format("20060102-150405"), format)
+	c.DataFromReader(http.StatusOK, size, contentType, tmp, map[string]string{
+		"Content-Disposition": fmt.Sprintf(`attachment; filename="%s"`, fileName),
+	})
+}
+
+// Import 处理 POST /api/action/import 请求，按自然键合并导入导出包并返回冲突报告
+// 可以上传文件（multipart 表单字段 file，json 或 zip），也可以直接以 JSON 作为请求体
+// Query parameters:
+//   - on_conflict: skip（默认，保留本地记录）或 overwrite（用导入的内容覆盖）
+//   - dry_run: true 时只生成报告，不写入任何数据
+func (h *TransferHandler) Import(c *gin.Context) {
+	opts, ok := bindImportOptions(c)
+	if !ok {
+		return
+	}
+	reader, size, cleanup, ok := importSource(c)
+	if !ok {
+		return
+	}
+	defer cleanup()
+
+	report, err := transferService.Import(h.DB, transferDirs(), reader, size, opts)
+	switch {
+	case errors.Is(err, transferService.ErrInvalidBundle), errors.Is(err, transferService.ErrUnsupportedVersion):
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(http.StatusBadRequest, "导入文件无效: "+err.Error()))
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(http.StatusInternalServerError, "导入失败: "+err.Error()))
+		return
+	}
+	if !opts.DryRun {
+		log.Printf("[Transfer] 导入完成，冲突 %d 条，错误 %d 条", len(report.Conflicts), len(report.Errors))
+	}
+	c.JSON(http.StatusOK, model.NewSuccessResponse(report))
+}
+
+// ImportMemos 处理 POST /api/action/import/memos 请求，从 memos 导入动态与附件
+// 可以上传 memos 的 SQLite 数据库或 JSON 导出（ListMemos 响应或动态数组），也可以直接以 JSON 作为请求体
+// 非公开或已归档的 memo 导入为隐藏动态；只支持图片与视频附件
+// Query parameters:
+//   - on_conflict / dry_run: 同 /api/action/import
+//   - base_url: memos 实例地址，附件没有内嵌数据或外链时用于拼接附件地址
+func (h *TransferHandler) ImportMemos(c *gin.Context) {
+	opts, ok := bindImportOptions(c)
+	if !ok {
+		return
+	}
+	reader, size, cleanup, ok := importSource(c)
+	if !ok {
+		return
+	}
+	defer cleanup()
+
+	report, err := memosService.Import(h.DB, config.GetConfig(), reader, size, memosService.Options{
+		ImportOptions: opts,
+		BaseURL:       c.Query("base_url"),
+	})
+	switch {
+	case errors.Is(err, memosService.ErrInvalidExport):
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(http.StatusBadRequest, "导入文件无效: "+err.Error()))
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(http.StatusInternalServerError, "导入失败: "+err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, model.NewSuccessResponse(report))
+}
+
+// ImportFriends 处理 POST /api/action/import/friends 请求，从 fcircle 等格式的友链列表导入友链与订阅源
+// 支持 Friend-Circle-Lite / hexo-circle-of-friends 的 JSON、YAML 友链列表、主题 link.yml 以及 OPML 订阅列表
+// 按友链地址合并，条目中没有的字段保留本地数据
+// Query parameters:
+//   - on_conflict / dry_run: 同 /api/action/import
+func (h *TransferHandler) ImportFriends(c *gin.Context) {
+	opts, ok := bindImportOptions(c)
+	if !ok {
+		return
+	}
+	reader, size, cleanup, ok := importSource(c)
+	if !ok {
+		return
+	}
+	defer cleanup()
+
+	data, err := io.ReadAll(io.NewSectionReader(reader, 0, size))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(http.StatusBadRequest, "读取导入文件失败: "+err.Error()))
+		return
+	}
+	report, err := fcircleService.Import(h.DB, data, opts)
+	switch {
+	case errors.Is(err, fcircleService.ErrInvalidList):
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(http.StatusBadRequest, "导入文件无效: "+err.Error()))
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(http.StatusInternalServerError, "导入失败: "+err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, model.NewSuccessResponse(report))
+}
+
+// bindImportOptions 解析 on_conflict 与 dry_run 参数
+func bindImportOptions(c *gin.Context) (model.ImportOptions, bool) {
+	var req struct {
+		OnConflict string `form:"on_conflict"`
+		DryRun     bool   `form:"dry_run"`
+	}
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(http.StatusBadRequest, "无效的查询参数: "+err.Error()))
+		return model.ImportOptions{}, false
+	}
+	switch req.OnConflict {
+	case "", "skip":
+	case "overwrite":
+	default:
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(http.StatusBadRequest, "on_conflict 只能是 skip 或 overwrite"))
+		return model.ImportOptions{}, false
+	}
+	return model.ImportOptions{Overwrite: req.OnConflict == "overwrite", DryRun: req.DryRun}, true
+}
+
+// importSource 返回上传的文件或请求体，请求体超过 maxImportSize 时返回 413
+// 直接提交的请求体先写入临时文件，不会全部读入内存
+func importSource(c *gin.Context) (io.ReaderAt, int64, func(), bool) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+
+	if c.ContentType() == "multipart/form-data" {
+		header, err := c.FormFile("file")
+		if err != nil {
+			respondImportReadError(c, "获取文件失败: ", err)
+			return nil, 0, nil, false
+		}
+		file, err := header.Open()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, model.NewErrorResponse(http.StatusInternalServerError, "打开上传文件失败: "+err.Error()))
+			return nil, 0, nil, false
+		}
+		return file, header.Size, func() { file.Close() }, true
+	}
+
+	tmp, err := os.CreateTemp("", "blog_api-import-*")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(http.StatusInternalServerError, "创建临时文件失败: "+err.Error()))
+		return nil, 0, nil, false
+	}
+	cleanup := func() {
+		tmp.Close()
+		os.Remove(tmp.Name())
+	}
+	size, err := io.Copy(tmp, c.Request.Body)
+	if err != nil {
+		cleanup()
+		respondImportReadError(c, "读取请求体失败: ", err)
+		return nil, 0, nil, false
+	}
+	if size == 0 {
+		cleanup()
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(http.StatusBadRequest, "请上传导出文件"))
+		return nil, 0, nil, false
+	}
+	return tmp, size, cleanup, true
+}
+
+func respondImportReadError(c *gin.Context, message string, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		c.JSON(http.StatusRequestEntityTooLarge, model.NewErrorResponse(http.StatusRequestEntityTooLarge, fmt.Sprintf("导入文件不能超过 %d MB", maxImportSize>>20)))
+		return
+	}
+	c.JSON(http.StatusBadRequest, model.NewErrorResponse(http.StatusBadRequest, message+err.Error()))
+}
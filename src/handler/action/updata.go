@@ -3,6 +3,7 @@ package handlerAction
 import (
 	"blog_api/src/model"
 	friendsRepositories "blog_api/src/repositories/friend"
+	"blog_api/src/service"
 	"errors"
 	"log"
 	"net/http"
@@ -23,6 +24,7 @@ func NewUpdataHandler(db *gorm.DB) *UpdataHandler {
 }
 
 // CreateFriendLink handles POST /api/updata/friend request
+// Links submitted with an email token become pending applications that an admin reviews.
 func (h *UpdataHandler) CreateFriendLink(c *gin.Context) {
 	log.Println("[handler][updata] Received friend link creation request")
 	var req model.FriendWebsite
@@ -53,6 +55,20 @@ func (h *UpdataHandler) CreateFriendLink(c *gin.Context) {
 			c.JSON(http.StatusInternalServerError, model.NewErrorResponse(500, "failed to validate friend link"))
 			return
 		}
+		if req.Link == "" {
+			c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "link is required"))
+			return
+		}
+
+		// 邮箱令牌提交的友链进入审核队列
+		app, err := service.SubmitFriendLinkApplication(c.Request.Context(), h.DB, req, service.ParseAcceptLanguage(c.GetHeader("Accept-Language")))
+		if err != nil {
+			log.Printf("[handler][updata][ERR] 提交友链申请失败: %v", err)
+			c.JSON(http.StatusInternalServerError, model.NewErrorResponse(500, "failed to create friend link"))
+			return
+		}
+		c.JSON(http.StatusOK, model.NewSuccessResponse(gin.H{"id": app.FriendLinkID, "application": app}))
+		return
 	}
 
 	// Insert into database
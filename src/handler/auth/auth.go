@@ -1,7 +1,10 @@
 package authHandler
 
 import (
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"blog_api/src/model"
 	"blog_api/src/service"
@@ -33,8 +36,19 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
+	// 连续登录失败的 IP 在锁定期内直接拒绝，不再校验密码
+	ip := c.ClientIP()
+	if wait := service.LoginLockedFor(ip); wait > 0 {
+		respondLoginLocked(c, wait)
+		return
+	}
+
 	// 验证用户名和密码
 	if !h.authService.ValidateCredentials(req.Username, req.Password) {
+		if lockout := service.RecordLoginFailure(ip); lockout > 0 {
+			respondLoginLocked(c, lockout)
+			return
+		}
 		c.JSON(http.StatusUnauthorized, model.ApiResponse{
 			Code:    http.StatusUnauthorized,
 			Message: "用户名或密码错误",
@@ -43,6 +57,8 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
+	service.ResetLoginFailures(ip)
+
 	// 生成 JWT token
 	token, expiresAt, err := h.authService.GenerateJWT(req.Username)
 	if err != nil {
@@ -63,3 +79,14 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		},
 	})
 }
+
+// respondLoginLocked 返回 429 与 Retry-After
+func respondLoginLocked(c *gin.Context, wait time.Duration) {
+	seconds := max(int(math.Ceil(wait.Seconds())), 1)
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	c.JSON(http.StatusTooManyRequests, model.ApiResponse{
+		Code:    http.StatusTooManyRequests,
+		Message: "登录失败次数过多，请在 " + strconv.Itoa(seconds) + " 秒后重试",
+		Data:    nil,
+	})
+}
@@ -2,7 +2,6 @@ package authHandler
 
 import (
 	"errors"
-	"fmt"
 	"log"
 	"net/http"
 
@@ -17,10 +16,12 @@ import (
 
 // SendEmailCode handles POST /api/verify/email request.
 // If code is provided, it confirms the code and returns a token; otherwise it sends a code.
+// The email language is taken from the optional "lang" field, falling back to the Accept-Language header.
 func (h *VerifyHandler) SendEmailCode(c *gin.Context) {
 	var req struct {
 		Email string `json:"email" binding:"required"`
 		Code  string `json:"code"`
+		Lang  string `json:"lang"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "invalid request body"))
@@ -67,13 +68,22 @@ func (h *VerifyHandler) SendEmailCode(c *gin.Context) {
 		return
 	}
 
-	content := service.EmailContent{
-		Subject: "blog api 登录验证",
-		Body:    fmt.Sprintf("你的验证码是： %s 。 它将在 %d 后过期。", code, service.EmailCodeTTLSeconds()/60),
-		IsHTML:  false,
+	lang := req.Lang
+	if lang == "" {
+		lang = service.ParseAcceptLanguage(c.GetHeader("Accept-Language"))
 	}
-	if !cfg.Email.Enable {
-		log.Printf("[email][disabled] To=%s Subject=%s Body=%s", req.Email, content.Subject, content.Body)
+	content, err := service.RenderEmail(service.EmailTemplateVerifyCode, lang, service.EmailTemplateData{
+		"Code":    code,
+		"Minutes": service.EmailCodeTTLSeconds() / 60,
+	})
+	if err != nil {
+		log.Printf("[email][ERR] 渲染验证码邮件失败: %v", err)
+		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(500, "failed to send verification email"))
+		return
+	}
+	// 验证码过期后不再投递
+	_, err = service.EnqueueEmail(h.DB, service.EmailTemplateVerifyCode, []string{req.Email}, content, expiresAt)
+	if errors.Is(err, service.ErrEmailDisabled) {
 		if cfg.IsDev {
 			c.JSON(http.StatusOK, model.NewSuccessResponse(map[string]interface{}{
 				"expires_at": expiresAt,
@@ -84,7 +94,8 @@ func (h *VerifyHandler) SendEmailCode(c *gin.Context) {
 		}
 		return
 	}
-	if err := service.SendEmail(cfg.Email, []string{req.Email}, content); err != nil {
+	if err != nil {
+		log.Printf("[email][ERR] 验证码邮件加入发件箱失败: %v", err)
 		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(500, "failed to send verification email"))
 		return
 	}
@@ -21,46 +21,41 @@ func NewFriendLinkHandler(db *gorm.DB) *FriendLinkHandler {
 	return &FriendLinkHandler{DB: db}
 }
 
+// friendLinkCheckLimit 管理接口返回的最近检查记录数量
+const friendLinkCheckLimit = 20
+
 // toFriendLinkDTOs converts a slice of FriendWebsite models to a slice of FriendLinkDTOs.
 // If isPrivate is true, it includes sensitive fields like Email and Times.
 func toFriendLinkDTOs(links []model.FriendWebsite, isPrivate bool) []model.FriendLinkDTO {
 	dtoLinks := make([]model.FriendLinkDTO, 0, len(links))
 	for _, link := range links {
-		dto := model.FriendLinkDTO{
-			ID:          link.ID,
-			Name:        link.Name,
-			Link:        link.Link,
-			Avatar:      link.Avatar,
-			Description: link.Info,
-			Status:      link.Status,
-			EnableRss:   link.EnableRss,
-			UpdatedAt:   link.UpdatedAt,
-		}
-		if isPrivate {
-			dto.Email = link.Email
-			dto.Times = link.Times
-			dto.IsDied = link.IsDied
-		}
-		dtoLinks = append(dtoLinks, dto)
+		dtoLinks = append(dtoLinks, toFriendLinkDTO(link, isPrivate))
 	}
 	return dtoLinks
 }
 
 func toFriendLinkDTO(link model.FriendWebsite, isPrivate bool) model.FriendLinkDTO {
 	dto := model.FriendLinkDTO{
-		ID:          link.ID,
-		Name:        link.Name,
-		Link:        link.Link,
-		Avatar:      link.Avatar,
-		Description: link.Info,
-		Status:      link.Status,
-		EnableRss:   link.EnableRss,
-		UpdatedAt:   link.UpdatedAt,
+		ID:             link.ID,
+		Name:           link.Name,
+		Link:           link.Link,
+		Avatar:         link.Avatar,
+		Description:    link.Info,
+		Status:         link.Status,
+		EnableRss:      link.EnableRss,
+		UpdatedAt:      link.UpdatedAt,
+		FriendsPageURL: link.FriendsPageURL,
 	}
 	if isPrivate {
 		dto.Email = link.Email
 		dto.Times = link.Times
 		dto.IsDied = link.IsDied
+		if link.BacklinkCheckedAt > 0 {
+			hasBacklink := link.HasBacklink
+			dto.HasBacklink = &hasBacklink
+		}
+		dto.BacklinkCheckedAt = link.BacklinkCheckedAt
+		dto.BacklinkLastSeenAt = link.BacklinkLastSeenAt
 	}
 	return dto
 }
@@ -112,16 +107,39 @@ func (h *FriendLinkHandler) getFriendLinks(c *gin.Context, isPrivate bool) {
 		}
 	}
 
+	// Backlink filter is only available on the admin API
+	backlink := ""
+	if isPrivate {
+		backlink = c.Query("backlink")
+		validBacklinks := map[string]bool{
+			"":          true,
+			"present":   true,
+			"missing":   true,
+			"lost":      true,
+			"unchecked": true,
+		}
+		if !validBacklinks[backlink] {
+			c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "invalid backlink parameter"))
+			return
+		}
+	}
+
 	// Calculate offset
 	offset := (page - 1) * pageSize
 
 	// Query friend links and total count
 	opts := model.FriendLinkQueryOptions{
-		Status: status,
-		Search: search,
-		Offset: offset,
-		Limit:  pageSize,
-		IsDied: isDied,
+		Status:   status,
+		Backlink: backlink,
+		Search:   search,
+		Offset:   offset,
+		Limit:    pageSize,
+		IsDied:   isDied,
+	}
+	// 待审核的申请不公开展示
+	if !isPrivate && status == "" {
+		opts.Statuses = []string{"pending"}
+		opts.NotIn = true
 	}
 	resp, err := friendsRepositories.QueryFriendLinks(h.DB, opts)
 	if err != nil {
@@ -131,6 +149,17 @@ func (h *FriendLinkHandler) getFriendLinks(c *gin.Context, isPrivate bool) {
 
 	// Convert to DTO based on the context (public or private)
 	dtoLinks := toFriendLinkDTOs(resp.Links, isPrivate)
+	if isPrivate {
+		withChecks, _ := strconv.ParseBool(c.DefaultQuery("with_checks", "false"))
+		checkLimit := 0
+		if withChecks {
+			checkLimit = friendLinkCheckLimit
+		}
+		if err := h.attachCheckStats(dtoLinks, checkLimit); err != nil {
+			c.JSON(http.StatusInternalServerError, model.NewErrorResponse(500, "failed to retrieve friend link checks"))
+			return
+		}
+	}
 
 	// Build paginated response
 	paginatedData := model.PaginatedResponse{
@@ -161,9 +190,47 @@ func (h *FriendLinkHandler) getFriendLinkByID(c *gin.Context, isPrivate bool) {
 	}
 
 	dto := toFriendLinkDTO(link, isPrivate)
+	if isPrivate {
+		dtos := []model.FriendLinkDTO{dto}
+		if err := h.attachCheckStats(dtos, friendLinkCheckLimit); err != nil {
+			c.JSON(http.StatusInternalServerError, model.NewErrorResponse(500, "failed to retrieve friend link checks"))
+			return
+		}
+		dto = dtos[0]
+	}
 	c.JSON(http.StatusOK, model.NewSuccessResponse(dto))
 }
 
+// attachCheckStats fills in uptime and, when checkLimit > 0, the most recent checks of each link.
+func (h *FriendLinkHandler) attachCheckStats(dtos []model.FriendLinkDTO, checkLimit int) error {
+	if len(dtos) == 0 {
+		return nil
+	}
+	ids := make([]int, len(dtos))
+	for i, dto := range dtos {
+		ids[i] = dto.ID
+	}
+
+	uptimes, err := friendsRepositories.GetFriendLinkUptime(h.DB, ids)
+	if err != nil {
+		return err
+	}
+	var checks map[int][]model.FriendLinkCheck
+	if checkLimit > 0 {
+		if checks, err = friendsRepositories.GetFriendLinkChecks(h.DB, ids, checkLimit); err != nil {
+			return err
+		}
+	}
+
+	for i := range dtos {
+		if uptime, ok := uptimes[dtos[i].ID]; ok {
+			dtos[i].Uptime = &uptime
+		}
+		dtos[i].RecentChecks = checks[dtos[i].ID]
+	}
+	return nil
+}
+
 // GetAllFriendLinks handles GET /api/friend/ request
 func (h *FriendLinkHandler) GetAllFriendLinks(c *gin.Context) {
 	h.getFriendLinks(c, false)
@@ -203,13 +270,44 @@ func (h *FriendLinkHandler) GetFriendLinkByEmailToken(c *gin.Context) {
 	c.JSON(http.StatusOK, model.NewSuccessResponse(dto))
 }
 
+// GetFriendApplicationByEmailToken handles GET /api/public/friend/application request (email token).
+// It returns the latest application submitted with the email, including its review status and reason.
+func (h *FriendLinkHandler) GetFriendApplicationByEmailToken(c *gin.Context) {
+	authType, ok := c.Get("auth_type")
+	if !ok || authType != "email" {
+		c.JSON(http.StatusForbidden, model.NewErrorResponse(403, "email token is required"))
+		return
+	}
+
+	authEmail, _ := c.Get("auth_email")
+	email, _ := authEmail.(string)
+	if email == "" {
+		c.JSON(http.StatusForbidden, model.NewErrorResponse(403, "email token is invalid"))
+		return
+	}
+
+	app, err := friendsRepositories.GetLatestFriendLinkApplicationByEmail(h.DB, email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, model.NewErrorResponse(404, "application not found"))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(500, "failed to retrieve application"))
+		return
+	}
+	c.JSON(http.StatusOK, model.NewSuccessResponse(app))
+}
+
 // GetFullFriendLinks handles GET /api/action/friend/ request (authenticated)
-// It returns the full friend link data, including sensitive fields.
+// It returns the full friend link data, including sensitive fields and uptime.
+// Query parameter with_checks=true also includes the most recent checks of each link,
+// and backlink=present|missing|lost|unchecked filters links by backlink state.
 func (h *FriendLinkHandler) GetFullFriendLinks(c *gin.Context) {
 	h.getFriendLinks(c, true)
 }
 
 // GetFullFriendLinkByID handles GET /api/action/friend/:id request (authenticated)
+// It includes uptime and the most recent checks.
 func (h *FriendLinkHandler) GetFullFriendLinkByID(c *gin.Context) {
 	h.getFriendLinkByID(c, true)
 }
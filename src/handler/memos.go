@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"blog_api/src/config"
+	"blog_api/src/model"
+	"blog_api/src/service"
+	memosService "blog_api/src/service/memos"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// MemosHandler serves moments in the memos v1 API shape for memos-based widgets.
+type MemosHandler struct {
+	DB *gorm.DB
+}
+
+// NewMemosHandler creates a new memos compatibility handler
+func NewMemosHandler(db *gorm.DB) *MemosHandler {
+	return &MemosHandler{DB: db}
+}
+
+// ListMemos handles GET /api/v1/memos, mirroring memos' ListMemos.
+// Only visible moments are listed, newest first. Enabled by moments_integrated_conf.memos_compat.
+// Query parameters:
+//   - pageSize: number of memos per page (default 10)
+//   - pageToken: the nextPageToken of the previous response
+func (h *MemosHandler) ListMemos(c *gin.Context) {
+	cfg := config.GetConfig()
+	if !cfg.MomentsIntegrated.MemosCompat {
+		c.JSON(http.StatusNotFound, gin.H{"code": 5, "message": "memos compatible API is disabled"})
+		return
+	}
+
+	pageSize := 10
+	if raw := c.Query("pageSize"); raw != "" {
+		size, err := strconv.Atoi(raw)
+		if err != nil || size < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 3, "message": "invalid pageSize"})
+			return
+		}
+		pageSize = size
+	}
+	maxSize := cfg.MomentsIntegrated.ApiSingleReturnEntries
+	if maxSize <= 0 {
+		maxSize = 100
+	}
+	if pageSize > maxSize {
+		pageSize = maxSize
+	}
+
+	page := 1
+	if token := c.Query("pageToken"); token != "" {
+		p, err := strconv.Atoi(token)
+		if err != nil || p < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 3, "message": "invalid pageToken"})
+			return
+		}
+		page = p
+	}
+
+	resp, err := service.GetMomentsWithMedia(h.DB, page, pageSize, "visible", nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 13, "message": "failed to list memos"})
+		return
+	}
+
+	origin := requestOrigin(c)
+	result := model.MemosListResponse{Memos: make([]model.MemosMemo, 0, len(resp.Moments))}
+	for _, moment := range resp.Moments {
+		result.Memos = append(result.Memos, memosService.ToMemo(moment, origin))
+	}
+	if int64(page*pageSize) < resp.Total {
+		result.NextPageToken = strconv.Itoa(page + 1)
+	}
+	c.JSON(http.StatusOK, result)
+}
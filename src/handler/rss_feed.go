@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"blog_api/src/config"
+	"blog_api/src/model"
+	friendsRepositories "blog_api/src/repositories/friend"
+	feedService "blog_api/src/service/feed"
+	"log"
+	"net/http"
+	"net/netip"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	feedFormatRSS  = "rss"
+	feedFormatAtom = "atom"
+	feedFormatJSON = "json"
+)
+
+var feedContentTypes = map[string]string{
+	feedFormatRSS:  "application/rss+xml; charset=utf-8",
+	feedFormatAtom: "application/atom+xml; charset=utf-8",
+	feedFormatJSON: "application/feed+json; charset=utf-8",
+}
+
+// GetRssFeed handles GET /api/public/rss/rss.xml request (RSS 2.0)
+func (h *RssPostHandler) GetRssFeed(c *gin.Context) {
+	h.serveFeed(c, feedFormatRSS)
+}
+
+// GetAtomFeed handles GET /api/public/rss/atom.xml request (Atom 1.0)
+func (h *RssPostHandler) GetAtomFeed(c *gin.Context) {
+	h.serveFeed(c, feedFormatAtom)
+}
+
+// GetJSONFeed handles GET /api/public/rss/feed.json request (JSON Feed 1.1)
+func (h *RssPostHandler) GetJSONFeed(c *gin.Context) {
+	h.serveFeed(c, feedFormatJSON)
+}
+
+// serveFeed renders the aggregated posts in the given format.
+// It honors the same rss_id / friend_link_id filters as GetRssPosts.
+func (h *RssPostHandler) serveFeed(c *gin.Context, format string) {
+	var query model.PostQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "invalid query parameters"))
+		return
+	}
+
+	cfg := config.GetConfig()
+	posts, err := friendsRepositories.GetFeedPosts(h.DB, &query, cfg.Feed.Limit)
+	if err != nil {
+		log.Printf("[handler][feed][ERR] 获取订阅文章失败: %v", err)
+		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(500, "failed to retrieve posts"))
+		return
+	}
+
+	lastModified := feedService.LastModified(posts)
+	updated := lastModified
+	if updated.IsZero() {
+		updated = time.Now().UTC()
+	}
+	meta := feedService.Meta{
+		Title:       cfg.Feed.Title,
+		Description: cfg.Feed.Description,
+		SiteURL:     cfg.Feed.SiteURL,
+		FeedURL:     requestURL(c),
+		Updated:     updated,
+	}
+	etag := feedService.ComputeETag(format, meta, posts)
+
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "public, max-age=300")
+	if !lastModified.IsZero() {
+		c.Header("Last-Modified", lastModified.Format(http.TimeFormat))
+	}
+	if isFeedNotModified(c, etag, lastModified) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	var body []byte
+	switch format {
+	case feedFormatAtom:
+		body, err = feedService.BuildAtom(meta, posts)
+	case feedFormatJSON:
+		body, err = feedService.BuildJSONFeed(meta, posts)
+	default:
+		body, err = feedService.BuildRSS(meta, posts)
+	}
+	if err != nil {
+		log.Printf("[handler][feed][ERR] 渲染订阅失败: %v", err)
+		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(500, "failed to render feed"))
+		return
+	}
+
+	c.Data(http.StatusOK, feedContentTypes[format], body)
+}
+
+// GetOPML handles GET /api/public/rss/opml request.
+// It lists every visible friend feed so a reader can subscribe to the whole circle at once.
+func (h *RssPostHandler) GetOPML(c *gin.Context) {
+	feeds, err := friendsRepositories.GetOPMLFeeds(h.DB)
+	if err != nil {
+		log.Printf("[handler][feed][ERR] 获取订阅源失败: %v", err)
+		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(500, "failed to retrieve feeds"))
+		return
+	}
+
+	cfg := config.GetConfig()
+	meta := feedService.Meta{
+		Title:   cfg.Feed.Title,
+		Updated: time.Now(),
+	}
+	body, err := feedService.BuildOPML(meta, feeds)
+	if err != nil {
+		log.Printf("[handler][feed][ERR] 渲染 OPML 失败: %v", err)
+		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(500, "failed to render opml"))
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=300")
+	c.Header("Content-Disposition", `inline; filename="friends.opml"`)
+	c.Data(http.StatusOK, "text/x-opml; charset=utf-8", body)
+}
+
+// isFeedNotModified evaluates If-None-Match first and falls back to If-Modified-Since.
+func isFeedNotModified(c *gin.Context, etag string, lastModified time.Time) bool {
+	if inm := c.GetHeader("If-None-Match"); inm != "" {
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == "*" || candidate == etag {
+				return true
+			}
+		}
+		return false
+	}
+
+	if ims := c.GetHeader("If-Modified-Since"); ims != "" && !lastModified.IsZero() {
+		since, err := http.ParseTime(ims)
+		if err == nil && !lastModified.After(since) {
+			return true
+		}
+	}
+	return false
+}
+
+// requestURL rebuilds the absolute URL of the current request.
+func requestURL(c *gin.Context) string {
+	return requestOrigin(c) + c.Request.URL.RequestURI()
+}
+
+// requestOrigin returns the scheme and host the current request was made to.
+// X-Forwarded-Proto is only honored when the peer is one of safe_conf.trusted_proxies.
+func requestOrigin(c *gin.Context) string {
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if proto := c.GetHeader("X-Forwarded-Proto"); proto != "" && isTrustedProxy(c.RemoteIP()) {
+		switch forwarded := strings.ToLower(strings.TrimSpace(strings.Split(proto, ",")[0])); forwarded {
+		case "http", "https":
+			scheme = forwarded
+		}
+	}
+	return scheme + "://" + c.Request.Host
+}
+
+// isTrustedProxy reports whether ip matches an address or CIDR in safe_conf.trusted_proxies.
+func isTrustedProxy(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, proxy := range config.GetConfig().Safe.TrustedProxies {
+		if prefix, err := netip.ParsePrefix(proxy); err == nil {
+			if prefix.Contains(addr) {
+				return true
+			}
+			continue
+		}
+		if proxyAddr, err := netip.ParseAddr(proxy); err == nil && proxyAddr.Unmap() == addr {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"blog_api/src/model"
+	"blog_api/src/repositories"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	searchTypePost   = "post"
+	searchTypeMoment = "moment"
+	maxSearchQuery   = 200
+)
+
+// SearchHandler handles full-text search requests
+type SearchHandler struct {
+	DB *gorm.DB
+}
+
+// NewSearchHandler creates a new search handler
+func NewSearchHandler(db *gorm.DB) *SearchHandler {
+	return &SearchHandler{DB: db}
+}
+
+// Search handles GET /api/public/search request
+// Query parameters:
+//   - q: search keywords separated by spaces (required)
+//   - type: post or moment (optional, default: post)
+//   - page: for pagination (optional, default: 1)
+//   - page_size: for pagination (optional, default: 10, max: 50)
+//
+// Only visible moments are searched.
+func (h *SearchHandler) Search(c *gin.Context) {
+	h.search(c, false)
+}
+
+// AdminSearch handles GET /api/action/search request
+// Accepts the same parameters as Search, plus status to filter moments (optional, default: all).
+func (h *SearchHandler) AdminSearch(c *gin.Context) {
+	h.search(c, true)
+}
+
+func (h *SearchHandler) search(c *gin.Context, admin bool) {
+	var query model.SearchQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "invalid query parameters"))
+		return
+	}
+
+	query.Q = strings.TrimSpace(query.Q)
+	if query.Q == "" {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "q is required"))
+		return
+	}
+	if len([]rune(query.Q)) > maxSearchQuery {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "q is too long"))
+		return
+	}
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.PageSize <= 0 {
+		query.PageSize = 10
+	}
+	if query.PageSize > 50 {
+		query.PageSize = 50
+	}
+
+	var (
+		items interface{}
+		total int
+		err   error
+	)
+	switch query.Type {
+	case "", searchTypePost:
+		items, total, err = repositories.SearchRssPosts(h.DB, &query)
+	case searchTypeMoment:
+		status := "visible"
+		if admin {
+			status = query.Status
+		}
+		items, total, err = repositories.SearchMoments(h.DB, &query, status)
+	default:
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "invalid type parameter"))
+		return
+	}
+	if err != nil {
+		log.Printf("[search] search %q failed: %v", query.Q, err)
+		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(500, "failed to search"))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(&model.PaginatedResponse{
+		Items:    items,
+		Total:    total,
+		Page:     query.Page,
+		PageSize: query.PageSize,
+	}))
+}
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"blog_api/src/model"
+	"blog_api/src/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxRateLimitBody caps how much of the request body is inspected for the email key.
+const maxRateLimitBody = 64 << 10
+
+// RateLimit applies the token-bucket policy safe_conf.rate_limit.policies[policy].
+// Requests are keyed by client IP, the "email" field of a JSON body and fingerprint_id,
+// so it must run after FingerprintAuth when the policy has fingerprint rules.
+func RateLimit(policy string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		keys := []service.RateLimitKey{{Key: "ip", Value: c.ClientIP()}}
+		if email := peekBodyEmail(c); email != "" {
+			keys = append(keys, service.RateLimitKey{Key: "email", Value: email})
+		}
+		if id, ok := c.Get("fingerprint_id"); ok {
+			keys = append(keys, service.RateLimitKey{Key: "fingerprint", Value: strconv.Itoa(id.(int))})
+		}
+
+		if ok, wait := service.AllowRequest(policy, keys); !ok {
+			AbortTooManyRequests(c, wait)
+			return
+		}
+		c.Next()
+	}
+}
+
+// AbortTooManyRequests responds with 429 and a Retry-After header rounded up to whole seconds.
+func AbortTooManyRequests(c *gin.Context, wait time.Duration) {
+	seconds := max(int(math.Ceil(wait.Seconds())), 1)
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	c.JSON(http.StatusTooManyRequests, model.NewErrorResponse(429, "too many requests, retry after "+strconv.Itoa(seconds)+" seconds"))
+	c.Abort()
+}
+
+// peekBodyEmail reads the email field of a JSON body and restores the body for the handler.
+func peekBodyEmail(c *gin.Context) string {
+	// Handlers bind with ShouldBindJSON regardless of Content-Type, so neither do we
+	if c.Request.Body == nil || c.Request.Method == http.MethodGet {
+		return ""
+	}
+	head, err := io.ReadAll(io.LimitReader(c.Request.Body, maxRateLimitBody))
+	c.Request.Body = readCloser{io.MultiReader(bytes.NewReader(head), c.Request.Body), c.Request.Body}
+	if err != nil {
+		return ""
+	}
+
+	var body struct {
+		Email string `json:"email"`
+	}
+	if json.Unmarshal(head, &body) != nil {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(body.Email))
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
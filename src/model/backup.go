@@ -0,0 +1,8 @@
+package model
+
+// BackupInfo describes a database backup file.
+type BackupInfo struct {
+	Name      string `json:"name"`
+	Size      int64  `json:"size"`       // Size in bytes
+	CreatedAt int64  `json:"created_at"` // File modification time
+}
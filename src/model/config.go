@@ -11,6 +11,7 @@ type Config struct {
 	CronScanOnStartup bool
 	EnableStatusLog   bool
 	IsDev             bool
+	ShutdownTimeout   int // 优雅关闭的最长等待时间（秒），默认 30
 
 	// 系统配置 - 使用小写字段名，通过 Safe 和 Data 访问
 	Safe              SafeConfig              `mapstructure:"safe_conf"`
@@ -20,6 +21,8 @@ type Config struct {
 	OSS               OSSConfig               `mapstructure:"oss_conf"`
 	Verify            VerifyConfig            `mapstructure:"verify_conf"`
 	Email             EmailConf               `mapstructure:"email_conf"`
+	Feed              FeedConfig              `mapstructure:"feed_conf"`
+	Cron              CronConfig              `mapstructure:"cron_conf"`
 
 	// 友链配置
 	FriendLinks []FriendWebsite
@@ -27,8 +30,58 @@ type Config struct {
 
 // CrawlerConfig 爬虫配置
 type CrawlerConfig struct {
-	Concurrency       int `mapstructure:"concurrency"`         // 并发数量，默认 5
-	RssTimeoutSeconds int `mapstructure:"rss_timeout_seconds"` // RSS 解析超时（秒）
+	Concurrency         int `mapstructure:"concurrency"`           // 并发数量，默认 5
+	RssTimeoutSeconds   int `mapstructure:"rss_timeout_seconds"`   // RSS 解析超时（秒）
+	RssTimeoutThreshold int `mapstructure:"rss_timeout_threshold"` // 连续失败多少次后标记为 timeout，默认 3
+	RssErrorThreshold   int `mapstructure:"rss_error_threshold"`   // 连续失败多少次后标记为 error，默认 8
+
+	RssRetention RssRetentionConfig `mapstructure:"rss_retention"` // RSS 文章保留策略
+
+	BacklinkDomains []string `mapstructure:"backlink_domains"` // 本站域名，用于检查友链是否回链；为空时取 feed_conf.site_url 的域名
+
+	MaxRedirects         int  `mapstructure:"max_redirects"`          // 爬取友链时最多跟随的重定向次数，默认 5
+	RedirectConfirmCount int  `mapstructure:"redirect_confirm_count"` // 连续多少次爬取都永久重定向到同一地址才视为迁移，默认 3
+	RedirectAutoUpdate   bool `mapstructure:"redirect_auto_update"`   // 确认迁移后自动更新友链地址；为 false 时提交管理员审核
+
+	UserAgent          string `mapstructure:"user_agent"`           // 爬虫 User-Agent，默认 blog_api_webCrawler/1.0 (+site_url)，产品名用于匹配 robots.txt
+	IgnoreRobotsTxt    bool   `mapstructure:"ignore_robots_txt"`    // 不检查 robots.txt
+	RobotsCacheMinutes int    `mapstructure:"robots_cache_minutes"` // robots.txt 缓存时间（分钟），默认 60
+	PerHostConcurrency int    `mapstructure:"per_host_concurrency"` // 同一主机的最大并发请求数，默认 2
+	PerHostDelayMs     int    `mapstructure:"per_host_delay_ms"`    // 同一主机两次请求的最小间隔（毫秒），默认 500；robots.txt 的 Crawl-delay 更大时以其为准
+}
+
+// RssRetentionConfig RSS 文章保留策略，各项为 0 表示不限制
+type RssRetentionConfig struct {
+	MaxAgeDays      int `mapstructure:"max_age_days" json:"max_age_days"`             // 文章最长保留天数（按发布时间）
+	MaxPostsPerFeed int `mapstructure:"max_posts_per_feed" json:"max_posts_per_feed"` // 每个订阅源最多保留的文章数
+	MaxTotalPosts   int `mapstructure:"max_total_posts" json:"max_total_posts"`       // 全部订阅源合计最多保留的文章数
+}
+
+// Enabled 是否配置了任意一项保留限制
+func (r RssRetentionConfig) Enabled() bool {
+	return r.MaxAgeDays > 0 || r.MaxPostsPerFeed > 0 || r.MaxTotalPosts > 0
+}
+
+// FeedConfig 聚合订阅输出配置
+type FeedConfig struct {
+	Title       string `mapstructure:"title"`       // 订阅标题
+	Description string `mapstructure:"description"` // 订阅描述
+	SiteURL     string `mapstructure:"site_url"`    // 站点地址，用于生成订阅中的链接
+	Limit       int    `mapstructure:"limit"`       // 单次输出的文章数量，默认 50
+}
+
+// CronConfig 定时任务配置，键为任务名称（friend_crawl、friend_died_check、rss_parse、rss_prune、image_check、db_backup）
+type CronConfig map[string]CronJobConfig
+
+// CronJobConfig 单个定时任务的配置
+type CronJobConfig struct {
+	Enable   *bool  `mapstructure:"enable"`   // 是否启用，未设置时视为启用
+	Schedule string `mapstructure:"schedule"` // 标准 5 段 cron 表达式，也支持 @every 1h 等写法
+}
+
+// Enabled 任务是否启用
+func (c CronJobConfig) Enabled() bool {
+	return c.Enable == nil || *c.Enable
 }
 
 // FriendLinksConf 对应 friend_list.json 的结构
@@ -43,6 +96,32 @@ type SafeConfig struct {
 	CorsAllowHostlist []string `mapstructure:"cors_allow_hostlist"`
 	ExcludePaths      []string `mapstructure:"exclude_paths"`
 	AllowExtension    []string `mapstructure:"allow_extension"`
+	// TrustedProxies 信任的反向代理地址或网段，只有来自这些地址的请求才会读取 X-Forwarded-For / X-Real-IP，默认不信任任何代理
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
+
+	TokenStore        string `mapstructure:"token_store"`         // 反机器人令牌、邮箱验证码与邮箱令牌的存储：sqlite（默认，重启后保留）或 memory
+	TokenSweepSeconds int    `mapstructure:"token_sweep_seconds"` // 清理过期令牌的间隔（秒），默认 300
+
+	RateLimit RateLimitConfig `mapstructure:"rate_limit"`
+}
+
+// RateLimitConfig 接口限流与登录锁定配置，计数只保存在进程内存中
+type RateLimitConfig struct {
+	Enable bool `mapstructure:"enable"` // 是否启用限流与登录锁定，默认 true
+	// Policies 各接口的限流策略，键为策略名（verify_email、verify_fingerprint、verify_passwd、reactions），
+	// 未配置的策略使用默认值，配置为空列表则不限流
+	Policies map[string][]RateLimitRule `mapstructure:"policies"`
+
+	LoginMaxFailures    int `mapstructure:"login_max_failures"`    // 同一 IP 在 login_window_seconds 内登录失败多少次后锁定，默认 5
+	LoginWindowSeconds  int `mapstructure:"login_window_seconds"`  // 统计登录失败次数的时间窗口（秒），默认 900
+	LoginLockoutSeconds int `mapstructure:"login_lockout_seconds"` // 锁定时长（秒），默认 900
+}
+
+// RateLimitRule 一条令牌桶规则：每个 key 对应一个容量为 burst 的桶，每分钟补充 per_minute 个令牌
+type RateLimitRule struct {
+	Key       string  `mapstructure:"key"`        // ip、email 或 fingerprint
+	Burst     int     `mapstructure:"burst"`      // 桶容量，即允许的突发请求数
+	PerMinute float64 `mapstructure:"per_minute"` // 每分钟补充的令牌数
 }
 
 // DataConfig 数据配置
@@ -50,6 +129,7 @@ type DataConfig struct {
 	Database DatabaseConfig `mapstructure:"database"`
 	Image    ImageConfig    `mapstructure:"image"`
 	Resource ResourceConfig `mapstructure:"resource"`
+	Backup   BackupConfig   `mapstructure:"backup"`
 }
 
 // DatabaseConfig 数据库配置
@@ -57,6 +137,12 @@ type DatabaseConfig struct {
 	Path string `mapstructure:"path"`
 }
 
+// BackupConfig 数据库备份配置
+type BackupConfig struct {
+	Path string `mapstructure:"path"` // 备份目录，默认 data/backup
+	Keep int    `mapstructure:"keep"` // 保留的备份数量，默认 7
+}
+
 // ImageConfig 图片配置
 type ImageConfig struct {
 	Path   string `mapstructure:"path"`
@@ -72,6 +158,7 @@ type ResourceConfig struct {
 type MomentsIntegratedConfig struct {
 	Enable                 bool              `mapstructure:"enable"`
 	ApiSingleReturnEntries int               `mapstructure:"api_single_return_entries"`
+	MemosCompat            bool              `mapstructure:"memos_compat"` // 提供 memos v1 兼容的 /api/v1/memos 接口
 	Integrated             IntegratedTargets `mapstructure:"integrated"`
 }
 
@@ -131,6 +218,16 @@ type EmailConf struct {
 	Password string `mapstructure:"password"`
 	Port     int    `mapstructure:"port"`
 	Sender   string `mapstructure:"sender"`
+
+	AdminAddress string `mapstructure:"admin_address"` // 接收友链申请等通知的管理员邮箱
+	Language     string `mapstructure:"language"`      // 邮件模板的默认语言，默认 zh-CN
+
+	Transport        string `mapstructure:"transport"`          // smtp（默认）、file（每封邮件写为 .eml 文件）或 maildir
+	SinkPath         string `mapstructure:"sink_path"`          // file / maildir 的写入目录，默认 mail（位于 data 之外，不会被静态文件服务公开）
+	MaxAttempts      int    `mapstructure:"max_attempts"`       // 每封邮件最多尝试次数，默认 6
+	RetryBaseSeconds int    `mapstructure:"retry_base_seconds"` // 首次重试的等待时间，之后每次翻倍，默认 30
+	RetryMaxSeconds  int    `mapstructure:"retry_max_seconds"`  // 重试等待时间上限，默认 3600
+	LogRetentionDays int    `mapstructure:"log_retention_days"` // 已发送与失败的投递记录保留天数，默认 30
 }
 
 // DiscordConfig Discord 配置
@@ -4,7 +4,14 @@ package model
 type CrawlResult struct {
 	Description string
 	IconURL     string
-	Status      string   // e.g., "survival", "timeout", "error"
-	RedirectURL string   // The new URL if a redirect occurs
+	Status      string   // e.g., "survival", "timeout", "error"; "disallowed" if robots.txt forbids the crawl
+	RedirectURL string   // The final URL if the request was redirected
 	RssURLs     []string // Discovered RSS feed URLs
+	HTTPStatus  int      // HTTP status code, 0 if no response was received
+	LatencyMs   int64    // Time until the response headers arrived
+	Error       string   // Failure reason when Status is not "survival"
+	Backlink    *bool    // Whether a link back to our site was found, nil if it was not checked
+
+	RedirectHops      int  // Number of redirects followed
+	PermanentRedirect bool // True if every redirect hop was a 301 or 308
 }
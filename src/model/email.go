@@ -0,0 +1,25 @@
+package model
+
+// EmailOutbox 发件箱中的一封邮件，同时作为投递记录
+// status: queued（等待发送或重试）、sending（发送中）、sent（已发送）、failed（重试次数用尽或已过期）
+type EmailOutbox struct {
+	ID            int      `json:"id" gorm:"column:id;primaryKey"`
+	Template      string   `json:"template,omitempty" gorm:"column:template"` // 生成邮件的模板名称
+	Recipients    []string `json:"recipients" gorm:"column:recipients;serializer:json"`
+	Subject       string   `json:"subject" gorm:"column:subject"`
+	Body          string   `json:"body,omitempty" gorm:"column:body"` // 纯文本正文，列表中不返回
+	HTML          string   `json:"html,omitempty" gorm:"column:html"` // HTML 正文，列表中不返回
+	Status        string   `json:"status" gorm:"column:status"`
+	Attempts      int      `json:"attempts" gorm:"column:attempts"`
+	LastError     string   `json:"last_error,omitempty" gorm:"column:last_error"`
+	NextAttemptAt int64    `json:"next_attempt_at,omitempty" gorm:"column:next_attempt_at"`
+	ExpiresAt     int64    `json:"expires_at,omitempty" gorm:"column:expires_at"` // 过期后不再发送，如验证码邮件；0 表示不过期
+	CreatedAt     int64    `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt     int64    `json:"updated_at,omitempty" gorm:"column:updated_at"`
+	SentAt        int64    `json:"sent_at,omitempty" gorm:"column:sent_at"`
+}
+
+// TableName sets the table name for EmailOutbox.
+func (EmailOutbox) TableName() string {
+	return "email_outbox"
+}
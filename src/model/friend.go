@@ -13,6 +13,12 @@ type FriendWebsite struct {
 	IsDied    bool   `json:"is_died,omitempty" gorm:"column:is_died"`
 	EnableRss bool   `json:"enable_rss,omitempty" gorm:"column:enable_rss"`
 	UpdatedAt int64  `json:"updated_at,omitempty" gorm:"column:updated_at"`
+
+	// 反链检查
+	FriendsPageURL     string `json:"friends_page_url,omitempty" gorm:"column:friends_page_url"`
+	HasBacklink        bool   `json:"has_backlink,omitempty" gorm:"column:has_backlink"`
+	BacklinkCheckedAt  int64  `json:"backlink_checked_at,omitempty" gorm:"column:backlink_checked_at"`
+	BacklinkLastSeenAt int64  `json:"backlink_last_seen_at,omitempty" gorm:"column:backlink_last_seen_at"`
 }
 
 // TableName sets the insert table name for this struct type.
@@ -20,12 +26,97 @@ func (FriendWebsite) TableName() string {
 	return "friend_link"
 }
 
+// FriendLinkCheck 友链单次爬取检查记录
+type FriendLinkCheck struct {
+	ID           int    `json:"id" gorm:"column:id;primaryKey"`
+	FriendLinkID int    `json:"friend_link_id" gorm:"column:friend_link_id"`
+	Status       string `json:"status" gorm:"column:status"`
+	HTTPStatus   int    `json:"http_status" gorm:"column:http_status"`
+	LatencyMs    int64  `json:"latency_ms" gorm:"column:latency_ms"`
+	RedirectURL  string `json:"redirect_url,omitempty" gorm:"column:redirect_url"`
+	Error        string `json:"error,omitempty" gorm:"column:error"`
+	CheckedAt    int64  `json:"checked_at" gorm:"column:checked_at"`
+}
+
+// TableName sets the table name for FriendLinkCheck.
+func (FriendLinkCheck) TableName() string {
+	return "friend_link_check"
+}
+
+// FriendLinkURLChange 友链地址变更记录
+// status: observing（观察中）、pending（待审核）、applied（自动更新）、approved（已批准）、
+// rejected（已拒绝）、dismissed（已失效）、manual（管理员手动修改）
+type FriendLinkURLChange struct {
+	ID           int    `json:"id" gorm:"column:id;primaryKey"`
+	FriendLinkID int    `json:"friend_link_id" gorm:"column:friend_link_id"`
+	OldURL       string `json:"old_url" gorm:"column:old_url"`
+	NewURL       string `json:"new_url" gorm:"column:new_url"`
+	Status       string `json:"status" gorm:"column:status"`
+	Hits         int    `json:"hits" gorm:"column:hits"`
+	Reason       string `json:"reason,omitempty" gorm:"column:reason"`
+	FirstSeenAt  int64  `json:"first_seen_at" gorm:"column:first_seen_at"`
+	LastSeenAt   int64  `json:"last_seen_at" gorm:"column:last_seen_at"`
+	ResolvedAt   int64  `json:"resolved_at,omitempty" gorm:"column:resolved_at"`
+}
+
+// TableName sets the table name for FriendLinkURLChange.
+func (FriendLinkURLChange) TableName() string {
+	return "friend_link_url_change"
+}
+
+// FriendLinkApplication 通过邮箱令牌提交的友链申请
+// status: pending（待审核）、approved（已通过）、rejected（已拒绝，对应的友链已删除）
+type FriendLinkApplication struct {
+	ID             int    `json:"id" gorm:"column:id;primaryKey"`
+	FriendLinkID   int    `json:"friend_link_id" gorm:"column:friend_link_id"`
+	Email          string `json:"email" gorm:"column:email"`
+	Name           string `json:"name" gorm:"column:website_name"`
+	Link           string `json:"link" gorm:"column:website_url"`
+	Avatar         string `json:"avatar" gorm:"column:website_icon_url"`
+	Description    string `json:"description" gorm:"column:description"`
+	FriendsPageURL string `json:"friends_page_url,omitempty" gorm:"column:friends_page_url"`
+	Status         string `json:"status" gorm:"column:status"`
+	Reason         string `json:"reason,omitempty" gorm:"column:reason"`
+	Language       string `json:"language,omitempty" gorm:"column:language"` // 申请人的语言，用于选择通知邮件模板
+	CreatedAt      int64  `json:"created_at" gorm:"column:created_at"`
+	ReviewedAt     int64  `json:"reviewed_at,omitempty" gorm:"column:reviewed_at"`
+
+	FriendLinkApplicationCheck
+}
+
+// TableName sets the table name for FriendLinkApplication.
+func (FriendLinkApplication) TableName() string {
+	return "friend_link_application"
+}
+
+// FriendLinkApplicationCheck 提交时的自动预检结果，nil 表示未检查
+type FriendLinkApplicationCheck struct {
+	Reachable   *bool  `json:"reachable" gorm:"column:reachable"`
+	HTTPStatus  int    `json:"http_status,omitempty" gorm:"column:http_status"`
+	CheckError  string `json:"check_error,omitempty" gorm:"column:check_error"`
+	HasBacklink *bool  `json:"has_backlink" gorm:"column:has_backlink"`
+	DuplicateOf []int  `json:"duplicate_of" gorm:"column:duplicate_of;serializer:json"` // 同域名的已有友链
+	CheckedAt   int64  `json:"checked_at" gorm:"column:checked_at"`
+}
+
+// FriendLinkUptime 友链在统计窗口内的可用率
+type FriendLinkUptime struct {
+	FriendLinkID int     `json:"friend_link_id,omitempty"`
+	Name         string  `json:"name,omitempty"`
+	Checks       int     `json:"checks"`
+	Up           int     `json:"up"`
+	Uptime       float64 `json:"uptime"`         // 百分比，0-100
+	AvgLatencyMs int64   `json:"avg_latency_ms"` // 仅统计成功的检查
+	Since        int64   `json:"since"`
+}
+
 // FriendLinkQueryOptions defines the options for querying friend links.
 type FriendLinkQueryOptions struct {
 	Status   string   // Single status filter, e.g., "pending"
 	Statuses []string // Multiple statuses for IN or NOT IN clauses, e.g., {"ignored"}
 	IsDied   *bool    // Filter by is_died status
 	NotIn    bool     // If true, use NOT IN for Statuses
+	Backlink string   // Backlink filter: "present", "missing", "lost" or "unchecked"
 	Search   string   // Search keyword
 	Offset   int      // Pagination offset
 	Limit    int      // Pagination limit
@@ -45,7 +136,42 @@ type FriendRss struct {
 	Name         string `json:"name" gorm:"column:name"`
 	RssURL       string `json:"rss_url" gorm:"column:rss_url"`
 	Status       string `json:"status" gorm:"column:status"`
+	ETag         string `json:"etag,omitempty" gorm:"column:etag"`
+	LastModified string `json:"last_modified,omitempty" gorm:"column:last_modified"`
 	UpdatedAt    int64  `json:"updated_at" gorm:"column:updated_at"`
+
+	// 抓取健康状态
+	LastFetchedAt  int64                `json:"last_fetched_at" gorm:"column:last_fetched_at"`
+	LastSuccessAt  int64                `json:"last_success_at" gorm:"column:last_success_at"`
+	LastError      string               `json:"last_error" gorm:"column:last_error"`
+	LastHTTPStatus int                  `json:"last_http_status" gorm:"column:last_http_status"`
+	FailCount      int                  `json:"fail_count" gorm:"column:fail_count"`
+	History        []FriendRssStatusLog `json:"history,omitempty" gorm:"-"`
+}
+
+// FriendRssStatusLog records a status transition of a friend_rss feed.
+type FriendRssStatusLog struct {
+	ID         int    `json:"id" gorm:"column:id;primaryKey"`
+	RssID      int    `json:"rss_id" gorm:"column:rss_id"`
+	FromStatus string `json:"from_status" gorm:"column:from_status"`
+	ToStatus   string `json:"to_status" gorm:"column:to_status"`
+	Reason     string `json:"reason" gorm:"column:reason"`
+	CreatedAt  int64  `json:"created_at" gorm:"column:created_at"`
+}
+
+// TableName sets the table name for FriendRssStatusLog.
+func (FriendRssStatusLog) TableName() string {
+	return "friend_rss_status_log"
+}
+
+// FriendRssHealth holds the result of a single feed fetch.
+type FriendRssHealth struct {
+	Status         string
+	FetchedAt      int64
+	Success        bool
+	LastError      string
+	LastHTTPStatus int
+	FailCount      int
 }
 
 // RssPost represents an article from an RSS feed.
@@ -57,6 +183,9 @@ type RssPost struct {
 	Description string `json:"description" gorm:"column:description"`
 	Author      string `json:"author" gorm:"column:author"`
 	Time        int64  `json:"time" gorm:"column:time"`
+	GUID        string `json:"guid,omitempty" gorm:"column:guid"`
+	UpdatedAt   int64  `json:"updated_at" gorm:"column:updated_at"`
+	LinkKey     string `json:"-" gorm:"column:link_key"` // 规范化后的链接
 }
 
 // TableName sets the table name for FriendRss.
@@ -82,3 +211,46 @@ type QueryFriendRssResponse struct {
 	Feeds []FriendRss `json:"feeds"`
 	Total int64       `json:"total"`
 }
+
+// FeedPost represents an RSS post joined with its feed and friend link, used for feed output.
+type FeedPost struct {
+	RssPost
+	FeedName string `json:"feed_name" gorm:"column:feed_name"`
+	SiteName string `json:"site_name" gorm:"column:site_name"`
+	SiteURL  string `json:"site_url" gorm:"column:site_url"`
+}
+
+// OPMLFeed is a subscribable feed with its friend link, listed in the OPML export.
+type OPMLFeed struct {
+	Name        string `gorm:"column:name"`
+	RssURL      string `gorm:"column:rss_url"`
+	SiteName    string `gorm:"column:site_name"`
+	SiteURL     string `gorm:"column:site_url"`
+	Description string `gorm:"column:description"`
+}
+
+// RssPruneFeedCount 单个订阅源待清理的文章数量
+type RssPruneFeedCount struct {
+	RssID int    `json:"rss_id"`
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// RssPruneReport 按保留策略清理文章的结果
+type RssPruneReport struct {
+	DryRun bool                `json:"dry_run"`
+	Policy RssRetentionConfig  `json:"policy"`
+	Total  int                 `json:"total"`
+	Feeds  []RssPruneFeedCount `json:"feeds"`
+	Posts  []RssPost           `json:"posts,omitempty"`
+}
+
+// FriendImportEntry is a friend link read from an external friend list, matched by website_url.
+// Empty fields are left untouched on existing links.
+type FriendImportEntry struct {
+	Name        string
+	Link        string
+	Avatar      string
+	Description string
+	RssURL      string // 订阅地址，为空时由爬虫自动发现
+}
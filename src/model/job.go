@@ -0,0 +1,37 @@
+package model
+
+// JobRun describes one execution of a background job (crawl, RSS parse, image check, ...).
+type JobRun struct {
+	ID         string         `json:"id" gorm:"primaryKey"`
+	Job        string         `json:"job"`                           // e.g. "friend_crawl", "rss_parse"
+	Target     int            `json:"target,omitempty"`              // Friend link or feed ID when the run covers a single item
+	Trigger    string         `json:"trigger"`                       // "cron", "startup" or "manual"
+	State      string         `json:"state"`                         // "queued", "running", "succeeded", "failed" or "skipped"
+	Total      int            `json:"total"`                         // Number of items to process, 0 until known
+	Done       int            `json:"done"`                          // Items processed successfully
+	Failed     int            `json:"failed"`                        // Items that failed
+	Error      string         `json:"error,omitempty"`               // Why the run failed or was skipped
+	Errors     []JobItemError `json:"errors" gorm:"serializer:json"` // Per-item errors, capped
+	CreatedAt  int64          `json:"created_at"`                    // When the run was triggered
+	StartedAt  int64          `json:"started_at,omitempty"`          // 0 while queued
+	FinishedAt int64          `json:"finished_at,omitempty"`         // 0 until the run ends
+}
+
+// TableName specifies the table name for the JobRun model.
+func (JobRun) TableName() string {
+	return "job_runs"
+}
+
+// JobItemError records why a single item of a job run failed.
+type JobItemError struct {
+	Item  string `json:"item"`
+	Error string `json:"error"`
+	At    int64  `json:"at"`
+}
+
+// JobInfo describes a registered job.
+type JobInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Targetable  bool   `json:"targetable"` // Whether the job can run for a single item
+}
@@ -0,0 +1,49 @@
+package model
+
+// MemosListResponse mirrors the memos v1 ListMemos response.
+type MemosListResponse struct {
+	Memos         []MemosMemo `json:"memos"`
+	NextPageToken string      `json:"nextPageToken"`
+}
+
+// MemosMemo mirrors a memos v1 memo. Both the older (rowStatus, resources)
+// and newer (state, attachments) field names are filled for widget compatibility.
+type MemosMemo struct {
+	Name        string          `json:"name"`
+	UID         string          `json:"uid"`
+	RowStatus   string          `json:"rowStatus"`
+	State       string          `json:"state"`
+	Creator     string          `json:"creator"`
+	CreateTime  string          `json:"createTime"`
+	UpdateTime  string          `json:"updateTime"`
+	DisplayTime string          `json:"displayTime"`
+	Content     string          `json:"content"`
+	Snippet     string          `json:"snippet"`
+	Visibility  string          `json:"visibility"`
+	Pinned      bool            `json:"pinned"`
+	Tags        []string        `json:"tags"`
+	Resources   []MemosResource `json:"resources"`
+	Attachments []MemosResource `json:"attachments"`
+	Relations   []interface{}   `json:"relations"`
+	Reactions   []MemosReaction `json:"reactions"`
+}
+
+// MemosResource mirrors a memos v1 resource (attachment).
+type MemosResource struct {
+	Name         string `json:"name"`
+	UID          string `json:"uid"`
+	CreateTime   string `json:"createTime"`
+	Filename     string `json:"filename"`
+	ExternalLink string `json:"externalLink"`
+	Type         string `json:"type"`
+	Size         string `json:"size"`
+	Memo         string `json:"memo"`
+}
+
+// MemosReaction mirrors a memos v1 reaction.
+type MemosReaction struct {
+	ID           int    `json:"id"`
+	Creator      string `json:"creator"`
+	ContentID    string `json:"contentId"`
+	ReactionType string `json:"reactionType"`
+}
@@ -0,0 +1,23 @@
+package model
+
+// SchemaMigration records a versioned migration that has been applied.
+type SchemaMigration struct {
+	Version   string `json:"version" gorm:"primaryKey"` // Migration file name without ".sql", e.g. "008_01_create_job_runs"
+	Checksum  string `json:"checksum"`                  // SHA-256 of the file when it was applied
+	AppliedAt int64  `json:"applied_at"`
+}
+
+// TableName specifies the table name for the SchemaMigration model.
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// MigrationStatus describes a migration file and whether it has been applied.
+type MigrationStatus struct {
+	Version   string `json:"version"`
+	Applied   bool   `json:"applied"`
+	AppliedAt int64  `json:"applied_at,omitempty"`
+	Modified  bool   `json:"modified,omitempty"` // The file changed after it was applied
+	HasDown   bool   `json:"has_down"`           // A .down.sql file exists
+	Missing   bool   `json:"missing,omitempty"`  // Recorded as applied but the file no longer exists
+}
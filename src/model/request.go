@@ -32,6 +32,15 @@ type PostQuery struct {
 	PageSize     int  `form:"page_size"`
 }
 
+// SearchQuery defines the query parameters for full-text search.
+type SearchQuery struct {
+	Q        string `form:"q"`
+	Type     string `form:"type"`
+	Status   string `form:"status"`
+	Page     int    `form:"page"`
+	PageSize int    `form:"page_size"`
+}
+
 // UploadResourceReq 定义了上传资源请求的表单字段。
 type UploadResourceReq struct {
 	Path      string `form:"path"`
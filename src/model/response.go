@@ -30,6 +30,15 @@ type FriendLinkDTO struct {
 	EnableRss   bool   `json:"enable_rss"`
 	IsDied      bool   `json:"is_died,omitempty"`
 	UpdatedAt   int64  `json:"updated_at"`
+
+	FriendsPageURL string `json:"friends_page_url,omitempty"`
+
+	// 仅管理接口返回
+	HasBacklink        *bool             `json:"has_backlink,omitempty"`
+	BacklinkCheckedAt  int64             `json:"backlink_checked_at,omitempty"`
+	BacklinkLastSeenAt int64             `json:"backlink_last_seen_at,omitempty"`
+	Uptime             *FriendLinkUptime `json:"uptime,omitempty"`
+	RecentChecks       []FriendLinkCheck `json:"recent_checks,omitempty"`
 }
 
 // NewSuccessResponse 创建成功响应
@@ -62,6 +71,30 @@ type PublicMomentWithMedia struct {
 	SelectedReaction string         `json:"selected_reaction,omitempty"`
 }
 
+// PostSearchResult represents an RSS post matched by a search, with highlighted fragments.
+type PostSearchResult struct {
+	ID             int    `json:"id"`
+	RssID          int    `json:"rss_id"`
+	Title          string `json:"title"`
+	Link           string `json:"link"`
+	Author         string `json:"author"`
+	Time           int64  `json:"time"`
+	FeedName       string `json:"feed_name,omitempty"`
+	TitleHighlight string `json:"title_highlight"`
+	Snippet        string `json:"snippet"`
+}
+
+// MomentSearchResult represents a moment matched by a search, with a highlighted snippet.
+type MomentSearchResult struct {
+	ID          int    `json:"id"`
+	Content     string `json:"content"`
+	Status      string `json:"status"`
+	MessageLink string `json:"message_link,omitempty"`
+	CreatedAt   int64  `json:"created_at"`
+	UpdatedAt   int64  `json:"updated_at"`
+	Snippet     string `json:"snippet"`
+}
+
 // QueryMomentsResponse defines the response for querying moments.
 type QueryMomentsResponse struct {
 	Moments []MomentWithMedia `json:"moments"`
@@ -9,6 +9,17 @@ type StatusData struct {
 	RssPostCount        int                     `json:"rss_post_count"`
 	FriendLinkStatusPie []FriendLinkStatusCount `json:"friend_link_status_pie"`
 	RssPostCountMonthly []RssPostCountMonthly   `json:"rss_post_count_monthly"`
+	FriendLinkUptime    FriendLinkUptime        `json:"friend_link_uptime"`
+	LowestUptimeLinks   []FriendLinkUptime      `json:"lowest_uptime_links"`
+	FriendLinkBacklink  FriendLinkBacklinkCount `json:"friend_link_backlink"`
+}
+
+// FriendLinkBacklinkCount holds the count of friend links by backlink state.
+type FriendLinkBacklinkCount struct {
+	Present   int `json:"present"`
+	Missing   int `json:"missing"`
+	Lost      int `json:"lost"` // 曾经有反链、最近一次检查未找到
+	Unchecked int `json:"unchecked"`
 }
 
 // FriendLinkStatusCount holds the count of friend links by status.
@@ -0,0 +1,15 @@
+package model
+
+// Token 持久化的短期令牌，(kind, key) 唯一
+type Token struct {
+	Kind      string `json:"kind" gorm:"column:kind;primaryKey"`
+	Key       string `json:"key" gorm:"column:key;primaryKey"`
+	Value     string `json:"value" gorm:"column:value"`
+	ExpiresAt int64  `json:"expires_at" gorm:"column:expires_at"`
+	CreatedAt int64  `json:"created_at" gorm:"column:created_at"`
+}
+
+// TableName sets the table name for Token.
+func (Token) TableName() string {
+	return "tokens"
+}
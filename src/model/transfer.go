@@ -0,0 +1,145 @@
+package model
+
+// ExportVersion is the version of the export bundle format.
+const ExportVersion = 1
+
+// ExportBundle is a portable copy of the blog content.
+// Records reference each other by natural keys instead of database IDs.
+type ExportBundle struct {
+	Version     int                `json:"version"`
+	ExportedAt  int64              `json:"exported_at"`
+	FriendLinks []ExportFriendLink `json:"friend_links"`
+	Moments     []ExportMoment     `json:"moments"`
+	Images      []ExportImage      `json:"images"`
+}
+
+// ExportFriendLink is a friend link with its feeds, keyed by website_url.
+type ExportFriendLink struct {
+	Name           string       `json:"name"`
+	Link           string       `json:"link"`
+	Avatar         string       `json:"avatar,omitempty"`
+	Description    string       `json:"description"`
+	Email          string       `json:"email,omitempty"`
+	Status         string       `json:"status"`
+	EnableRss      bool         `json:"enable_rss"`
+	FriendsPageURL string       `json:"friends_page_url,omitempty"`
+	Feeds          []ExportFeed `json:"feeds,omitempty"`
+}
+
+// ExportFeed is an RSS feed with its posts, keyed by rss_url.
+type ExportFeed struct {
+	Name   string       `json:"name"`
+	RssURL string       `json:"rss_url"`
+	Status string       `json:"status"`
+	Posts  []ExportPost `json:"posts,omitempty"`
+}
+
+// ExportPost is an RSS post, keyed by link.
+type ExportPost struct {
+	Title       string `json:"title"`
+	Link        string `json:"link"`
+	Description string `json:"description"`
+	Author      string `json:"author,omitempty"`
+	Time        int64  `json:"time"`
+	GUID        string `json:"guid,omitempty"`
+}
+
+// ExportMoment is a moment with its media and reactions.
+// Moments synced from a chat are keyed by (channel_id, message_id), others by (content, created_at).
+type ExportMoment struct {
+	Content     string              `json:"content"`
+	Status      string              `json:"status"`
+	GuildID     int64               `json:"guild_id,omitempty"`
+	ChannelID   int64               `json:"channel_id,omitempty"`
+	MessageID   int64               `json:"message_id,omitempty"`
+	MessageLink string              `json:"message_link,omitempty"`
+	CreatedAt   int64               `json:"created_at"`
+	Media       []ExportMomentMedia `json:"media,omitempty"`
+	Reactions   []ExportReaction    `json:"reactions,omitempty"`
+}
+
+// ExportMomentMedia is a media file of a moment, keyed by media_url within the moment.
+type ExportMomentMedia struct {
+	Name      string `json:"name,omitempty"`
+	MediaURL  string `json:"media_url"`
+	MediaType string `json:"media_type"`
+	IsLocal   int    `json:"is_local"`
+	IsDeleted int    `json:"is_deleted"`
+}
+
+// ExportReaction is a reaction to a moment, identified by the visitor fingerprint.
+type ExportReaction struct {
+	Fingerprint string `json:"fingerprint"`
+	Reaction    string `json:"reaction"`
+	CreatedAt   int64  `json:"created_at"`
+}
+
+// ExportImage is an image record, keyed by url.
+type ExportImage struct {
+	Name      string `json:"name"`
+	URL       string `json:"url"`
+	LocalPath string `json:"local_path,omitempty"`
+	IsLocal   int    `json:"is_local"`
+	IsOss     int    `json:"is_oss"`
+	Status    string `json:"status"`
+}
+
+// ImportOptions controls how an import is merged into the existing data.
+type ImportOptions struct {
+	Overwrite bool // Replace differing local records with the imported ones instead of keeping them
+	DryRun    bool // Report what would change without writing anything
+}
+
+// ImportCount counts the outcome of an import for one kind of record.
+type ImportCount struct {
+	Added     int `json:"added"`
+	Updated   int `json:"updated"`
+	Unchanged int `json:"unchanged"`
+	Skipped   int `json:"skipped"`
+}
+
+// ImportConflict describes an imported record whose natural key exists with different content.
+type ImportConflict struct {
+	Type       string   `json:"type"`       // e.g. "friend_link", "post", "file"
+	Key        string   `json:"key"`        // Natural key of the record
+	Fields     []string `json:"fields"`     // Fields that differ
+	Resolution string   `json:"resolution"` // "kept_local" or "overwritten"
+}
+
+// ImportReport summarizes an import.
+type ImportReport struct {
+	DryRun    bool                    `json:"dry_run"`
+	Counts    map[string]*ImportCount `json:"counts"`
+	Conflicts []ImportConflict        `json:"conflicts"`
+	Errors    []string                `json:"errors,omitempty"` // Records that could not be imported
+	// Media holds the URLs of media attached to imported moments, including media that already existed.
+	// Importers that bring their own files use it to write only the files of accepted moments.
+	Media map[string]bool `json:"-"`
+}
+
+// NewImportReport creates an empty report.
+func NewImportReport(dryRun bool) *ImportReport {
+	return &ImportReport{DryRun: dryRun, Counts: map[string]*ImportCount{}, Conflicts: []ImportConflict{}, Media: map[string]bool{}}
+}
+
+// Count returns the counter for a kind of record, creating it on first use.
+func (r *ImportReport) Count(kind string) *ImportCount {
+	count, ok := r.Counts[kind]
+	if !ok {
+		count = &ImportCount{}
+		r.Counts[kind] = count
+	}
+	return count
+}
+
+// Conflict records a conflict and counts the record as updated or skipped.
+func (r *ImportReport) Conflict(kind, key string, fields []string, overwrite bool) {
+	resolution := "kept_local"
+	if overwrite {
+		resolution = "overwritten"
+		r.Count(kind).Updated++
+	} else {
+		r.Count(kind).Skipped++
+	}
+	r.Conflicts = append(r.Conflicts, ImportConflict{Type: kind, Key: key, Fields: fields, Resolution: resolution})
+}
@@ -0,0 +1,141 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/mattn/go-sqlite3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// ErrInvalidBackup is returned when a file cannot be restored: it is not a healthy SQLite
+// database, was not created by this application, or has a schema newer than this build.
+var ErrInvalidBackup = errors.New("invalid backup")
+
+// legacyBackupTables are the baseline tables a backup without schema_migrations must contain.
+var legacyBackupTables = []string{"friend_link", "friend_rss", "friend_rss_post", "moments"}
+
+// VacuumInto writes a consistent, compacted copy of the database to path, which must not exist.
+func VacuumInto(db *gorm.DB, path string) error {
+	if err := db.Exec("VACUUM INTO ?", path).Error; err != nil {
+		return fmt.Errorf("could not write backup %s: %w", path, err)
+	}
+	return nil
+}
+
+// ValidateBackup checks that path holds a healthy database whose applied migrations
+// are all known to fsys, so restoring it never moves the schema past this build.
+// Databases without schema_migrations are accepted if they contain the baseline tables;
+// Migrate brings them up to date after the restore.
+func ValidateBackup(path string, fsys fs.FS) error {
+	backup, err := gorm.Open(sqlite.Open("file:"+path+"?mode=ro"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		return fmt.Errorf("%w: could not open file: %v", ErrInvalidBackup, err)
+	}
+	if sqlDB, err := backup.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+
+	var result string
+	if err := backup.Raw("PRAGMA integrity_check").Scan(&result).Error; err != nil {
+		return fmt.Errorf("%w: not a SQLite database: %v", ErrInvalidBackup, err)
+	}
+	if result != "ok" {
+		return fmt.Errorf("%w: integrity check failed: %s", ErrInvalidBackup, result)
+	}
+
+	// 早于版本化迁移的备份没有 schema_migrations，恢复后由 Migrate 补齐；
+	// 但必须包含本程序的基础表，避免误用其他程序的数据库覆盖当前数据
+	if !backup.Migrator().HasTable("schema_migrations") {
+		for _, table := range legacyBackupTables {
+			if !backup.Migrator().HasTable(table) {
+				return fmt.Errorf("%w: table %s is missing", ErrInvalidBackup, table)
+			}
+		}
+		return nil
+	}
+	applied, err := appliedMigrations(backup)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+	files, err := loadMigrations(fsys)
+	if err != nil {
+		return err
+	}
+	known := make(map[string]bool, len(files))
+	for _, file := range files {
+		known[file.Version] = true
+	}
+	for version := range applied {
+		if !known[version] {
+			return fmt.Errorf("%w: migration %s is unknown to this build", ErrInvalidBackup, version)
+		}
+	}
+	return nil
+}
+
+// RestoreFrom replaces the contents of the live database with the database at path
+// using the SQLite online backup API, so open connections keep working afterwards.
+func RestoreFrom(db *gorm.DB, path string) error {
+	source, err := gorm.Open(sqlite.Open("file:"+path+"?mode=ro"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		return fmt.Errorf("could not open backup: %w", err)
+	}
+	sourceDB, err := source.DB()
+	if err != nil {
+		return fmt.Errorf("could not get sql.DB from gorm: %w", err)
+	}
+	defer sourceDB.Close()
+
+	targetDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("could not get sql.DB from gorm: %w", err)
+	}
+
+	ctx := context.Background()
+	targetConn, err := targetDB.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("could not get database connection: %w", err)
+	}
+	defer targetConn.Close()
+	sourceConn, err := sourceDB.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("could not get backup connection: %w", err)
+	}
+	defer sourceConn.Close()
+
+	return targetConn.Raw(func(target any) error {
+		return sourceConn.Raw(func(src any) error {
+			targetSQLite, ok := target.(*sqlite3.SQLiteConn)
+			if !ok {
+				return fmt.Errorf("unexpected database driver %T", target)
+			}
+			sourceSQLite, ok := src.(*sqlite3.SQLiteConn)
+			if !ok {
+				return fmt.Errorf("unexpected database driver %T", src)
+			}
+
+			backup, err := targetSQLite.Backup("main", sourceSQLite, "main")
+			if err != nil {
+				return fmt.Errorf("could not start restore: %w", err)
+			}
+			// Copy all pages in one step; the live database stays locked meanwhile
+			if _, err := backup.Step(-1); err != nil {
+				backup.Finish()
+				return fmt.Errorf("could not copy backup: %w", err)
+			}
+			if err := backup.Finish(); err != nil {
+				return fmt.Errorf("could not finish restore: %w", err)
+			}
+			return nil
+		})
+	})
+}
@@ -0,0 +1,108 @@
+package repositories
+
+import (
+	"blog_api/migrations"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestValidateBackup(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(t *testing.T, db *gorm.DB)
+		raw     string // written instead of a database when set
+		wantErr bool
+	}{
+		{
+			name: "migrated database",
+			setup: func(t *testing.T, db *gorm.DB) {
+				if err := MigrateUp(db, migrations.FS); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+		{
+			name: "database from before versioned migrations",
+			setup: func(t *testing.T, db *gorm.DB) {
+				if err := MigrateUp(db, migrations.FS); err != nil {
+					t.Fatal(err)
+				}
+				if err := db.Exec("DROP TABLE schema_migrations").Error; err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+		{
+			name: "unrelated database",
+			setup: func(t *testing.T, db *gorm.DB) {
+				if err := db.Exec("CREATE TABLE memo (id INTEGER PRIMARY KEY, content TEXT)").Error; err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantErr: true,
+		},
+		{
+			name: "legacy database missing a baseline table",
+			setup: func(t *testing.T, db *gorm.DB) {
+				if err := MigrateUp(db, migrations.FS); err != nil {
+					t.Fatal(err)
+				}
+				if err := db.Exec("DROP TABLE schema_migrations").Error; err != nil {
+					t.Fatal(err)
+				}
+				if err := db.Exec("DROP TABLE moments").Error; err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantErr: true,
+		},
+		{
+			name: "migration unknown to this build",
+			setup: func(t *testing.T, db *gorm.DB) {
+				if err := MigrateUp(db, migrations.FS); err != nil {
+					t.Fatal(err)
+				}
+				if err := db.Exec("INSERT INTO schema_migrations (version) VALUES ('999_01_future')").Error; err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantErr: true,
+		},
+		{
+			name:    "not a database",
+			raw:     "hello",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var path string
+			if tt.setup != nil {
+				db := openTestDB(t)
+				tt.setup(t, db)
+				path = filepath.Join(t.TempDir(), "backup.db")
+				if err := VacuumInto(db, path); err != nil {
+					t.Fatal(err)
+				}
+			} else {
+				path = filepath.Join(t.TempDir(), "backup.db")
+				if err := os.WriteFile(path, []byte(tt.raw), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := ValidateBackup(path, migrations.FS)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateBackup() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidBackup) {
+				t.Errorf("error %v is not ErrInvalidBackup", err)
+			}
+		})
+	}
+}
@@ -1,12 +1,12 @@
 package repositories
 
 import (
+	"blog_api/migrations"
 	"blog_api/src/model"
+	friendsRepositories "blog_api/src/repositories/friend"
 	"fmt"
 	"log"
 	"os"
-	"path/filepath"
-	"sort"
 	"time"
 
 	"gorm.io/driver/sqlite"
@@ -16,6 +16,39 @@ import (
 
 // InitDB initializes the database and runs migrations.
 func InitDB(cfg *model.Config) (*gorm.DB, error) {
+	db, err := OpenDB(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := Migrate(db); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// Migrate brings the schema up to date: versioned migrations and FTS5 indexes.
+func Migrate(db *gorm.DB) error {
+	// Run versioned migrations embedded in the binary
+	if err := MigrateUp(db, migrations.FS); err != nil {
+		return err
+	}
+
+	// 回填版本化迁移无法计算的字段
+	if err := friendsRepositories.BackfillRssPostLinkKeys(db); err != nil {
+		return err
+	}
+
+	if err := setupFullTextSearch(db); err != nil {
+		return err
+	}
+
+	log.Println("Database migrations completed successfully.")
+	return nil
+}
+
+// OpenDB opens the configured database without running migrations.
+func OpenDB(cfg *model.Config) (*gorm.DB, error) {
 	dbPath := cfg.Data.Database.Path
 	if dbPath == "" {
 		return nil, fmt.Errorf("database path is not configured")
@@ -52,26 +85,5 @@ func InitDB(cfg *model.Config) (*gorm.DB, error) {
 		return nil, fmt.Errorf("could not connect to database via gorm: %w", err)
 	}
 
-	// Run migrations
-	migrationFiles, err := filepath.Glob("migrations/*.sql")
-	if err != nil {
-		return nil, fmt.Errorf("could not find migration files: %w", err)
-	}
-	sort.Strings(migrationFiles)
-
-	for _, file := range migrationFiles {
-		log.Printf("运行迁移: %s\n", file)
-		content, err := os.ReadFile(file)
-		if err != nil {
-			return nil, fmt.Errorf("could not read migration file %s: %w", file, err)
-		}
-
-		// Execute the entire migration file content at once
-		if err := db.Exec(string(content)).Error; err != nil {
-			return nil, fmt.Errorf("could not execute migration statement in file %s: %w", file, err)
-		}
-	}
-
-	log.Println("Database migrations completed successfully.")
 	return db, nil
 }
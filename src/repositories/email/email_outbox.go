@@ -0,0 +1,153 @@
+package emailRepositories
+
+import (
+	"blog_api/src/model"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrEmailNotFailed 只有发送失败的邮件可以重试
+var ErrEmailNotFailed = errors.New("email has not failed")
+
+// listColumns are the columns returned by QueryEmails; bodies are only loaded for a single email.
+var listColumns = []string{"id", "template", "recipients", "subject", "status", "attempts", "last_error",
+	"next_attempt_at", "expires_at", "created_at", "updated_at", "sent_at"}
+
+// CreateEmail inserts a queued email.
+func CreateEmail(db *gorm.DB, email *model.EmailOutbox) error {
+	if err := db.Create(email).Error; err != nil {
+		return fmt.Errorf("could not insert email: %w", err)
+	}
+	return nil
+}
+
+// GetEmailByID fetches a single email including its bodies.
+func GetEmailByID(db *gorm.DB, id int) (model.EmailOutbox, error) {
+	var email model.EmailOutbox
+	err := db.Where("id = ?", id).First(&email).Error
+	return email, err
+}
+
+// QueryEmails lists emails without their bodies, newest first. An empty status disables the filter.
+func QueryEmails(db *gorm.DB, status string, page, pageSize int) ([]model.EmailOutbox, int64, error) {
+	var emails []model.EmailOutbox
+	var total int64
+
+	query := db.Model(&model.EmailOutbox{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("could not count emails: %w", err)
+	}
+	if page > 0 && pageSize > 0 {
+		query = query.Offset((page - 1) * pageSize).Limit(pageSize)
+	}
+	if err := query.Select(listColumns).Order("created_at DESC, id DESC").Find(&emails).Error; err != nil {
+		return nil, 0, fmt.Errorf("could not query emails: %w", err)
+	}
+	return emails, total, nil
+}
+
+// GetDueEmails returns up to limit queued emails whose next attempt is due, oldest first.
+func GetDueEmails(db *gorm.DB, now int64, limit int) ([]model.EmailOutbox, error) {
+	var emails []model.EmailOutbox
+	err := db.Where("status = ? AND next_attempt_at <= ?", "queued", now).
+		Order("next_attempt_at, id").Limit(limit).Find(&emails).Error
+	if err != nil {
+		return nil, fmt.Errorf("could not query due emails: %w", err)
+	}
+	return emails, nil
+}
+
+// GetNextAttemptAt returns when the next queued email is due, or 0 if none is queued.
+func GetNextAttemptAt(db *gorm.DB) (int64, error) {
+	var next *int64
+	if err := db.Model(&model.EmailOutbox{}).Where("status = ?", "queued").
+		Select("MIN(next_attempt_at)").Scan(&next).Error; err != nil {
+		return 0, fmt.Errorf("could not query next email attempt: %w", err)
+	}
+	if next == nil {
+		return 0, nil
+	}
+	return *next, nil
+}
+
+// ClaimEmail moves a queued email to sending and counts the attempt. It returns false when
+// another worker claimed the email first.
+func ClaimEmail(db *gorm.DB, id int) (bool, error) {
+	res := db.Model(&model.EmailOutbox{}).
+		Where("id = ? AND status = ?", id, "queued").
+		Updates(map[string]interface{}{"status": "sending", "attempts": gorm.Expr("attempts + 1")})
+	if res.Error != nil {
+		return false, fmt.Errorf("could not claim email %d: %w", id, res.Error)
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// MarkEmailSent records a successful delivery.
+func MarkEmailSent(db *gorm.DB, id int) error {
+	err := db.Model(&model.EmailOutbox{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"status": "sent", "last_error": "", "sent_at": time.Now().Unix()}).Error
+	if err != nil {
+		return fmt.Errorf("could not mark email %d as sent: %w", id, err)
+	}
+	return nil
+}
+
+// MarkEmailRetry records a failed attempt and schedules the next one.
+func MarkEmailRetry(db *gorm.DB, id int, lastError string, nextAttemptAt int64) error {
+	err := db.Model(&model.EmailOutbox{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"status": "queued", "last_error": lastError, "next_attempt_at": nextAttemptAt}).Error
+	if err != nil {
+		return fmt.Errorf("could not reschedule email %d: %w", id, err)
+	}
+	return nil
+}
+
+// MarkEmailFailed gives up on an email.
+func MarkEmailFailed(db *gorm.DB, id int, lastError string) error {
+	err := db.Model(&model.EmailOutbox{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"status": "failed", "last_error": lastError}).Error
+	if err != nil {
+		return fmt.Errorf("could not mark email %d as failed: %w", id, err)
+	}
+	return nil
+}
+
+// RetryEmail queues a failed email again with a fresh set of attempts.
+func RetryEmail(db *gorm.DB, id int) error {
+	res := db.Model(&model.EmailOutbox{}).
+		Where("id = ? AND status = ?", id, "failed").
+		Updates(map[string]interface{}{"status": "queued", "attempts": 0, "next_attempt_at": time.Now().Unix()})
+	if res.Error != nil {
+		return fmt.Errorf("could not retry email %d: %w", id, res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return ErrEmailNotFailed
+	}
+	return nil
+}
+
+// RequeueInterruptedEmails puts emails left sending by a previous process back into the queue.
+func RequeueInterruptedEmails(db *gorm.DB) (int64, error) {
+	res := db.Model(&model.EmailOutbox{}).Where("status = ?", "sending").
+		Updates(map[string]interface{}{"status": "queued", "next_attempt_at": time.Now().Unix()})
+	if res.Error != nil {
+		return 0, fmt.Errorf("could not requeue interrupted emails: %w", res.Error)
+	}
+	return res.RowsAffected, nil
+}
+
+// PruneEmails deletes sent and failed emails created before cutoff.
+func PruneEmails(db *gorm.DB, cutoff int64) (int64, error) {
+	res := db.Where("status IN ? AND created_at < ?", []string{"sent", "failed"}, cutoff).Delete(&model.EmailOutbox{})
+	if res.Error != nil {
+		return 0, fmt.Errorf("could not prune emails: %w", res.Error)
+	}
+	return res.RowsAffected, nil
+}
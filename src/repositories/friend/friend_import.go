@@ -0,0 +1,168 @@
+package friendsRepositories
+
+import (
+	"blog_api/src/model"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var errDryRun = errors.New("dry run")
+
+// ImportFriendLinks merges entries from an external friend list into friend_link and friend_rss and fills report.
+// Links are matched by website_url (ignoring a trailing slash) and feeds by rss_url. Only the fields an entry
+// provides are compared: empty local fields are filled in, differing ones are replaced only when opts.Overwrite is set.
+// New links are created as survival with RSS enabled. With opts.DryRun nothing is written.
+func ImportFriendLinks(db *gorm.DB, entries []model.FriendImportEntry, opts model.ImportOptions, report *model.ImportReport) error {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		for _, entry := range entries {
+			if err := importFriendLink(tx, entry, opts, report); err != nil {
+				return err
+			}
+		}
+		if opts.DryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if errors.Is(err, errDryRun) {
+		return nil
+	}
+	return err
+}
+
+func importFriendLink(tx *gorm.DB, entry model.FriendImportEntry, opts model.ImportOptions, report *model.ImportReport) error {
+	entry.Link = strings.TrimSpace(entry.Link)
+	if !isHTTPURL(entry.Link) {
+		key := entry.Link
+		if key == "" {
+			key = entry.Name
+		}
+		importFailed(report, "friend_link", key, errors.New("link must be an http(s) URL"))
+		return nil
+	}
+
+	var link model.FriendWebsite
+	err := tx.Where("website_url IN ?", urlVariants(entry.Link)).Order("id").First(&link).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		name := entry.Name
+		if name == "" {
+			name = entry.Link
+		}
+		link = model.FriendWebsite{
+			Name:      name,
+			Link:      entry.Link,
+			Avatar:    resolveAvatarURL(entry.Avatar, entry.Link),
+			Info:      entry.Description,
+			Status:    "survival",
+			EnableRss: true,
+		}
+		if err := tx.Create(&link).Error; err != nil {
+			importFailed(report, "friend_link", entry.Link, err)
+			return nil
+		}
+		report.Count("friend_link").Added++
+	case err != nil:
+		return fmt.Errorf("could not look up friend link %s: %w", entry.Link, err)
+	default:
+		// 本地为空的字段直接补全，本地已有不同值的字段按 opts.Overwrite 处理
+		fills := map[string]interface{}{}
+		updates := map[string]interface{}{}
+		fields := []string{}
+		compare := func(field, local, imported string) {
+			switch {
+			case imported == "" || imported == local:
+			case local == "":
+				fills[field] = imported
+			default:
+				updates[field] = imported
+				fields = append(fields, field)
+			}
+		}
+		compare("website_name", link.Name, entry.Name)
+		compare("website_icon_url", link.Avatar, resolveAvatarURL(entry.Avatar, entry.Link))
+		compare("description", link.Info, entry.Description)
+		if opts.Overwrite {
+			for field, value := range updates {
+				fills[field] = value
+			}
+		}
+		if len(fills) > 0 {
+			if err := tx.Model(&link).Updates(fills).Error; err != nil {
+				return fmt.Errorf("could not update friend link %s: %w", link.Link, err)
+			}
+		}
+		switch {
+		case len(fields) > 0:
+			report.Conflict("friend_link", link.Link, fields, opts.Overwrite)
+		case len(fills) > 0:
+			report.Count("friend_link").Updated++
+		default:
+			report.Count("friend_link").Unchanged++
+		}
+	}
+
+	if entry.RssURL == "" {
+		return nil
+	}
+	return importFriendFeed(tx, link, entry, opts, report)
+}
+
+func importFriendFeed(tx *gorm.DB, link model.FriendWebsite, entry model.FriendImportEntry, opts model.ImportOptions, report *model.ImportReport) error {
+	rssURL := strings.TrimSpace(entry.RssURL)
+	if !isHTTPURL(rssURL) {
+		importFailed(report, "feed", rssURL, errors.New("rss_url must be an http(s) URL"))
+		return nil
+	}
+
+	var feed model.FriendRss
+	err := tx.Where("rss_url = ?", rssURL).First(&feed).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		feed = model.FriendRss{
+			FriendLinkID: link.ID,
+			Name:         link.Name,
+			RssURL:       rssURL,
+			Status:       "survival",
+		}
+		if err := tx.Create(&feed).Error; err != nil {
+			importFailed(report, "feed", rssURL, err)
+			return nil
+		}
+		report.Count("feed").Added++
+	case err != nil:
+		return fmt.Errorf("could not look up feed %s: %w", rssURL, err)
+	case feed.FriendLinkID == link.ID:
+		report.Count("feed").Unchanged++
+	default:
+		// 订阅源已挂在其他友链下
+		if opts.Overwrite {
+			if err := tx.Model(&feed).Update("friend_link_id", link.ID).Error; err != nil {
+				return fmt.Errorf("could not update feed %s: %w", rssURL, err)
+			}
+		}
+		report.Conflict("feed", rssURL, []string{"friend_link_id"}, opts.Overwrite)
+	}
+	return nil
+}
+
+// importFailed records an entry that could not be imported; the import carries on.
+func importFailed(report *model.ImportReport, kind, key string, err error) {
+	report.Count(kind).Skipped++
+	report.Errors = append(report.Errors, fmt.Sprintf("%s %s: %v", kind, key, err))
+}
+
+func isHTTPURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// urlVariants returns the link with and without a trailing slash.
+func urlVariants(link string) []string {
+	trimmed := strings.TrimRight(link, "/")
+	return []string{link, trimmed, trimmed + "/"}
+}
@@ -5,11 +5,16 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"time"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
+// friendLinkFields lists the columns selected when querying friend links.
+const friendLinkFields = "id, website_name, website_url, website_icon_url, description, email, times, status, is_died, enable_rss, updated_at, " +
+	"friends_page_url, has_backlink, backlink_checked_at, backlink_last_seen_at"
+
 // InsertFriendLinks inserts friend links from the configuration if they don't already exist.
 func InsertFriendLinks(db *gorm.DB, friendLinks []model.FriendWebsite) error {
 	var count int64
@@ -46,6 +51,8 @@ func InsertFriendLinks(db *gorm.DB, friendLinks []model.FriendWebsite) error {
 				Info:      link.Info,
 				Status:    "survival",
 				EnableRss: true,
+
+				FriendsPageURL: link.FriendsPageURL,
 			}
 			if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&newLink).Error; err != nil {
 				log.Printf("[db][friend][ERR]无法插入友链 %s: %v", link.Name, err)
@@ -83,6 +90,18 @@ func QueryFriendLinks(db *gorm.DB, opts model.FriendLinkQueryOptions) (model.Que
 		query = query.Where("is_died = ?", *opts.IsDied)
 	}
 
+	// Apply backlink filter
+	switch opts.Backlink {
+	case "present":
+		query = query.Where("backlink_checked_at > 0 AND has_backlink = ?", true)
+	case "missing":
+		query = query.Where("backlink_checked_at > 0 AND has_backlink = ?", false)
+	case "lost":
+		query = query.Where("has_backlink = ? AND backlink_last_seen_at > 0", false)
+	case "unchecked":
+		query = query.Where("backlink_checked_at = 0")
+	}
+
 	// Apply search filter
 	if opts.Search != "" {
 		searchPattern := "%" + opts.Search + "%"
@@ -107,8 +126,7 @@ func QueryFriendLinks(db *gorm.DB, opts model.FriendLinkQueryOptions) (model.Que
 	query = query.Order("updated_at DESC")
 
 	// Select specific fields for the final query
-	selectFields := "id, website_name, website_url, website_icon_url, description, email, times, status, is_died, enable_rss, updated_at"
-	if err := query.Select(selectFields).Find(&resp.Links).Error; err != nil {
+	if err := query.Select(friendLinkFields).Find(&resp.Links).Error; err != nil {
 		return resp, fmt.Errorf("could not query friend links: %w", err)
 	}
 
@@ -123,8 +141,7 @@ func QueryFriendLinks(db *gorm.DB, opts model.FriendLinkQueryOptions) (model.Que
 // GetFriendLinkByID fetches a single friend link by ID.
 func GetFriendLinkByID(db *gorm.DB, id int) (model.FriendWebsite, error) {
 	var link model.FriendWebsite
-	selectFields := "id, website_name, website_url, website_icon_url, description, email, times, status, is_died, enable_rss, updated_at"
-	err := db.Model(&model.FriendWebsite{}).Select(selectFields).Where("id = ?", id).First(&link).Error
+	err := db.Model(&model.FriendWebsite{}).Select(friendLinkFields).Where("id = ?", id).First(&link).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return model.FriendWebsite{}, err
@@ -137,8 +154,7 @@ func GetFriendLinkByID(db *gorm.DB, id int) (model.FriendWebsite, error) {
 // GetFriendLinkByEmail fetches a single friend link by email.
 func GetFriendLinkByEmail(db *gorm.DB, email string) (model.FriendWebsite, error) {
 	var link model.FriendWebsite
-	selectFields := "id, website_name, website_url, website_icon_url, description, email, times, status, is_died, enable_rss, updated_at"
-	err := db.Model(&model.FriendWebsite{}).Select(selectFields).Where("email = ?", email).First(&link).Error
+	err := db.Model(&model.FriendWebsite{}).Select(friendLinkFields).Where("email = ?", email).First(&link).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return model.FriendWebsite{}, err
@@ -163,25 +179,38 @@ func UpdateFriendLink(db *gorm.DB, link model.FriendWebsite, result model.CrawlR
 
 	link.Status = result.Status
 
-	if result.RedirectURL != "" {
-		link.Link = result.RedirectURL
-	}
-
+	// 重定向不在此处直接改写地址，由重定向策略确认后再更新
 	updates := map[string]interface{}{
-		"website_url": link.Link,
 		"description": gorm.Expr("CASE WHEN description = '' THEN ? ELSE description END", result.Description),
 		"status":      link.Status,
 		"times":       link.Times,
 		"is_died":     link.IsDied,
 	}
 
+	if result.Backlink != nil {
+		now := time.Now().Unix()
+		updates["has_backlink"] = *result.Backlink
+		updates["backlink_checked_at"] = now
+		if *result.Backlink {
+			updates["backlink_last_seen_at"] = now
+		} else if link.HasBacklink {
+			log.Printf("[db][friend] 友链 %s (ID %d) 已移除本站链接", link.Name, link.ID)
+		}
+	}
+
 	// 仅当现有 icon 为空时才覆盖，避免已有 icon 被新结果替换
 	if link.Avatar == "" && result.IconURL != "" {
 		updates["website_icon_url"] = resolveAvatarURL(result.IconURL, link.Link)
 	}
 
-	if err := db.Model(&model.FriendWebsite{}).Where("id = ?", link.ID).Updates(updates).Error; err != nil {
-		return fmt.Errorf("could not update friend link with id %d: %w", link.ID, err)
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.FriendWebsite{}).Where("id = ?", link.ID).Updates(updates).Error; err != nil {
+			return fmt.Errorf("could not update friend link with id %d: %w", link.ID, err)
+		}
+		return insertFriendLinkCheck(tx, link.ID, result)
+	})
+	if err != nil {
+		return err
 	}
 
 	log.Printf("为 ID  %d 更新友链. 状态: %s, 时间: %d, is_died: %t", link.ID, link.Status, link.Times, link.IsDied)
@@ -198,6 +227,8 @@ func CreateFriendLink(db *gorm.DB, link model.FriendWebsite) (int64, error) {
 		Email:     link.Email,
 		Status:    "pending",
 		EnableRss: link.EnableRss,
+
+		FriendsPageURL: link.FriendsPageURL,
 	}
 
 	if err := db.Create(&newLink).Error; err != nil {
@@ -217,6 +248,14 @@ func DeleteFriendLinkByID(db *gorm.DB, id uint) (model.FriendWebsite, error) {
 			return fmt.Errorf("could not query friend link for deletion: %w", err)
 		}
 
+		// 手动删除检查记录，避免依赖连接级的外键设置
+		if err := tx.Where("friend_link_id = ?", id).Delete(&model.FriendLinkCheck{}).Error; err != nil {
+			return fmt.Errorf("could not delete friend link checks: %w", err)
+		}
+		if err := tx.Where("friend_link_id = ?", id).Delete(&model.FriendLinkURLChange{}).Error; err != nil {
+			return fmt.Errorf("could not delete friend link url changes: %w", err)
+		}
+
 		res := tx.Where("id = ?", id).Delete(&model.FriendWebsite{})
 		if res.Error != nil {
 			return fmt.Errorf("could not delete friend link: %w", res.Error)
@@ -250,6 +289,7 @@ func UpdateFriendLinkByID(db *gorm.DB, id uint, req model.EditFriendLinkReq) (in
 		"status":           true,
 		"enable_rss":       true,
 		"is_died":          true,
+		"friends_page_url": true,
 	}
 
 	updates := map[string]interface{}{}
@@ -285,6 +325,16 @@ func UpdateFriendLinkByID(db *gorm.DB, id uint, req model.EditFriendLinkReq) (in
 			}
 		}
 
+		// Keep the previous url in the history
+		if newURL, ok := updates["website_url"].(string); ok {
+			var current model.FriendWebsite
+			if err := tx.Select("id, website_url").Where("id = ?", id).First(&current).Error; err == nil {
+				if err := recordManualURLChange(tx, current.ID, current.Link, newURL); err != nil {
+					return err
+				}
+			}
+		}
+
 		// Perform the update
 		result := tx.Model(&model.FriendWebsite{}).Where("id = ?", id).Updates(updates)
 		if result.Error != nil {
@@ -0,0 +1,195 @@
+package friendsRepositories
+
+import (
+	"blog_api/src/model"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrFriendLinkApplicationNotPending 申请已被处理
+var ErrFriendLinkApplicationNotPending = errors.New("friend link application is not pending")
+
+// CreateFriendLinkApplication inserts a pending friend link together with its application.
+// language is the applicant's preferred language for notification emails.
+func CreateFriendLinkApplication(db *gorm.DB, link model.FriendWebsite, language string) (model.FriendLinkApplication, error) {
+	var app model.FriendLinkApplication
+	err := db.Transaction(func(tx *gorm.DB) error {
+		id, err := CreateFriendLink(tx, link)
+		if err != nil {
+			return err
+		}
+		app = model.FriendLinkApplication{
+			FriendLinkID:   int(id),
+			Email:          link.Email,
+			Name:           link.Name,
+			Link:           link.Link,
+			Avatar:         link.Avatar,
+			Description:    link.Info,
+			FriendsPageURL: link.FriendsPageURL,
+			Status:         "pending",
+			Language:       language,
+			CreatedAt:      time.Now().Unix(),
+			FriendLinkApplicationCheck: model.FriendLinkApplicationCheck{
+				DuplicateOf: []int{},
+			},
+		}
+		if err := tx.Create(&app).Error; err != nil {
+			return fmt.Errorf("could not create friend link application: %w", err)
+		}
+		return nil
+	})
+	return app, err
+}
+
+// GetFriendLinkApplicationByID fetches a single application.
+func GetFriendLinkApplicationByID(db *gorm.DB, id int) (model.FriendLinkApplication, error) {
+	var app model.FriendLinkApplication
+	err := db.Where("id = ?", id).First(&app).Error
+	return app, err
+}
+
+// GetLatestFriendLinkApplicationByEmail returns the newest application submitted with the email.
+func GetLatestFriendLinkApplicationByEmail(db *gorm.DB, email string) (model.FriendLinkApplication, error) {
+	var app model.FriendLinkApplication
+	err := db.Where("email = ?", email).Order("created_at DESC, id DESC").First(&app).Error
+	return app, err
+}
+
+// QueryFriendLinkApplications lists applications, newest first. An empty status disables the filter.
+func QueryFriendLinkApplications(db *gorm.DB, status string, page, pageSize int) ([]model.FriendLinkApplication, int64, error) {
+	var apps []model.FriendLinkApplication
+	var total int64
+
+	query := db.Model(&model.FriendLinkApplication{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("could not count friend link applications: %w", err)
+	}
+	if page > 0 && pageSize > 0 {
+		query = query.Offset((page - 1) * pageSize).Limit(pageSize)
+	}
+	if err := query.Order("created_at DESC, id DESC").Find(&apps).Error; err != nil {
+		return nil, 0, fmt.Errorf("could not query friend link applications: %w", err)
+	}
+	return apps, total, nil
+}
+
+// SaveFriendLinkApplicationCheck stores the pre-check result of an application.
+func SaveFriendLinkApplicationCheck(db *gorm.DB, id int, check model.FriendLinkApplicationCheck) error {
+	if check.DuplicateOf == nil {
+		check.DuplicateOf = []int{}
+	}
+	err := db.Model(&model.FriendLinkApplication{ID: id}).
+		Select("reachable", "http_status", "check_error", "has_backlink", "duplicate_of", "checked_at").
+		Updates(&model.FriendLinkApplication{FriendLinkApplicationCheck: check}).Error
+	if err != nil {
+		return fmt.Errorf("could not save friend link application check %d: %w", id, err)
+	}
+	return nil
+}
+
+// FindFriendLinksByDomain returns the ids of friend links on the same host as rawURL (ignoring a www. prefix),
+// excluding excludeID.
+func FindFriendLinksByDomain(db *gorm.DB, rawURL string, excludeID int) ([]int, error) {
+	host := linkHost(rawURL)
+	ids := []int{}
+	if host == "" {
+		return ids, nil
+	}
+
+	var links []model.FriendWebsite
+	if err := db.Model(&model.FriendWebsite{}).Select("id, website_url").
+		Where("id != ? AND website_url LIKE ?", excludeID, "%"+host+"%").
+		Find(&links).Error; err != nil {
+		return nil, fmt.Errorf("could not query friend links by domain: %w", err)
+	}
+	for _, link := range links {
+		if linkHost(link.Link) == host {
+			ids = append(ids, link.ID)
+		}
+	}
+	return ids, nil
+}
+
+// ApproveFriendLinkApplication marks the application approved and puts its friend link into service.
+func ApproveFriendLinkApplication(db *gorm.DB, app model.FriendLinkApplication, reason string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := resolveFriendLinkApplication(tx, app.ID, "approved", reason); err != nil {
+			return err
+		}
+		res := tx.Model(&model.FriendWebsite{}).
+			Where("id = ? AND status = ?", app.FriendLinkID, "pending").
+			Update("status", "survival")
+		if res.Error != nil {
+			return fmt.Errorf("could not update friend link %d: %w", app.FriendLinkID, res.Error)
+		}
+		if res.RowsAffected == 0 {
+			log.Printf("[db][friend] 友链 %d 已不是待审核状态，仅更新申请记录", app.FriendLinkID)
+		}
+		return nil
+	})
+}
+
+// RejectFriendLinkApplication marks the application rejected and deletes its pending friend link,
+// so the applicant can apply again. Links that are no longer pending are kept.
+func RejectFriendLinkApplication(db *gorm.DB, app model.FriendLinkApplication, reason string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := resolveFriendLinkApplication(tx, app.ID, "rejected", reason); err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&model.FriendWebsite{}).Where("id = ? AND status = ?", app.FriendLinkID, "pending").Count(&count).Error; err != nil {
+			return fmt.Errorf("could not query friend link %d: %w", app.FriendLinkID, err)
+		}
+		if count == 0 {
+			return nil
+		}
+		if err := DeleteRssDataByFriendLinkID(tx, app.FriendLinkID); err != nil {
+			return err
+		}
+		if err := tx.Where("friend_link_id = ?", app.FriendLinkID).Delete(&model.FriendLinkCheck{}).Error; err != nil {
+			return fmt.Errorf("could not delete friend link checks: %w", err)
+		}
+		if err := tx.Where("friend_link_id = ?", app.FriendLinkID).Delete(&model.FriendLinkURLChange{}).Error; err != nil {
+			return fmt.Errorf("could not delete friend link url changes: %w", err)
+		}
+		if err := tx.Where("id = ?", app.FriendLinkID).Delete(&model.FriendWebsite{}).Error; err != nil {
+			return fmt.Errorf("could not delete friend link %d: %w", app.FriendLinkID, err)
+		}
+		return nil
+	})
+}
+
+// resolveFriendLinkApplication moves a pending application to status, failing with
+// ErrFriendLinkApplicationNotPending if it was handled meanwhile.
+func resolveFriendLinkApplication(tx *gorm.DB, id int, status, reason string) error {
+	res := tx.Model(&model.FriendLinkApplication{}).
+		Where("id = ? AND status = ?", id, "pending").
+		Updates(map[string]interface{}{"status": status, "reason": reason, "reviewed_at": time.Now().Unix()})
+	if res.Error != nil {
+		return fmt.Errorf("could not update friend link application %d: %w", id, res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return ErrFriendLinkApplicationNotPending
+	}
+	return nil
+}
+
+// linkHost returns the lowercase host of a link without a www. prefix.
+func linkHost(rawURL string) string {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
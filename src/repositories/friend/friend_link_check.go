@@ -0,0 +1,148 @@
+package friendsRepositories
+
+import (
+	"blog_api/src/model"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// FriendLinkUptimeDays 可用率统计窗口（天）
+	FriendLinkUptimeDays = 30
+	// friendLinkCheckRetentionDays 检查记录保留天数
+	friendLinkCheckRetentionDays = 90
+)
+
+// insertFriendLinkCheck records a crawl result and drops the link's checks older than the retention window.
+func insertFriendLinkCheck(tx *gorm.DB, linkID int, result model.CrawlResult) error {
+	now := time.Now()
+	check := model.FriendLinkCheck{
+		FriendLinkID: linkID,
+		Status:       result.Status,
+		HTTPStatus:   result.HTTPStatus,
+		LatencyMs:    result.LatencyMs,
+		RedirectURL:  result.RedirectURL,
+		Error:        result.Error,
+		CheckedAt:    now.Unix(),
+	}
+	if err := tx.Create(&check).Error; err != nil {
+		return fmt.Errorf("could not insert friend link check: %w", err)
+	}
+
+	cutoff := now.AddDate(0, 0, -friendLinkCheckRetentionDays).Unix()
+	if err := tx.Where("friend_link_id = ? AND checked_at < ?", linkID, cutoff).Delete(&model.FriendLinkCheck{}).Error; err != nil {
+		return fmt.Errorf("could not prune friend link checks: %w", err)
+	}
+	return nil
+}
+
+// GetFriendLinkChecks retrieves the most recent checks for the given friend links, newest first.
+func GetFriendLinkChecks(db *gorm.DB, linkIDs []int, limitPerLink int) (map[int][]model.FriendLinkCheck, error) {
+	result := make(map[int][]model.FriendLinkCheck)
+	if len(linkIDs) == 0 {
+		return result, nil
+	}
+
+	var checks []model.FriendLinkCheck
+	query := `
+		SELECT id, friend_link_id, status, http_status, latency_ms, redirect_url, error, checked_at
+		FROM (
+			SELECT *, ROW_NUMBER() OVER (PARTITION BY friend_link_id ORDER BY checked_at DESC, id DESC) AS rank
+			FROM friend_link_check
+			WHERE friend_link_id IN ?
+		)
+		WHERE ? <= 0 OR rank <= ?
+		ORDER BY friend_link_id, checked_at DESC, id DESC
+	`
+	if err := db.Raw(query, linkIDs, limitPerLink, limitPerLink).Scan(&checks).Error; err != nil {
+		return nil, fmt.Errorf("could not query friend link checks: %w", err)
+	}
+
+	for _, check := range checks {
+		result[check.FriendLinkID] = append(result[check.FriendLinkID], check)
+	}
+	return result, nil
+}
+
+// GetFriendLinkUptime computes the uptime of the given friend links over the last FriendLinkUptimeDays days.
+// Links without any check in the window are absent from the result.
+func GetFriendLinkUptime(db *gorm.DB, linkIDs []int) (map[int]model.FriendLinkUptime, error) {
+	result := make(map[int]model.FriendLinkUptime)
+	if len(linkIDs) == 0 {
+		return result, nil
+	}
+
+	since := time.Now().AddDate(0, 0, -FriendLinkUptimeDays).Unix()
+	var rows []model.FriendLinkUptime
+	query := `
+		SELECT friend_link_id,
+			COUNT(*) AS checks,
+			SUM(CASE WHEN status = 'survival' THEN 1 ELSE 0 END) AS up,
+			CAST(COALESCE(AVG(CASE WHEN status = 'survival' THEN latency_ms END), 0) AS INTEGER) AS avg_latency_ms
+		FROM friend_link_check
+		WHERE friend_link_id IN ? AND checked_at >= ?
+		GROUP BY friend_link_id
+	`
+	if err := db.Raw(query, linkIDs, since).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("could not query friend link uptime: %w", err)
+	}
+
+	for _, row := range rows {
+		row.Uptime = uptimePercent(row.Up, row.Checks)
+		row.Since = since
+		result[row.FriendLinkID] = row
+	}
+	return result, nil
+}
+
+// GetFriendLinkUptimeSummary returns the overall uptime across all friend links and the links with the
+// lowest uptime over the last FriendLinkUptimeDays days.
+func GetFriendLinkUptimeSummary(db *gorm.DB, lowestLimit int) (model.FriendLinkUptime, []model.FriendLinkUptime, error) {
+	since := time.Now().AddDate(0, 0, -FriendLinkUptimeDays).Unix()
+
+	var overall model.FriendLinkUptime
+	overallQuery := `
+		SELECT COUNT(*) AS checks,
+			COALESCE(SUM(CASE WHEN status = 'survival' THEN 1 ELSE 0 END), 0) AS up,
+			CAST(COALESCE(AVG(CASE WHEN status = 'survival' THEN latency_ms END), 0) AS INTEGER) AS avg_latency_ms
+		FROM friend_link_check
+		WHERE checked_at >= ?
+	`
+	if err := db.Raw(overallQuery, since).Scan(&overall).Error; err != nil {
+		return overall, nil, fmt.Errorf("could not query friend link uptime summary: %w", err)
+	}
+	overall.Uptime = uptimePercent(overall.Up, overall.Checks)
+	overall.Since = since
+
+	lowest := []model.FriendLinkUptime{}
+	lowestQuery := `
+		SELECT c.friend_link_id, l.website_name AS name,
+			COUNT(*) AS checks,
+			SUM(CASE WHEN c.status = 'survival' THEN 1 ELSE 0 END) AS up,
+			CAST(COALESCE(AVG(CASE WHEN c.status = 'survival' THEN c.latency_ms END), 0) AS INTEGER) AS avg_latency_ms
+		FROM friend_link_check c
+		JOIN friend_link l ON l.id = c.friend_link_id
+		WHERE c.checked_at >= ?
+		GROUP BY c.friend_link_id
+		HAVING up < checks
+		ORDER BY CAST(up AS REAL) / checks ASC, checks DESC
+		LIMIT ?
+	`
+	if err := db.Raw(lowestQuery, since, lowestLimit).Scan(&lowest).Error; err != nil {
+		return overall, nil, fmt.Errorf("could not query lowest uptime friend links: %w", err)
+	}
+	for i := range lowest {
+		lowest[i].Uptime = uptimePercent(lowest[i].Up, lowest[i].Checks)
+		lowest[i].Since = since
+	}
+	return overall, lowest, nil
+}
+
+func uptimePercent(up, checks int) float64 {
+	if checks == 0 {
+		return 0
+	}
+	return float64(int(float64(up)*10000/float64(checks))) / 100
+}
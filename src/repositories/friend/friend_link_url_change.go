@@ -0,0 +1,192 @@
+package friendsRepositories
+
+import (
+	"blog_api/src/model"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrFriendLinkURLChanged 友链地址在变更被处理前已被修改
+var ErrFriendLinkURLChanged = errors.New("friend link url has changed since the redirect was observed")
+
+// ObserveFriendLinkRedirect records that oldURL permanently redirected to newURL during a crawl.
+// An existing observing or pending record for the same target is bumped; observations of other targets are
+// dropped since the redirect is no longer consistent. A rejected target is returned unchanged.
+func ObserveFriendLinkRedirect(db *gorm.DB, linkID int, oldURL, newURL string) (model.FriendLinkURLChange, error) {
+	var change model.FriendLinkURLChange
+	now := time.Now().Unix()
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("friend_link_id = ? AND old_url = ? AND new_url = ? AND status IN ?", linkID, oldURL, newURL, []string{"observing", "pending", "rejected"}).
+			Order("id DESC").
+			First(&change).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("could not query friend link url change: %w", err)
+		}
+
+		if err := tx.Where("friend_link_id = ? AND status = ? AND (old_url != ? OR new_url != ?)", linkID, "observing", oldURL, newURL).
+			Delete(&model.FriendLinkURLChange{}).Error; err != nil {
+			return fmt.Errorf("could not clear stale redirect observations: %w", err)
+		}
+
+		if change.ID == 0 {
+			change = model.FriendLinkURLChange{
+				FriendLinkID: linkID,
+				OldURL:       oldURL,
+				NewURL:       newURL,
+				Status:       "observing",
+				Hits:         1,
+				FirstSeenAt:  now,
+				LastSeenAt:   now,
+			}
+			if err := tx.Create(&change).Error; err != nil {
+				return fmt.Errorf("could not create friend link url change: %w", err)
+			}
+			return nil
+		}
+		if change.Status == "rejected" {
+			return nil
+		}
+
+		change.Hits++
+		change.LastSeenAt = now
+		if err := tx.Model(&model.FriendLinkURLChange{}).Where("id = ?", change.ID).
+			Updates(map[string]interface{}{"hits": change.Hits, "last_seen_at": now}).Error; err != nil {
+			return fmt.Errorf("could not update friend link url change: %w", err)
+		}
+		return nil
+	})
+	return change, err
+}
+
+// ClearFriendLinkRedirectObservations drops the unconfirmed redirect observations of a friend link.
+func ClearFriendLinkRedirectObservations(db *gorm.DB, linkID int) error {
+	if err := db.Where("friend_link_id = ? AND status = ?", linkID, "observing").Delete(&model.FriendLinkURLChange{}).Error; err != nil {
+		return fmt.Errorf("could not clear redirect observations: %w", err)
+	}
+	return nil
+}
+
+// MarkFriendLinkURLChangePending moves a confirmed redirect to pending admin review.
+func MarkFriendLinkURLChangePending(db *gorm.DB, id int) error {
+	if err := db.Model(&model.FriendLinkURLChange{}).Where("id = ?", id).Update("status", "pending").Error; err != nil {
+		return fmt.Errorf("could not update friend link url change %d: %w", id, err)
+	}
+	return nil
+}
+
+// GetFriendLinkURLChangeByID fetches a single url change record.
+func GetFriendLinkURLChangeByID(db *gorm.DB, id int) (model.FriendLinkURLChange, error) {
+	var change model.FriendLinkURLChange
+	err := db.Where("id = ?", id).First(&change).Error
+	return change, err
+}
+
+// ApplyFriendLinkURLChange updates the friend link to the new url and resolves the change with the given status
+// ("applied" or "approved"). It fails with ErrFriendLinkURLChanged if the link no longer points at the old url,
+// in which case the change is dismissed.
+func ApplyFriendLinkURLChange(db *gorm.DB, change model.FriendLinkURLChange, status string) error {
+	now := time.Now().Unix()
+	var staleErr error
+	err := db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&model.FriendWebsite{}).
+			Where("id = ? AND website_url = ?", change.FriendLinkID, change.OldURL).
+			Update("website_url", change.NewURL)
+		if res.Error != nil {
+			return fmt.Errorf("could not update friend link url: %w", res.Error)
+		}
+
+		resolved := map[string]interface{}{"status": status, "resolved_at": now}
+		if res.RowsAffected == 0 {
+			resolved["status"] = "dismissed"
+			resolved["reason"] = "friend link url changed before the change was applied"
+			staleErr = ErrFriendLinkURLChanged
+		}
+		if err := tx.Model(&model.FriendLinkURLChange{}).Where("id = ?", change.ID).Updates(resolved).Error; err != nil {
+			return fmt.Errorf("could not resolve friend link url change %d: %w", change.ID, err)
+		}
+		if staleErr != nil {
+			return nil
+		}
+
+		// 地址已变更，其余未处理的记录随之失效
+		if err := tx.Model(&model.FriendLinkURLChange{}).
+			Where("friend_link_id = ? AND id != ? AND status IN ?", change.FriendLinkID, change.ID, []string{"observing", "pending"}).
+			Updates(map[string]interface{}{"status": "dismissed", "resolved_at": now}).Error; err != nil {
+			return fmt.Errorf("could not dismiss other friend link url changes: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return staleErr
+}
+
+// RejectFriendLinkURLChange rejects a pending change; the same redirect will not be suggested again.
+func RejectFriendLinkURLChange(db *gorm.DB, id int, reason string) error {
+	updates := map[string]interface{}{
+		"status":      "rejected",
+		"reason":      reason,
+		"resolved_at": time.Now().Unix(),
+	}
+	if err := db.Model(&model.FriendLinkURLChange{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+		return fmt.Errorf("could not reject friend link url change %d: %w", id, err)
+	}
+	return nil
+}
+
+// recordManualURLChange keeps the previous url in the history when an admin edits website_url.
+func recordManualURLChange(tx *gorm.DB, linkID int, oldURL, newURL string) error {
+	if oldURL == newURL {
+		return nil
+	}
+	now := time.Now().Unix()
+	change := model.FriendLinkURLChange{
+		FriendLinkID: linkID,
+		OldURL:       oldURL,
+		NewURL:       newURL,
+		Status:       "manual",
+		Hits:         0,
+		FirstSeenAt:  now,
+		LastSeenAt:   now,
+		ResolvedAt:   now,
+	}
+	if err := tx.Create(&change).Error; err != nil {
+		return fmt.Errorf("could not record friend link url change: %w", err)
+	}
+
+	if err := tx.Model(&model.FriendLinkURLChange{}).
+		Where("friend_link_id = ? AND status IN ?", linkID, []string{"observing", "pending"}).
+		Updates(map[string]interface{}{"status": "dismissed", "resolved_at": now}).Error; err != nil {
+		return fmt.Errorf("could not dismiss friend link url changes: %w", err)
+	}
+	return nil
+}
+
+// QueryFriendLinkURLChanges lists url changes, newest first. Zero linkID or empty status disables the filter.
+func QueryFriendLinkURLChanges(db *gorm.DB, linkID int, status string, page, pageSize int) ([]model.FriendLinkURLChange, int64, error) {
+	var changes []model.FriendLinkURLChange
+	var total int64
+
+	query := db.Model(&model.FriendLinkURLChange{})
+	if linkID > 0 {
+		query = query.Where("friend_link_id = ?", linkID)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("could not count friend link url changes: %w", err)
+	}
+	if page > 0 && pageSize > 0 {
+		query = query.Offset((page - 1) * pageSize).Limit(pageSize)
+	}
+	if err := query.Order("last_seen_at DESC, id DESC").Find(&changes).Error; err != nil {
+		return nil, 0, fmt.Errorf("could not query friend link url changes: %w", err)
+	}
+	return changes, total, nil
+}
@@ -46,6 +46,31 @@ func QueryFriendRss(db *gorm.DB, opts model.FriendRssQueryOptions) (model.QueryF
 	return resp, nil
 }
 
+// GetOPMLFeeds returns the publicly visible feeds: the feed is not paused and its friend link
+// is neither dead, ignored nor pending review.
+func GetOPMLFeeds(db *gorm.DB) ([]model.OPMLFeed, error) {
+	var feeds []model.OPMLFeed
+	err := db.Table("friend_rss AS r").
+		Select("r.name, r.rss_url, l.website_name AS site_name, l.website_url AS site_url, l.description").
+		Joins("JOIN friend_link l ON l.id = r.friend_link_id").
+		Where("l.is_died = ?", false).
+		Where("l.status NOT IN ?", []string{"ignored", "pending"}).
+		Where("r.status != ?", "pause").
+		Order("l.id, r.id").
+		Scan(&feeds).Error
+	if err != nil {
+		return nil, fmt.Errorf("could not query opml feeds: %w", err)
+	}
+	return feeds, nil
+}
+
+// GetFriendRssByID fetches a single friend RSS feed by ID.
+func GetFriendRssByID(db *gorm.DB, id int) (model.FriendRss, error) {
+	var feed model.FriendRss
+	err := db.Where("id = ?", id).First(&feed).Error
+	return feed, err
+}
+
 // CreateFriendRssFeeds creates a new friend_rss record, avoiding duplicates.
 func CreateFriendRssFeeds(db *gorm.DB, friendLinkID int, rssURL string, name string) (*model.FriendRss, error) {
 	if rssURL == "" {
@@ -86,6 +111,9 @@ func DeleteFriendRssByID(db *gorm.DB, id uint) (int64, error) {
 		if err := tx.Where("rss_id = ?", id).Delete(&model.RssPost{}).Error; err != nil {
 			return fmt.Errorf("删除 RSS 文章失败: %w", err)
 		}
+		if err := tx.Where("rss_id = ?", id).Delete(&model.FriendRssStatusLog{}).Error; err != nil {
+			return fmt.Errorf("删除 RSS 状态记录失败: %w", err)
+		}
 
 		// GORM can delete with a primary key
 		result := tx.Delete(&model.FriendRss{}, id)
@@ -130,6 +158,9 @@ func DeleteRssDataByFriendLinkID(tx *gorm.DB, friendLinkID int) error {
 	if err := tx.Where("rss_id IN ?", rssIDs).Delete(&model.RssPost{}).Error; err != nil {
 		return fmt.Errorf("could not delete rss posts for friend_link_id %d: %w", friendLinkID, err)
 	}
+	if err := tx.Where("rss_id IN ?", rssIDs).Delete(&model.FriendRssStatusLog{}).Error; err != nil {
+		return fmt.Errorf("could not delete rss status logs for friend_link_id %d: %w", friendLinkID, err)
+	}
 
 	// Delete the RSS feeds themselves
 	if err := tx.Where("friend_link_id = ?", friendLinkID).Delete(&model.FriendRss{}).Error; err != nil {
@@ -166,6 +197,12 @@ func UpdateFriendRssByID(db *gorm.DB, id uint, req model.EditFriendRssReq) (int6
 		return 0, nil
 	}
 
+	// 订阅地址变更后，旧的缓存校验头不再有效
+	if _, ok := updates["rss_url"]; ok {
+		updates["etag"] = ""
+		updates["last_modified"] = ""
+	}
+
 	var rowsAffected int64
 	err := db.Transaction(func(tx *gorm.DB) error {
 		// Perform the update
@@ -184,3 +221,92 @@ func UpdateFriendRssByID(db *gorm.DB, id uint, req model.EditFriendRssReq) (int6
 	log.Printf("[db][friend_rss] Updated friend_rss with ID: %d. Rows affected: %d", id, rowsAffected)
 	return rowsAffected, nil
 }
+
+// UpdateFriendRssCacheHeaders stores the ETag and Last-Modified validators returned by the feed server.
+func UpdateFriendRssCacheHeaders(db *gorm.DB, id int, etag, lastModified string) error {
+	updates := map[string]interface{}{
+		"etag":          etag,
+		"last_modified": lastModified,
+	}
+	if err := db.Model(&model.FriendRss{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+		return fmt.Errorf("could not update cache headers for friend_rss id %d: %w", id, err)
+	}
+	return nil
+}
+
+// UpdateFriendRssHealth stores the result of a feed fetch.
+// The status is only changed when it still equals fromStatus, so a feed paused meanwhile is left untouched,
+// and every applied transition is recorded in friend_rss_status_log.
+func UpdateFriendRssHealth(db *gorm.DB, id int, fromStatus string, health model.FriendRssHealth) error {
+	updates := map[string]interface{}{
+		"last_fetched_at":  health.FetchedAt,
+		"last_error":       health.LastError,
+		"last_http_status": health.LastHTTPStatus,
+		"fail_count":       health.FailCount,
+	}
+	if health.Success {
+		updates["last_success_at"] = health.FetchedAt
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.FriendRss{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+			return fmt.Errorf("could not update health for friend_rss id %d: %w", id, err)
+		}
+
+		if health.Status == "" || health.Status == fromStatus {
+			return nil
+		}
+		result := tx.Model(&model.FriendRss{}).Where("id = ? AND status = ?", id, fromStatus).Update("status", health.Status)
+		if result.Error != nil {
+			return fmt.Errorf("could not update status for friend_rss id %d: %w", id, result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		reason := health.LastError
+		if health.Success {
+			reason = "recovered"
+		}
+		entry := model.FriendRssStatusLog{
+			RssID:      id,
+			FromStatus: fromStatus,
+			ToStatus:   health.Status,
+			Reason:     reason,
+			CreatedAt:  health.FetchedAt,
+		}
+		if err := tx.Create(&entry).Error; err != nil {
+			return fmt.Errorf("could not record status transition for friend_rss id %d: %w", id, err)
+		}
+		log.Printf("[db][friend_rss] RSS 源 %d 状态变更: %s -> %s", id, fromStatus, health.Status)
+		return nil
+	})
+}
+
+// GetFriendRssStatusLogs returns the latest status transitions for the given feeds, grouped by rss_id.
+func GetFriendRssStatusLogs(db *gorm.DB, rssIDs []int, limitPerFeed int) (map[int][]model.FriendRssStatusLog, error) {
+	result := make(map[int][]model.FriendRssStatusLog)
+	if len(rssIDs) == 0 {
+		return result, nil
+	}
+
+	// 每个 feed 只取最新的 limitPerFeed 条，limitPerFeed <= 0 时不限制
+	latest := `
+		SELECT id FROM (
+			SELECT id, ROW_NUMBER() OVER (PARTITION BY rss_id ORDER BY created_at DESC, id DESC) AS feed_rank
+			FROM friend_rss_status_log
+			WHERE rss_id IN @rss_ids
+		) WHERE @limit <= 0 OR feed_rank <= @limit
+	`
+	args := map[string]interface{}{"rss_ids": rssIDs, "limit": limitPerFeed}
+
+	var logs []model.FriendRssStatusLog
+	if err := db.Where("id IN (?)", db.Raw(latest, args)).Order("created_at DESC, id DESC").Find(&logs).Error; err != nil {
+		return nil, fmt.Errorf("could not query friend rss status logs: %w", err)
+	}
+
+	for _, entry := range logs {
+		result[entry.RssID] = append(result[entry.RssID], entry)
+	}
+	return result, nil
+}
@@ -0,0 +1,57 @@
+package friendsRepositories
+
+import (
+	"blog_api/src/model"
+	"slices"
+	"testing"
+)
+
+func TestGetFriendRssStatusLogs(t *testing.T) {
+	db := openTestDB(t, "005_01_create_friend_rss_status_log.sql")
+	// Feed 1 has five transitions, feed 2 has one, feed 3 has none in the query
+	for i, entry := range []model.FriendRssStatusLog{
+		{RssID: 1, ToStatus: "s1", CreatedAt: 100},
+		{RssID: 1, ToStatus: "s2", CreatedAt: 200},
+		{RssID: 2, ToStatus: "s1", CreatedAt: 150},
+		{RssID: 1, ToStatus: "s3", CreatedAt: 300},
+		{RssID: 1, ToStatus: "s4", CreatedAt: 300},
+		{RssID: 1, ToStatus: "s5", CreatedAt: 50},
+		{RssID: 3, ToStatus: "s1", CreatedAt: 400},
+	} {
+		if err := db.Create(&entry).Error; err != nil {
+			t.Fatalf("entry %d: %v", i, err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		ids   []int
+		limit int
+		want  map[int][]string
+	}{
+		{"limited per feed", []int{1, 2}, 3, map[int][]string{1: {"s4", "s3", "s2"}, 2: {"s1"}}},
+		{"no limit", []int{1}, 0, map[int][]string{1: {"s4", "s3", "s2", "s1", "s5"}}},
+		{"unknown feed", []int{9}, 3, map[int][]string{}},
+		{"empty ids", nil, 3, map[int][]string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetFriendRssStatusLogs(db, tt.ids, tt.limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d feeds, want %d: %+v", len(got), len(tt.want), got)
+			}
+			for rssID, want := range tt.want {
+				var statuses []string
+				for _, entry := range got[rssID] {
+					statuses = append(statuses, entry.ToStatus)
+				}
+				if !slices.Equal(statuses, want) {
+					t.Errorf("feed %d = %v, want %v", rssID, statuses, want)
+				}
+			}
+		})
+	}
+}
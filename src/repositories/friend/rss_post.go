@@ -4,36 +4,235 @@ import (
 	"blog_api/src/model"
 	"fmt"
 	"log"
+	"sort"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
 )
 
-// InsertRssPost inserts a new post into the database, avoiding duplicates.
-func InsertRssPost(db *gorm.DB, post *model.RssPost) error {
-	var count int64
-	if err := db.Model(&model.RssPost{}).Where("link = ?", post.Link).Count(&count).Error; err != nil {
-		return fmt.Errorf("could not check for existing post: %w", err)
+// UpsertRssPosts inserts new posts of a feed and updates the ones that changed since the last fetch.
+// Posts are matched by feed GUID first, then by normalized link; links already owned by another feed are skipped.
+// Each post is written in its own savepoint, so a GUID collision only skips that post.
+// It returns the number of inserted and updated posts.
+func UpsertRssPosts(db *gorm.DB, rssID int, posts []*model.RssPost) (int, int, error) {
+	if len(posts) == 0 {
+		return 0, 0, nil
 	}
 
-	if count > 0 {
-		log.Printf("链接为 %s 的文章已存在，跳过", post.Link)
+	var existing []model.RssPost
+	if err := db.Where("rss_id = ?", rssID).Find(&existing).Error; err != nil {
+		return 0, 0, fmt.Errorf("could not query existing posts: %w", err)
+	}
+
+	byGUID := make(map[string]*model.RssPost, len(existing))
+	byLink := make(map[string]*model.RssPost, len(existing))
+	for i := range existing {
+		if existing[i].GUID != "" {
+			byGUID[existing[i].GUID] = &existing[i]
+		}
+		if linkKey := NormalizePostLink(existing[i].Link); linkKey != "" {
+			byLink[linkKey] = &existing[i]
+		}
+	}
+
+	foreign, err := foreignPostLinks(db, rssID, posts)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	now := time.Now().Unix()
+	inserted, updated := 0, 0
+	batch := map[*model.RssPost]bool{}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		for _, post := range posts {
+			post.RssID = rssID
+			linkKey := NormalizePostLink(post.Link)
+			post.LinkKey = linkKey
+			if post.GUID == "" {
+				post.GUID = linkKey
+			}
+			// 既没有 GUID 也没有链接的文章无法去重
+			if post.GUID == "" {
+				continue
+			}
+
+			match := byGUID[post.GUID]
+			if match == nil && linkKey != "" {
+				match = byLink[linkKey]
+			}
+			if match == nil {
+				if linkKey != "" && foreign[linkKey] {
+					continue
+				}
+				// 新文章的更新时间即发布时间，之后内容变化时才刷新
+				post.UpdatedAt = post.Time
+				err := tx.Transaction(func(tx *gorm.DB) error { return tx.Create(post).Error })
+				if isUniqueViolation(err) {
+					log.Printf("跳过 GUID 重复的文章: %s", post.Title)
+					continue
+				}
+				if err != nil {
+					return fmt.Errorf("could not insert post %s: %w", post.Link, err)
+				}
+				log.Printf("已插入新文章: %s", post.Title)
+				inserted++
+				batch[post] = true
+				byGUID[post.GUID] = post
+				if linkKey != "" {
+					byLink[linkKey] = post
+				}
+				continue
+			}
+			// 同一批次内重复出现的文章
+			if batch[match] {
+				continue
+			}
+
+			updates := diffRssPost(match, post)
+			if len(updates) == 0 {
+				continue
+			}
+			// 仅补写 guid 的旧文章不视为内容更新
+			_, guidChanged := updates["guid"]
+			contentChanged := len(updates) > 1 || !guidChanged
+			if contentChanged {
+				updates["updated_at"] = now
+			}
+			err := tx.Transaction(func(tx *gorm.DB) error {
+				return tx.Model(&model.RssPost{}).Where("id = ?", match.ID).UpdateColumns(updates).Error
+			})
+			// 新 GUID 已被本 feed 的其他文章占用时保留原 GUID
+			if isUniqueViolation(err) && guidChanged {
+				delete(updates, "guid")
+				if !contentChanged {
+					continue
+				}
+				err = tx.Transaction(func(tx *gorm.DB) error {
+					return tx.Model(&model.RssPost{}).Where("id = ?", match.ID).UpdateColumns(updates).Error
+				})
+			}
+			if isUniqueViolation(err) {
+				log.Printf("跳过 GUID 重复的文章: %s", post.Title)
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("could not update post %d: %w", match.ID, err)
+			}
+			if contentChanged {
+				log.Printf("已更新文章: %s", post.Title)
+				updated++
+			}
+		}
 		return nil
+	})
+	if err != nil {
+		return 0, 0, err
 	}
+	return inserted, updated, nil
+}
 
-	if err := db.Create(post).Error; err != nil {
-		return fmt.Errorf("could not insert post: %w", err)
+// foreignLinkBatch 每次查询其他 feed 文章时匹配的链接数量，避免超出 SQLite 参数上限
+const foreignLinkBatch = 500
+
+// foreignPostLinks returns the normalized links of posts that belong to feeds other than rssID.
+func foreignPostLinks(db *gorm.DB, rssID int, posts []*model.RssPost) (map[string]bool, error) {
+	keys := make([]string, 0, len(posts))
+	for _, post := range posts {
+		if linkKey := NormalizePostLink(post.Link); linkKey != "" {
+			keys = append(keys, linkKey)
+		}
 	}
 
-	log.Printf("已插入新文章: %s", post.Title)
+	foreign := map[string]bool{}
+	for start := 0; start < len(keys); start += foreignLinkBatch {
+		end := min(start+foreignLinkBatch, len(keys))
+		var found []string
+		err := db.Model(&model.RssPost{}).
+			Where("link_key IN ? AND rss_id != ?", keys[start:end], rssID).
+			Distinct().Pluck("link_key", &found).Error
+		if err != nil {
+			return nil, fmt.Errorf("could not check for posts of other feeds: %w", err)
+		}
+		for _, linkKey := range found {
+			foreign[linkKey] = true
+		}
+	}
+	return foreign, nil
+}
+
+// rssPostBackfillBatch 每批回填 link_key 的文章数量
+const rssPostBackfillBatch = 500
+
+// BackfillRssPostLinkKeys fills link_key for posts stored before the column existed.
+func BackfillRssPostLinkKeys(db *gorm.DB) error {
+	total := 0
+	for {
+		var posts []model.RssPost
+		if err := db.Select("id, link").Where("link_key = '' AND TRIM(link) != ''").Limit(rssPostBackfillBatch).Find(&posts).Error; err != nil {
+			return fmt.Errorf("could not query posts without link_key: %w", err)
+		}
+		if len(posts) == 0 {
+			break
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			for _, post := range posts {
+				if err := tx.Model(&model.RssPost{}).Where("id = ?", post.ID).UpdateColumn("link_key", NormalizePostLink(post.Link)).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("could not backfill link_key: %w", err)
+		}
+		total += len(posts)
+	}
+	if total > 0 {
+		log.Printf("已回填 %d 篇文章的规范化链接", total)
+	}
 	return nil
 }
 
+// isUniqueViolation reports whether err is a SQLite unique constraint failure.
+func isUniqueViolation(err error) bool {
+	if err == nil {
+		return false
+	}
+	return strings.Contains(err.Error(), "UNIQUE constraint failed")
+}
+
+// diffRssPost returns the columns of current that differ from incoming.
+func diffRssPost(current *model.RssPost, incoming *model.RssPost) map[string]interface{} {
+	updates := map[string]interface{}{}
+	if current.Title != incoming.Title {
+		updates["title"] = incoming.Title
+	}
+	if current.Link != incoming.Link {
+		updates["link"] = incoming.Link
+		updates["link_key"] = incoming.LinkKey
+	}
+	if current.Description != incoming.Description {
+		updates["description"] = incoming.Description
+	}
+	if current.Author != incoming.Author {
+		updates["author"] = incoming.Author
+	}
+	if current.Time != incoming.Time {
+		updates["time"] = incoming.Time
+	}
+	if current.GUID != incoming.GUID {
+		updates["guid"] = incoming.GUID
+	}
+	return updates
+}
+
 // GetPosts retrieves posts based on the provided query parameters.
 func GetPosts(db *gorm.DB, query *model.PostQuery) ([]model.RssPost, int, error) {
 	var posts []model.RssPost
 	var total int64
 
-	tx := db.Table("friend_rss_post AS p").Select("p.id, p.rss_id, p.title, p.link, p.description, p.author, p.time")
+	tx := db.Table("friend_rss_post AS p").Select("p.id, p.rss_id, p.title, p.link, p.description, p.author, p.time, p.guid, p.updated_at")
 	if query.FriendLinkID != nil {
 		tx = tx.Joins("JOIN friend_rss r ON p.rss_id = r.id").Where("r.friend_link_id = ?", *query.FriendLinkID)
 	}
@@ -66,3 +265,172 @@ func GetPosts(db *gorm.DB, query *model.PostQuery) ([]model.RssPost, int, error)
 
 	return posts, int(total), nil
 }
+
+// GetFeedPosts retrieves the latest posts joined with feed and friend link info for feed output.
+// Like GetOPMLFeeds, only posts of feeds that are not paused and whose friend link is neither dead,
+// ignored nor pending review are included.
+func GetFeedPosts(db *gorm.DB, query *model.PostQuery, limit int) ([]model.FeedPost, error) {
+	var posts []model.FeedPost
+
+	tx := db.Table("friend_rss_post AS p").
+		Select("p.id, p.rss_id, p.title, p.link, p.description, p.author, p.time, p.guid, p.updated_at, r.name AS feed_name, l.website_name AS site_name, l.website_url AS site_url").
+		Joins("JOIN friend_rss r ON p.rss_id = r.id").
+		Joins("JOIN friend_link l ON r.friend_link_id = l.id").
+		Where("l.is_died = ?", false).
+		Where("l.status NOT IN ?", []string{"ignored", "pending"}).
+		Where("r.status != ?", "pause")
+	if query.FriendLinkID != nil {
+		tx = tx.Where("r.friend_link_id = ?", *query.FriendLinkID)
+	}
+	if query.RssID != nil {
+		tx = tx.Where("p.rss_id = ?", *query.RssID)
+	}
+	if limit > 0 {
+		tx = tx.Limit(limit)
+	}
+
+	if err := tx.Order("p.time DESC, p.id DESC").Scan(&posts).Error; err != nil {
+		return nil, fmt.Errorf("could not query feed posts: %w", err)
+	}
+
+	for i := range posts {
+		if posts[i].Time < 0 {
+			posts[i].Time = 0
+		}
+	}
+
+	return posts, nil
+}
+
+// pruneDeleteBatch 每批删除的文章数量，避免超出 SQLite 参数上限
+const pruneDeleteBatch = 500
+
+// rssKeptQuery ranks the posts that survive the age and per-feed rules of the retention policy;
+// total_rank is the rank among them used for the total cap.
+const rssKeptQuery = `
+	WITH ranked AS (
+		SELECT id, time, ROW_NUMBER() OVER (PARTITION BY rss_id ORDER BY time DESC, id DESC) AS feed_rank
+		FROM friend_rss_post
+	), kept AS (
+		SELECT id, time, ROW_NUMBER() OVER (ORDER BY time DESC, id DESC) AS total_rank
+		FROM ranked
+		WHERE (@max_age = 0 OR time >= @cutoff) AND (@max_per_feed = 0 OR feed_rank <= @max_per_feed)
+	)
+`
+
+// rssRetentionArgs returns the named arguments of rssKeptQuery for policy.
+func rssRetentionArgs(policy model.RssRetentionConfig) map[string]interface{} {
+	var cutoff int64
+	if policy.MaxAgeDays > 0 {
+		cutoff = time.Now().AddDate(0, 0, -policy.MaxAgeDays).Unix()
+	}
+	return map[string]interface{}{
+		"max_age":      policy.MaxAgeDays,
+		"cutoff":       cutoff,
+		"max_per_feed": policy.MaxPostsPerFeed,
+		"max_total":    policy.MaxTotalPosts,
+	}
+}
+
+// RssTotalCapCutoff returns the publish time of the oldest post kept under policy.MaxTotalPosts.
+// Newly fetched posts older than it would be pruned right away. It returns 0 when the cap is not set or not reached.
+func RssTotalCapCutoff(db *gorm.DB, policy model.RssRetentionConfig) (int64, error) {
+	if policy.MaxTotalPosts <= 0 {
+		return 0, nil
+	}
+	var times []int64
+	if err := db.Raw(rssKeptQuery+" SELECT time FROM kept WHERE total_rank = @max_total", rssRetentionArgs(policy)).Scan(&times).Error; err != nil {
+		return 0, fmt.Errorf("could not query retention cutoff: %w", err)
+	}
+	if len(times) == 0 {
+		return 0, nil
+	}
+	return times[0], nil
+}
+
+// PruneRssPosts deletes the posts that fall outside the retention policy.
+// A post is kept only if it is within the age limit, among the newest posts of its feed, and among the newest
+// posts overall after the first two rules. With dryRun nothing is deleted; up to sampleLimit affected posts are
+// included in the report.
+func PruneRssPosts(db *gorm.DB, policy model.RssRetentionConfig, dryRun bool, sampleLimit int) (*model.RssPruneReport, error) {
+	report := &model.RssPruneReport{
+		DryRun: dryRun,
+		Policy: policy,
+		Feeds:  []model.RssPruneFeedCount{},
+	}
+	if !policy.Enabled() {
+		return report, nil
+	}
+
+	expired := rssKeptQuery + `
+		SELECT p.id, p.rss_id, p.title, p.link, p.time
+		FROM friend_rss_post p
+		WHERE p.id NOT IN (SELECT id FROM kept WHERE @max_total = 0 OR total_rank <= @max_total)
+	`
+	args := rssRetentionArgs(policy)
+
+	var posts []model.RssPost
+	if err := db.Raw(expired+" ORDER BY p.time ASC, p.id ASC", args).Scan(&posts).Error; err != nil {
+		return nil, fmt.Errorf("could not query expired posts: %w", err)
+	}
+	report.Total = len(posts)
+	if report.Total == 0 {
+		return report, nil
+	}
+
+	counts := map[int]int{}
+	ids := make([]int, 0, len(posts))
+	for _, post := range posts {
+		counts[post.RssID]++
+		ids = append(ids, post.ID)
+	}
+	rssIDs := make([]int, 0, len(counts))
+	for rssID := range counts {
+		rssIDs = append(rssIDs, rssID)
+	}
+	var feeds []model.FriendRss
+	if err := db.Select("id, name").Where("id IN ?", rssIDs).Find(&feeds).Error; err != nil {
+		return nil, fmt.Errorf("could not query feed names: %w", err)
+	}
+	names := make(map[int]string, len(feeds))
+	for _, feed := range feeds {
+		names[feed.ID] = feed.Name
+	}
+	for _, rssID := range rssIDs {
+		report.Feeds = append(report.Feeds, model.RssPruneFeedCount{RssID: rssID, Name: names[rssID], Count: counts[rssID]})
+	}
+	sort.Slice(report.Feeds, func(i, j int) bool {
+		if report.Feeds[i].Count != report.Feeds[j].Count {
+			return report.Feeds[i].Count > report.Feeds[j].Count
+		}
+		return report.Feeds[i].RssID < report.Feeds[j].RssID
+	})
+
+	if sampleLimit > 0 {
+		if len(posts) > sampleLimit {
+			posts = posts[:sampleLimit]
+		}
+		report.Posts = posts
+	}
+
+	if dryRun {
+		return report, nil
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		for start := 0; start < len(ids); start += pruneDeleteBatch {
+			end := start + pruneDeleteBatch
+			if end > len(ids) {
+				end = len(ids)
+			}
+			if err := tx.Where("id IN ?", ids[start:end]).Delete(&model.RssPost{}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not delete expired posts: %w", err)
+	}
+	return report, nil
+}
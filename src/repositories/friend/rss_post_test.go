@@ -0,0 +1,250 @@
+package friendsRepositories
+
+import (
+	"blog_api/migrations"
+	"blog_api/src/model"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openTestDB opens an empty database and runs the given migration files on it.
+func openTestDB(t *testing.T, files ...string) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	for _, name := range files {
+		content, err := fs.ReadFile(migrations.FS, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Exec(string(content)).Error; err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+	}
+	return db
+}
+
+func TestUpsertRssPosts(t *testing.T) {
+	tests := []struct {
+		name         string
+		existing     []model.RssPost
+		posts        []model.RssPost
+		wantInserted int
+		wantUpdated  int
+		// race is a guid that collides right before the post with that guid is inserted
+		race string
+		want []model.RssPost // rows of feed 1 ordered by id; title, link, guid and a non-zero updated_at are compared
+	}{
+		{
+			name:         "new posts are inserted with the normalized link as fallback guid",
+			posts:        []model.RssPost{{Title: "a", Link: "https://Example.com/a/", GUID: "g-a"}, {Title: "b", Link: "https://example.com/b"}},
+			wantInserted: 2,
+			want:         []model.RssPost{{Title: "a", Link: "https://Example.com/a/", GUID: "g-a"}, {Title: "b", Link: "https://example.com/b", GUID: "//example.com/b"}},
+		},
+		{
+			name:        "matched by guid and updated",
+			existing:    []model.RssPost{{RssID: 1, Title: "old", Link: "https://example.com/a", GUID: "g-a"}},
+			posts:       []model.RssPost{{Title: "new", Link: "https://example.com/a-renamed", GUID: "g-a"}},
+			wantUpdated: 1,
+			want:        []model.RssPost{{Title: "new", Link: "https://example.com/a-renamed", GUID: "g-a"}},
+		},
+		{
+			name:     "matched by normalized link and guid backfilled",
+			existing: []model.RssPost{{RssID: 1, Title: "a", Link: "http://example.com/a/", Time: 5, UpdatedAt: 5}},
+			posts:    []model.RssPost{{Title: "a", Link: "http://example.com/a/", GUID: "g-a", Time: 5}},
+			// Backfilling the guid is not a content update
+			want: []model.RssPost{{Title: "a", Link: "http://example.com/a/", GUID: "g-a", UpdatedAt: 5}},
+		},
+		{
+			name:     "link owned by another feed is skipped after normalizing",
+			existing: []model.RssPost{{RssID: 2, Title: "a", Link: "https://EXAMPLE.com/a/"}},
+			posts:    []model.RssPost{{Title: "a", Link: "https://example.com/a", GUID: "g-a"}},
+			want:     []model.RssPost{},
+		},
+		{
+			name:         "posts without guid and link are skipped",
+			existing:     []model.RssPost{{RssID: 1, Title: "empty", Link: ""}},
+			posts:        []model.RssPost{{Title: "x", Link: " "}, {Title: "y", Link: "", GUID: "g-y"}},
+			wantInserted: 1,
+			want:         []model.RssPost{{Title: "empty", Link: ""}, {Title: "y", Link: "", GUID: "g-y"}},
+		},
+		{
+			name:         "duplicates within a batch are inserted once",
+			posts:        []model.RssPost{{Title: "a", Link: "https://example.com/a", GUID: "g-a"}, {Title: "a again", Link: "https://example.com/a/", GUID: "g-a2"}},
+			wantInserted: 1,
+			want:         []model.RssPost{{Title: "a", Link: "https://example.com/a", GUID: "g-a"}},
+		},
+		{
+			name:     "guid taken by a concurrent writer only skips that post",
+			existing: []model.RssPost{{RssID: 1, Title: "a", Link: "https://example.com/a", GUID: "g-a"}},
+			posts: []model.RssPost{
+				{Title: "raced", Link: "https://example.com/raced", GUID: "g-race"},
+				{Title: "a2", Link: "https://example.com/a", GUID: "g-a2"},
+				{Title: "c", Link: "https://example.com/c", GUID: "g-c"},
+			},
+			race:         "g-race",
+			wantInserted: 1,
+			wantUpdated:  1,
+			want: []model.RssPost{
+				{Title: "a2", Link: "https://example.com/a", GUID: "g-a2"},
+				{Title: "c", Link: "https://example.com/c", GUID: "g-c"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := openTestDB(t, "001_03_create_rss_post.sql", "012_03_add_friend_rss_post_guid.sql", "014_01_add_friend_rss_post_link_key.sql")
+			for _, post := range tt.existing {
+				if err := db.Create(&post).Error; err != nil {
+					t.Fatal(err)
+				}
+			}
+			// Existing rows are created without link_key, as before the column existed
+			if err := BackfillRssPostLinkKeys(db); err != nil {
+				t.Fatal(err)
+			}
+			if tt.race != "" {
+				raced := false
+				err := db.Callback().Create().Before("gorm:create").Register("test:race", func(tx *gorm.DB) {
+					post, ok := tx.Statement.Dest.(*model.RssPost)
+					if raced || !ok || post.GUID != tt.race {
+						return
+					}
+					raced = true
+					tx.Session(&gorm.Session{NewDB: true}).Exec("INSERT INTO friend_rss_post (rss_id, title, link, description, time, guid) VALUES (1, 'other', 'https://example.com/other', '', 0, ?)", tt.race)
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			posts := make([]*model.RssPost, len(tt.posts))
+			for i := range tt.posts {
+				posts[i] = &tt.posts[i]
+			}
+
+			inserted, updated, err := UpsertRssPosts(db, 1, posts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if inserted != tt.wantInserted || updated != tt.wantUpdated {
+				t.Errorf("inserted, updated = %d, %d; want %d, %d", inserted, updated, tt.wantInserted, tt.wantUpdated)
+			}
+
+			var rows []model.RssPost
+			if err := db.Where("rss_id = ?", 1).Order("id").Find(&rows).Error; err != nil {
+				t.Fatal(err)
+			}
+			if len(rows) != len(tt.want) {
+				t.Fatalf("rows = %+v, want %+v", rows, tt.want)
+			}
+			for i, want := range tt.want {
+				if rows[i].Title != want.Title || rows[i].Link != want.Link || rows[i].GUID != want.GUID {
+					t.Errorf("row %d = %q %q %q, want %q %q %q", i, rows[i].Title, rows[i].Link, rows[i].GUID, want.Title, want.Link, want.GUID)
+				}
+				if want.UpdatedAt != 0 && rows[i].UpdatedAt != want.UpdatedAt {
+					t.Errorf("row %d updated_at = %d, want %d", i, rows[i].UpdatedAt, want.UpdatedAt)
+				}
+				if rows[i].LinkKey != NormalizePostLink(rows[i].Link) {
+					t.Errorf("row %d link_key = %q, want %q", i, rows[i].LinkKey, NormalizePostLink(rows[i].Link))
+				}
+			}
+		})
+	}
+}
+
+func TestGetFeedPostsVisibility(t *testing.T) {
+	db := openTestDB(t, "001_01_create_frined_link.sql", "001_02_create_friend_rss.sql", "001_03_create_rss_post.sql", "012_03_add_friend_rss_post_guid.sql", "014_01_add_friend_rss_post_link_key.sql")
+	setup := []string{
+		`INSERT INTO friend_link (id, website_url, website_name, description, status, is_died) VALUES
+			(1, 'https://ok.example', 'ok', '', 'survival', 0),
+			(2, 'https://pending.example', 'pending', '', 'pending', 0),
+			(3, 'https://ignored.example', 'ignored', '', 'ignored', 0),
+			(4, 'https://dead.example', 'dead', '', 'died', 1),
+			(5, 'https://timeout.example', 'timeout', '', 'timeout', 0)`,
+		`INSERT INTO friend_rss (id, friend_link_id, name, rss_url, status) VALUES
+			(1, 1, 'ok', 'https://ok.example/rss', 'survival'),
+			(2, 1, 'paused', 'https://ok.example/paused', 'pause'),
+			(3, 2, 'pending', 'https://pending.example/rss', 'survival'),
+			(4, 3, 'ignored', 'https://ignored.example/rss', 'survival'),
+			(5, 4, 'dead', 'https://dead.example/rss', 'survival'),
+			(6, 5, 'timeout', 'https://timeout.example/rss', 'error'),
+			(7, 99, 'orphan', 'https://orphan.example/rss', 'survival')`,
+	}
+	for _, stmt := range setup {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	for rssID := 1; rssID <= 7; rssID++ {
+		post := model.RssPost{RssID: rssID, Title: "post", Link: "https://example.com/" + strconv.Itoa(rssID), Time: int64(rssID)}
+		if err := db.Create(&post).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	posts, err := GetFeedPosts(db, &model.PostQuery{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, post := range posts {
+		got = append(got, post.FeedName)
+	}
+	if want := []string{"timeout", "ok"}; !slices.Equal(got, want) {
+		t.Errorf("feeds = %v, want %v", got, want)
+	}
+}
+
+func TestRssTotalCapCutoff(t *testing.T) {
+	db := openTestDB(t, "001_03_create_rss_post.sql", "012_03_add_friend_rss_post_guid.sql", "014_01_add_friend_rss_post_link_key.sql")
+	// Feed 1 has posts at 10..50, feed 2 at 15 and 25
+	for i, post := range []model.RssPost{
+		{RssID: 1, Time: 10}, {RssID: 1, Time: 20}, {RssID: 1, Time: 30}, {RssID: 1, Time: 40}, {RssID: 1, Time: 50},
+		{RssID: 2, Time: 15}, {RssID: 2, Time: 25},
+	} {
+		post.Title = "post"
+		post.Link = "https://example.com/" + strconv.Itoa(i)
+		if err := db.Create(&post).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		policy model.RssRetentionConfig
+		want   int64
+	}{
+		{"no total cap", model.RssRetentionConfig{MaxAgeDays: 1}, 0},
+		{"cap not reached", model.RssRetentionConfig{MaxTotalPosts: 8}, 0},
+		{"cap reached exactly", model.RssRetentionConfig{MaxTotalPosts: 7}, 10},
+		{"cap below the post count", model.RssRetentionConfig{MaxTotalPosts: 3}, 30},
+		// Per-feed limit keeps 40 and 50 of feed 1 and both posts of feed 2
+		{"after the per-feed limit", model.RssRetentionConfig{MaxTotalPosts: 3, MaxPostsPerFeed: 2}, 25},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RssTotalCapCutoff(db, tt.policy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("RssTotalCapCutoff() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
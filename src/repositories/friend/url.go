@@ -1,6 +1,9 @@
 package friendsRepositories
 
-import "net/url"
+import (
+	"net/url"
+	"strings"
+)
 
 func resolveAvatarURL(avatar string, base string) string {
 	if avatar == "" {
@@ -22,3 +25,50 @@ func resolveAvatarURL(avatar string, base string) string {
 
 	return baseURL.ResolveReference(avatarURL).String()
 }
+
+// trackingParamPrefixes lists query parameters that do not identify an article.
+var trackingParamPrefixes = []string{"utm_"}
+
+// NormalizePostLink normalizes an article link so the same post is recognized across
+// scheme changes, default ports, trailing slashes, fragments and tracking parameters.
+func NormalizePostLink(link string) string {
+	link = strings.TrimSpace(link)
+	if link == "" {
+		return ""
+	}
+
+	u, err := url.Parse(link)
+	if err != nil || u.Host == "" {
+		return link
+	}
+
+	host := strings.ToLower(u.Hostname())
+	port := u.Port()
+	if port != "" && port != "80" && port != "443" {
+		host = host + ":" + port
+	}
+
+	path := u.EscapedPath()
+	if len(path) > 1 {
+		path = strings.TrimRight(path, "/")
+	}
+	if path == "/" {
+		path = ""
+	}
+
+	query := u.Query()
+	for key := range query {
+		for _, prefix := range trackingParamPrefixes {
+			if strings.HasPrefix(strings.ToLower(key), prefix) {
+				query.Del(key)
+				break
+			}
+		}
+	}
+
+	normalized := "//" + host + path
+	if encoded := query.Encode(); encoded != "" {
+		normalized += "?" + encoded
+	}
+	return normalized
+}
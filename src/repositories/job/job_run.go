@@ -0,0 +1,84 @@
+package jobRepositories
+
+import (
+	"blog_api/src/model"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// jobRunRetentionDays 任务执行记录保留天数
+const jobRunRetentionDays = 90
+
+// CreateJobRun inserts a new job run record.
+func CreateJobRun(db *gorm.DB, run *model.JobRun) error {
+	if err := db.Create(run).Error; err != nil {
+		return fmt.Errorf("could not insert job run: %w", err)
+	}
+	return nil
+}
+
+// SaveJobRun updates the state, progress and errors of a job run, and drops records older than the retention window
+// once the run has finished.
+func SaveJobRun(db *gorm.DB, run *model.JobRun) error {
+	if err := db.Save(run).Error; err != nil {
+		return fmt.Errorf("could not update job run %s: %w", run.ID, err)
+	}
+	if run.FinishedAt == 0 {
+		return nil
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -jobRunRetentionDays).Unix()
+	if err := db.Where("created_at < ? AND finished_at != 0", cutoff).Delete(&model.JobRun{}).Error; err != nil {
+		return fmt.Errorf("could not prune job runs: %w", err)
+	}
+	return nil
+}
+
+// GetJobRunByID fetches a single job run.
+func GetJobRunByID(db *gorm.DB, id string) (model.JobRun, error) {
+	var run model.JobRun
+	err := db.Where("id = ?", id).First(&run).Error
+	return run, err
+}
+
+// QueryJobRuns lists job runs, newest first. Empty job or state disables the filter.
+func QueryJobRuns(db *gorm.DB, job, state string, page, pageSize int) ([]model.JobRun, int64, error) {
+	var runs []model.JobRun
+	var total int64
+
+	query := db.Model(&model.JobRun{})
+	if job != "" {
+		query = query.Where("job = ?", job)
+	}
+	if state != "" {
+		query = query.Where("state = ?", state)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("could not count job runs: %w", err)
+	}
+	if page > 0 && pageSize > 0 {
+		query = query.Offset((page - 1) * pageSize).Limit(pageSize)
+	}
+	if err := query.Order("created_at DESC, rowid DESC").Find(&runs).Error; err != nil {
+		return nil, 0, fmt.Errorf("could not query job runs: %w", err)
+	}
+	return runs, total, nil
+}
+
+// FailInterruptedJobRuns marks runs left queued or running by a previous process as failed.
+func FailInterruptedJobRuns(db *gorm.DB) (int64, error) {
+	res := db.Model(&model.JobRun{}).
+		Where("state IN ?", []string{"queued", "running"}).
+		Updates(map[string]interface{}{
+			"state":       "failed",
+			"error":       "interrupted by restart",
+			"finished_at": time.Now().Unix(),
+		})
+	if res.Error != nil {
+		return 0, fmt.Errorf("could not mark interrupted job runs: %w", res.Error)
+	}
+	return res.RowsAffected, nil
+}
@@ -0,0 +1,260 @@
+package repositories
+
+import (
+	"blog_api/src/model"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	downSuffix = ".down.sql"
+	// skipIfColumnsDirective 后跟 table.column 列表；这些字段都已存在时只记录迁移而不执行，
+	// 用于版本化之前在启动时直接补齐字段的数据库
+	skipIfColumnsDirective = "-- skip-if-columns:"
+)
+
+// ErrNoDownMigration is returned when rolling back a migration that has no .down.sql file.
+var ErrNoDownMigration = errors.New("migration has no down file")
+
+// migrationFile is a versioned migration read from the migration filesystem.
+type migrationFile struct {
+	Version  string
+	Up       string
+	Down     string // Empty when there is no .down.sql file
+	Checksum string
+	// SkipIfColumns lists table.column pairs from the skip-if-columns directive of the up file
+	SkipIfColumns []string
+}
+
+// createSchemaMigrations creates the table that records applied migrations.
+func createSchemaMigrations(db *gorm.DB) error {
+	err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+    version TEXT PRIMARY KEY,
+    checksum TEXT NOT NULL DEFAULT '',
+    applied_at INTEGER NOT NULL DEFAULT (strftime('%s','now'))
+)`).Error
+	if err != nil {
+		return fmt.Errorf("could not create schema_migrations: %w", err)
+	}
+	return nil
+}
+
+// loadMigrations reads the *.sql files at the root of fsys, sorted by version.
+func loadMigrations(fsys fs.FS) ([]migrationFile, error) {
+	names, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, fmt.Errorf("could not find migration files: %w", err)
+	}
+
+	byVersion := map[string]*migrationFile{}
+	for _, name := range names {
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, fmt.Errorf("could not read migration file %s: %w", name, err)
+		}
+		down := strings.HasSuffix(name, downSuffix)
+		version := strings.TrimSuffix(name, ".sql")
+		if down {
+			version = strings.TrimSuffix(name, downSuffix)
+		}
+		file, ok := byVersion[version]
+		if !ok {
+			file = &migrationFile{Version: version}
+			byVersion[version] = file
+		}
+		if down {
+			file.Down = string(content)
+		} else {
+			sum := sha256.Sum256(content)
+			file.Up = string(content)
+			file.Checksum = hex.EncodeToString(sum[:])
+			file.SkipIfColumns = parseSkipIfColumns(file.Up)
+		}
+	}
+
+	files := make([]migrationFile, 0, len(byVersion))
+	for _, file := range byVersion {
+		if file.Up == "" {
+			return nil, fmt.Errorf("down migration %s%s has no matching up file", file.Version, downSuffix)
+		}
+		files = append(files, *file)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Version < files[j].Version })
+	return files, nil
+}
+
+// appliedMigrations returns the recorded migrations keyed by version.
+func appliedMigrations(db *gorm.DB) (map[string]model.SchemaMigration, error) {
+	var rows []model.SchemaMigration
+	if err := db.Order("version").Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("could not read schema_migrations: %w", err)
+	}
+	applied := make(map[string]model.SchemaMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+	return applied, nil
+}
+
+// MigrateUp applies the migrations in fsys that have not been recorded yet.
+// Each file runs in its own transaction together with its schema_migrations row,
+// so a failing file leaves no partial changes and is retried on the next start.
+func MigrateUp(db *gorm.DB, fsys fs.FS) error {
+	if err := createSchemaMigrations(db); err != nil {
+		return err
+	}
+	files, err := loadMigrations(fsys)
+	if err != nil {
+		return err
+	}
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if row, ok := applied[file.Version]; ok {
+			if row.Checksum != "" && row.Checksum != file.Checksum {
+				log.Printf("迁移 %s 在应用后被修改，修改不会生效，请新增迁移文件", file.Version)
+			}
+			continue
+		}
+
+		skip, err := columnsExist(db, file.SkipIfColumns)
+		if err != nil {
+			return fmt.Errorf("could not apply migration %s: %w", file.Version, err)
+		}
+		if skip {
+			log.Printf("迁移 %s 的字段已存在，记录为已应用", file.Version)
+		} else {
+			log.Printf("运行迁移: %s", file.Version)
+		}
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if !skip {
+				if err := tx.Exec(file.Up).Error; err != nil {
+					return err
+				}
+			}
+			return tx.Create(&model.SchemaMigration{
+				Version:   file.Version,
+				Checksum:  file.Checksum,
+				AppliedAt: time.Now().Unix(),
+			}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("could not apply migration %s: %w", file.Version, err)
+		}
+	}
+	return nil
+}
+
+// parseSkipIfColumns returns the table.column pairs listed in skip-if-columns directives.
+func parseSkipIfColumns(content string) []string {
+	var columns []string
+	for _, line := range strings.Split(content, "\n") {
+		if rest, ok := strings.CutPrefix(strings.TrimSpace(line), skipIfColumnsDirective); ok {
+			columns = append(columns, strings.Fields(rest)...)
+		}
+	}
+	return columns
+}
+
+// columnsExist reports whether every listed table.column exists; an empty list reports false.
+func columnsExist(db *gorm.DB, columns []string) (bool, error) {
+	if len(columns) == 0 {
+		return false, nil
+	}
+	for _, column := range columns {
+		table, name, ok := strings.Cut(column, ".")
+		if !ok || table == "" || name == "" {
+			return false, fmt.Errorf("invalid column %q in %s directive", column, strings.TrimPrefix(skipIfColumnsDirective, "-- "))
+		}
+		if !db.Migrator().HasColumn(table, name) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// MigrateDown reverts the last steps applied migrations, newest first, each in its own transaction.
+// It returns the reverted versions; a migration without a .down.sql file stops the rollback.
+func MigrateDown(db *gorm.DB, fsys fs.FS, steps int) ([]string, error) {
+	if err := createSchemaMigrations(db); err != nil {
+		return nil, err
+	}
+	files, err := loadMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[string]migrationFile, len(files))
+	for _, file := range files {
+		byVersion[file.Version] = file
+	}
+
+	var rows []model.SchemaMigration
+	if err := db.Order("version DESC").Limit(steps).Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("could not read schema_migrations: %w", err)
+	}
+
+	reverted := []string{}
+	for _, row := range rows {
+		file, ok := byVersion[row.Version]
+		if !ok || file.Down == "" {
+			return reverted, fmt.Errorf("%w: %s", ErrNoDownMigration, row.Version)
+		}
+
+		log.Printf("回滚迁移: %s", row.Version)
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(file.Down).Error; err != nil {
+				return err
+			}
+			return tx.Delete(&model.SchemaMigration{}, "version = ?", row.Version).Error
+		})
+		if err != nil {
+			return reverted, fmt.Errorf("could not revert migration %s: %w", row.Version, err)
+		}
+		reverted = append(reverted, row.Version)
+	}
+	return reverted, nil
+}
+
+// MigrationStatuses lists every migration file and every recorded version, sorted by version.
+func MigrationStatuses(db *gorm.DB, fsys fs.FS) ([]model.MigrationStatus, error) {
+	if err := createSchemaMigrations(db); err != nil {
+		return nil, err
+	}
+	files, err := loadMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]model.MigrationStatus, 0, len(files))
+	for _, file := range files {
+		status := model.MigrationStatus{Version: file.Version, HasDown: file.Down != ""}
+		if row, ok := applied[file.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = row.AppliedAt
+			status.Modified = row.Checksum != "" && row.Checksum != file.Checksum
+			delete(applied, file.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for _, row := range applied {
+		statuses = append(statuses, model.MigrationStatus{Version: row.Version, Applied: true, AppliedAt: row.AppliedAt, Missing: true})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses, nil
+}
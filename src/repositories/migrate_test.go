@@ -0,0 +1,211 @@
+package repositories
+
+import (
+	"blog_api/migrations"
+	"blog_api/src/model"
+	"path/filepath"
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+func appliedVersions(t *testing.T, db *gorm.DB) []string {
+	t.Helper()
+	var versions []string
+	if err := db.Model(&model.SchemaMigration{}).Order("version").Pluck("version", &versions).Error; err != nil {
+		t.Fatal(err)
+	}
+	return versions
+}
+
+func TestMigrateUp(t *testing.T) {
+	files := fstest.MapFS{
+		"001_create_a.sql":      {Data: []byte("CREATE TABLE a (id INTEGER PRIMARY KEY);")},
+		"001_create_a.down.sql": {Data: []byte("DROP TABLE a;")},
+		"002_add_a_name.sql": {Data: []byte("-- skip-if-columns: a.name a.note\n" +
+			"ALTER TABLE a ADD COLUMN name TEXT NOT NULL DEFAULT '';\n" +
+			"ALTER TABLE a ADD COLUMN note TEXT NOT NULL DEFAULT '';")},
+		"003_create_b.sql": {Data: []byte("CREATE TABLE b (id INTEGER PRIMARY KEY);")},
+	}
+	all := []string{"001_create_a", "002_add_a_name", "003_create_b"}
+
+	tests := []struct {
+		name    string
+		setup   []string // statements run before MigrateUp
+		fsys    fstest.MapFS
+		wantErr bool
+		want    []string
+		check   func(t *testing.T, db *gorm.DB)
+	}{
+		{
+			name: "fresh database",
+			fsys: files,
+			want: all,
+			check: func(t *testing.T, db *gorm.DB) {
+				if !db.Migrator().HasColumn("a", "note") || !db.Migrator().HasTable("b") {
+					t.Error("migrations were not applied")
+				}
+			},
+		},
+		{
+			name: "columns added before versioning are recorded without running",
+			setup: []string{
+				"CREATE TABLE a (id INTEGER PRIMARY KEY, name TEXT NOT NULL DEFAULT '', note TEXT NOT NULL DEFAULT '')",
+			},
+			fsys: fstest.MapFS{
+				"001_create_a.sql":   {Data: []byte("CREATE TABLE IF NOT EXISTS a (id INTEGER PRIMARY KEY);")},
+				"002_add_a_name.sql": files["002_add_a_name.sql"],
+				"003_create_b.sql":   files["003_create_b.sql"],
+			},
+			want: all,
+		},
+		{
+			name: "partially present columns still run the migration",
+			setup: []string{
+				"CREATE TABLE a (id INTEGER PRIMARY KEY, name TEXT NOT NULL DEFAULT '')",
+			},
+			fsys: fstest.MapFS{
+				"001_create_a.sql":   {Data: []byte("CREATE TABLE IF NOT EXISTS a (id INTEGER PRIMARY KEY);")},
+				"002_add_a_name.sql": files["002_add_a_name.sql"],
+			},
+			wantErr: true,
+			want:    []string{"001_create_a"},
+		},
+		{
+			name: "only pending migrations run",
+			setup: []string{
+				"CREATE TABLE a (id INTEGER PRIMARY KEY, name TEXT NOT NULL DEFAULT '', note TEXT NOT NULL DEFAULT '')",
+				"CREATE TABLE schema_migrations (version TEXT PRIMARY KEY, checksum TEXT NOT NULL DEFAULT '', applied_at INTEGER NOT NULL DEFAULT 0)",
+				"INSERT INTO schema_migrations (version) VALUES ('001_create_a'), ('002_add_a_name')",
+			},
+			fsys: files,
+			want: all,
+		},
+		{
+			name: "failing migration leaves no partial changes",
+			fsys: fstest.MapFS{
+				"001_create_a.sql": files["001_create_a.sql"],
+				"002_broken.sql":   {Data: []byte("CREATE TABLE c (id INTEGER PRIMARY KEY); INSERT INTO missing VALUES (1);")},
+			},
+			wantErr: true,
+			want:    []string{"001_create_a"},
+			check: func(t *testing.T, db *gorm.DB) {
+				if db.Migrator().HasTable("c") {
+					t.Error("table c from the failed migration was kept")
+				}
+			},
+		},
+		{
+			name:    "down file without up file",
+			fsys:    fstest.MapFS{"001_create_a.down.sql": files["001_create_a.down.sql"]},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := openTestDB(t)
+			for _, stmt := range tt.setup {
+				if err := db.Exec(stmt).Error; err != nil {
+					t.Fatal(err)
+				}
+			}
+			err := MigrateUp(db, tt.fsys)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("MigrateUp() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := appliedVersions(t, db); tt.want != nil && !slices.Equal(got, tt.want) {
+				t.Errorf("applied = %v, want %v", got, tt.want)
+			}
+			if tt.check != nil {
+				tt.check(t, db)
+			}
+			// Running again must not re-apply anything
+			if !tt.wantErr {
+				if err := MigrateUp(db, tt.fsys); err != nil {
+					t.Fatalf("second MigrateUp() error = %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestMigrateEmbedded(t *testing.T) {
+	files, err := loadMigrations(migrations.FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("fresh database", func(t *testing.T) {
+		db := openTestDB(t)
+		if err := MigrateUp(db, migrations.FS); err != nil {
+			t.Fatal(err)
+		}
+		if got := appliedVersions(t, db); len(got) != len(files) {
+			t.Fatalf("applied %d migrations, want %d", len(got), len(files))
+		}
+		for _, file := range files {
+			for _, column := range file.SkipIfColumns {
+				if ok, err := columnsExist(db, []string{column}); err != nil || !ok {
+					t.Errorf("%s: column %s is missing (%v)", file.Version, column, err)
+				}
+			}
+		}
+
+		reverted, err := MigrateDown(db, migrations.FS, len(files))
+		if err != nil {
+			t.Fatalf("MigrateDown() = %v, %v", reverted, err)
+		}
+		if got := appliedVersions(t, db); len(got) != 0 {
+			t.Fatalf("still applied after full rollback: %v", got)
+		}
+		if err := MigrateUp(db, migrations.FS); err != nil {
+			t.Fatalf("MigrateUp() after rollback: %v", err)
+		}
+	})
+
+	t.Run("database migrated before column migrations were versioned", func(t *testing.T) {
+		db := openTestDB(t)
+		if err := MigrateUp(db, migrations.FS); err != nil {
+			t.Fatal(err)
+		}
+		// Older releases added these columns at startup without recording a migration
+		var pending []string
+		for _, file := range files {
+			if len(file.SkipIfColumns) > 0 {
+				pending = append(pending, file.Version)
+			}
+		}
+		if len(pending) == 0 {
+			t.Fatal("no migration uses skip-if-columns")
+		}
+		if err := db.Where("version IN ?", pending).Delete(&model.SchemaMigration{}).Error; err != nil {
+			t.Fatal(err)
+		}
+
+		if err := MigrateUp(db, migrations.FS); err != nil {
+			t.Fatal(err)
+		}
+		if got := appliedVersions(t, db); len(got) != len(files) {
+			t.Fatalf("applied %d migrations, want %d", len(got), len(files))
+		}
+	})
+}
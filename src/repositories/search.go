@@ -0,0 +1,388 @@
+package repositories
+
+import (
+	"blog_api/migrations"
+	"blog_api/src/model"
+	"fmt"
+	"html"
+	"io/fs"
+	"log"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+const (
+	highlightOpen  = "<mark>"
+	highlightClose = "</mark>"
+	// FTS5 的 highlight()/snippet() 先用控制字符标出命中位置，转义 HTML 后再替换为 <mark>
+	matchOpen     = "\x02"
+	matchClose    = "\x03"
+	snippetTokens = 32
+	snippetRunes  = 120
+	// trigram 分词器无法用 MATCH 匹配少于 3 个字符的查询词
+	minMatchRunes = 3
+)
+
+// searchIndex describes an FTS5 index kept in sync with its content table by triggers.
+type searchIndex struct {
+	Table    string
+	Triggers []string
+}
+
+var searchIndexes = []searchIndex{
+	{
+		Table: "friend_rss_post_fts",
+		Triggers: []string{
+			"trg_friend_rss_post_fts_insert",
+			"trg_friend_rss_post_fts_delete",
+			"trg_friend_rss_post_fts_update",
+		},
+	},
+	{
+		Table: "moments_fts",
+		Triggers: []string{
+			"trg_moments_fts_insert",
+			"trg_moments_fts_delete",
+			"trg_moments_fts_update",
+		},
+	},
+}
+
+// fullTextSearchEnabled reports whether the FTS5 indexes are available in this build.
+var fullTextSearchEnabled bool
+
+// FullTextSearchEnabled reports whether search queries are served by the FTS5 indexes.
+func FullTextSearchEnabled() bool {
+	return fullTextSearchEnabled
+}
+
+// setupFullTextSearch creates the FTS5 indexes when the SQLite driver supports them.
+// Without FTS5 the sync triggers are dropped so writes keep working, and search falls back to LIKE.
+// An index whose triggers were missing is rebuilt from its content table.
+func setupFullTextSearch(db *gorm.DB) error {
+	var supported int
+	if err := db.Raw("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&supported).Error; err != nil {
+		return fmt.Errorf("could not check fts5 support: %w", err)
+	}
+
+	if supported == 0 {
+		for _, index := range searchIndexes {
+			for _, trigger := range index.Triggers {
+				if err := db.Exec("DROP TRIGGER IF EXISTS " + trigger).Error; err != nil {
+					return fmt.Errorf("could not drop trigger %s: %w", trigger, err)
+				}
+			}
+		}
+		fullTextSearchEnabled = false
+		log.Println("当前 SQLite 未启用 FTS5（需使用 -tags sqlite_fts5 构建），搜索将退化为 LIKE 匹配")
+		return nil
+	}
+
+	stale := map[string]bool{}
+	for _, index := range searchIndexes {
+		for _, trigger := range index.Triggers {
+			var count int64
+			if err := db.Raw("SELECT COUNT(*) FROM sqlite_master WHERE type = 'trigger' AND name = ?", trigger).Scan(&count).Error; err != nil {
+				return fmt.Errorf("could not check trigger %s: %w", trigger, err)
+			}
+			if count == 0 {
+				stale[index.Table] = true
+			}
+		}
+	}
+
+	files, err := fs.Glob(migrations.FS, "fts5/*.sql")
+	if err != nil {
+		return fmt.Errorf("could not find fts5 migration files: %w", err)
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		log.Printf("运行迁移: %s\n", file)
+		content, err := fs.ReadFile(migrations.FS, file)
+		if err != nil {
+			return fmt.Errorf("could not read migration file %s: %w", file, err)
+		}
+		if err := db.Exec(string(content)).Error; err != nil {
+			return fmt.Errorf("could not execute migration statement in file %s: %w", file, err)
+		}
+	}
+
+	for _, index := range searchIndexes {
+		if !stale[index.Table] {
+			continue
+		}
+		log.Printf("重建全文索引: %s", index.Table)
+		stmt := fmt.Sprintf("INSERT INTO %s (%s) VALUES ('rebuild')", index.Table, index.Table)
+		if err := db.Exec(stmt).Error; err != nil {
+			return fmt.Errorf("could not rebuild %s: %w", index.Table, err)
+		}
+	}
+
+	fullTextSearchEnabled = true
+	return nil
+}
+
+// SearchRssPosts searches post titles, descriptions and authors, best matches first.
+func SearchRssPosts(db *gorm.DB, query *model.SearchQuery) ([]model.PostSearchResult, int, error) {
+	terms := searchTerms(query.Q)
+	results := []model.PostSearchResult{}
+	if len(terms) == 0 {
+		return results, 0, nil
+	}
+	offset := (query.Page - 1) * query.PageSize
+
+	if canMatch(terms) {
+		match := matchExpression(terms)
+		var total int64
+		if err := db.Raw("SELECT COUNT(*) FROM friend_rss_post_fts WHERE friend_rss_post_fts MATCH ?", match).Scan(&total).Error; err != nil {
+			return nil, 0, fmt.Errorf("could not count posts: %w", err)
+		}
+
+		sql := `
+			SELECT p.id, p.rss_id, p.title, p.link, p.author, p.time, r.name AS feed_name,
+				highlight(friend_rss_post_fts, 0, ?, ?) AS title_highlight,
+				snippet(friend_rss_post_fts, 1, ?, ?, '…', ?) AS snippet
+			FROM friend_rss_post_fts
+			JOIN friend_rss_post p ON p.id = friend_rss_post_fts.rowid
+			LEFT JOIN friend_rss r ON r.id = p.rss_id
+			WHERE friend_rss_post_fts MATCH ?
+			ORDER BY bm25(friend_rss_post_fts, 10.0, 1.0, 5.0), p.time DESC
+			LIMIT ? OFFSET ?
+		`
+		if err := db.Raw(sql, matchOpen, matchClose, matchOpen, matchClose, snippetTokens, match, query.PageSize, offset).Scan(&results).Error; err != nil {
+			return nil, 0, fmt.Errorf("could not search posts: %w", err)
+		}
+		for i := range results {
+			results[i].TitleHighlight = escapeHighlight(results[i].TitleHighlight)
+			results[i].Snippet = escapeHighlight(results[i].Snippet)
+		}
+		return results, int(total), nil
+	}
+
+	// LIKE 回退：未启用 FTS5 或查询词过短
+	base := db.Table("friend_rss_post AS p")
+	for _, term := range terms {
+		pattern := likePattern(term)
+		base = base.Where("(p.title LIKE ? ESCAPE '\\' OR p.description LIKE ? ESCAPE '\\' OR p.author LIKE ? ESCAPE '\\')", pattern, pattern, pattern)
+	}
+
+	var total int64
+	if err := base.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("could not count posts: %w", err)
+	}
+
+	var rows []struct {
+		model.PostSearchResult
+		Description string
+	}
+	if err := base.Select("p.id, p.rss_id, p.title, p.link, p.author, p.time, p.description, r.name AS feed_name").
+		Joins("LEFT JOIN friend_rss r ON r.id = p.rss_id").
+		Order("p.time DESC").
+		Offset(offset).
+		Limit(query.PageSize).
+		Scan(&rows).Error; err != nil {
+		return nil, 0, fmt.Errorf("could not search posts: %w", err)
+	}
+	for _, row := range rows {
+		result := row.PostSearchResult
+		result.TitleHighlight = highlightText(result.Title, terms)
+		result.Snippet = snippetText(row.Description, terms)
+		results = append(results, result)
+	}
+	return results, int(total), nil
+}
+
+// SearchMoments searches moment content, best matches first. An empty status searches all moments.
+func SearchMoments(db *gorm.DB, query *model.SearchQuery, status string) ([]model.MomentSearchResult, int, error) {
+	terms := searchTerms(query.Q)
+	results := []model.MomentSearchResult{}
+	if len(terms) == 0 {
+		return results, 0, nil
+	}
+	offset := (query.Page - 1) * query.PageSize
+
+	if canMatch(terms) {
+		match := matchExpression(terms)
+		base := db.Table("moments_fts").
+			Joins("JOIN moments m ON m.id = moments_fts.rowid").
+			Where("moments_fts MATCH ?", match)
+		if status != "" {
+			base = base.Where("m.status = ?", status)
+		}
+
+		var total int64
+		if err := base.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+			return nil, 0, fmt.Errorf("could not count moments: %w", err)
+		}
+
+		if err := base.Select("m.id, m.content, m.status, m.message_link, m.created_at, m.updated_at, snippet(moments_fts, 0, ?, ?, '…', ?) AS snippet", matchOpen, matchClose, snippetTokens).
+			Order("bm25(moments_fts), m.created_at DESC").
+			Offset(offset).
+			Limit(query.PageSize).
+			Scan(&results).Error; err != nil {
+			return nil, 0, fmt.Errorf("could not search moments: %w", err)
+		}
+		for i := range results {
+			results[i].Snippet = escapeHighlight(results[i].Snippet)
+		}
+		return results, int(total), nil
+	}
+
+	// LIKE 回退：未启用 FTS5 或查询词过短
+	base := db.Table("moments AS m")
+	for _, term := range terms {
+		base = base.Where("m.content LIKE ? ESCAPE '\\'", likePattern(term))
+	}
+	if status != "" {
+		base = base.Where("m.status = ?", status)
+	}
+
+	var total int64
+	if err := base.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("could not count moments: %w", err)
+	}
+
+	if err := base.Select("m.id, m.content, m.status, m.message_link, m.created_at, m.updated_at").
+		Order("m.created_at DESC").
+		Offset(offset).
+		Limit(query.PageSize).
+		Scan(&results).Error; err != nil {
+		return nil, 0, fmt.Errorf("could not search moments: %w", err)
+	}
+	for i := range results {
+		results[i].Snippet = snippetText(results[i].Content, terms)
+	}
+	return results, int(total), nil
+}
+
+// searchTerms splits the query on whitespace, keeping at most 8 terms.
+func searchTerms(q string) []string {
+	terms := strings.Fields(q)
+	if len(terms) > 8 {
+		terms = terms[:8]
+	}
+	return terms
+}
+
+// canMatch reports whether every term can be answered by the trigram index.
+func canMatch(terms []string) bool {
+	if !fullTextSearchEnabled {
+		return false
+	}
+	for _, term := range terms {
+		if utf8.RuneCountInString(term) < minMatchRunes {
+			return false
+		}
+	}
+	return true
+}
+
+// matchExpression quotes each term as an FTS5 phrase so user input is never parsed as query syntax.
+func matchExpression(terms []string) string {
+	phrases := make([]string, len(terms))
+	for i, term := range terms {
+		phrases[i] = `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+	}
+	return strings.Join(phrases, " AND ")
+}
+
+func likePattern(term string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return "%" + replacer.Replace(term) + "%"
+}
+
+// highlightText HTML-escapes text and wraps every case-insensitive occurrence of the terms in highlight markers.
+func highlightText(text string, terms []string) string {
+	lower := foldCase(text)
+	marked := make([]bool, len(text))
+	for _, term := range terms {
+		needle := foldCase(term)
+		if needle == "" {
+			continue
+		}
+		for start := 0; ; {
+			idx := strings.Index(lower[start:], needle)
+			if idx < 0 {
+				break
+			}
+			for i := start + idx; i < start+idx+len(needle); i++ {
+				marked[i] = true
+			}
+			start += idx + len(needle)
+		}
+	}
+
+	var b strings.Builder
+	segment := 0
+	for i := 0; i <= len(text); i++ {
+		if i < len(text) && (i == 0 || marked[i] == marked[i-1]) {
+			continue
+		}
+		if i > segment {
+			if marked[segment] {
+				b.WriteString(highlightOpen + html.EscapeString(text[segment:i]) + highlightClose)
+			} else {
+				b.WriteString(html.EscapeString(text[segment:i]))
+			}
+		}
+		segment = i
+	}
+	return b.String()
+}
+
+// escapeHighlight HTML-escapes FTS5 output and turns its match markers into highlight markers.
+func escapeHighlight(text string) string {
+	return highlightReplacer.Replace(html.EscapeString(text))
+}
+
+var highlightReplacer = strings.NewReplacer(matchOpen, highlightOpen, matchClose, highlightClose)
+
+// foldCase lowercases text when that keeps byte offsets intact, so matches map back onto the original.
+func foldCase(text string) string {
+	lower := strings.ToLower(text)
+	if len(lower) != len(text) {
+		return text
+	}
+	return lower
+}
+
+// snippetText cuts a window of text around the first matching term and highlights it.
+func snippetText(text string, terms []string) string {
+	runes := []rune(text)
+	if len(runes) <= snippetRunes {
+		return highlightText(text, terms)
+	}
+
+	lower := foldCase(text)
+	first := -1
+	for _, term := range terms {
+		if idx := strings.Index(lower, foldCase(term)); idx >= 0 && (first < 0 || idx < first) {
+			first = idx
+		}
+	}
+
+	start := 0
+	if first > 0 {
+		start = utf8.RuneCountInString(lower[:first]) - snippetRunes/4
+		if start < 0 {
+			start = 0
+		}
+	}
+	end := start + snippetRunes
+	if end > len(runes) {
+		end = len(runes)
+		start = end - snippetRunes
+	}
+
+	snippet := highlightText(string(runes[start:end]), terms)
+	if start > 0 {
+		snippet = "…" + snippet
+	}
+	if end < len(runes) {
+		snippet += "…"
+	}
+	return snippet
+}
@@ -0,0 +1,23 @@
+package repositories
+
+import "testing"
+
+func TestHighlightEscapesHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"plain", highlightText("Go tips", []string{"go"}), "<mark>Go</mark> tips"},
+		{"tags in text", highlightText(`<img src=x onerror=alert(1)> go`, []string{"go"}), "&lt;img src=x onerror=alert(1)&gt; <mark>go</mark>"},
+		{"tags in match", highlightText(`a <b>bold</b>`, []string{"<b>"}), "a <mark>&lt;b&gt;</mark>bold&lt;/b&gt;"},
+		{"adjacent terms", highlightText("foobar", []string{"foo", "bar"}), "<mark>foobar</mark>"},
+		{"multibyte", highlightText("搜索<测试>", []string{"测试"}), "搜索&lt;<mark>测试</mark>&gt;"},
+		{"fts output", escapeHighlight("<script>\x02go\x03</script>"), "&lt;script&gt;<mark>go</mark>&lt;/script&gt;"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"blog_api/src/model"
+	friendsRepositories "blog_api/src/repositories/friend"
 	"fmt"
 
 	"gorm.io/gorm"
@@ -58,5 +59,26 @@ func GetSystemStats(db *gorm.DB) (model.StatusData, error) {
 	}
 	stats.RssPostCountMonthly = monthlyData
 
+	// Get friend link uptime over the recent check window
+	uptime, lowest, err := friendsRepositories.GetFriendLinkUptimeSummary(db, 5)
+	if err != nil {
+		return stats, err
+	}
+	stats.FriendLinkUptime = uptime
+	stats.LowestUptimeLinks = lowest
+
+	// Get friend link backlink distribution
+	backlinkQuery := `
+		SELECT
+			COALESCE(SUM(CASE WHEN backlink_checked_at > 0 AND has_backlink = 1 THEN 1 ELSE 0 END), 0) AS present,
+			COALESCE(SUM(CASE WHEN backlink_checked_at > 0 AND has_backlink = 0 THEN 1 ELSE 0 END), 0) AS missing,
+			COALESCE(SUM(CASE WHEN has_backlink = 0 AND backlink_last_seen_at > 0 THEN 1 ELSE 0 END), 0) AS lost,
+			COALESCE(SUM(CASE WHEN backlink_checked_at = 0 THEN 1 ELSE 0 END), 0) AS unchecked
+		FROM friend_link
+	`
+	if err := db.Raw(backlinkQuery).Scan(&stats.FriendLinkBacklink).Error; err != nil {
+		return stats, fmt.Errorf("could not query friend link backlink counts: %w", err)
+	}
+
 	return stats, nil
 }
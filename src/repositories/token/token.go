@@ -0,0 +1,68 @@
+package tokenRepositories
+
+import (
+	"blog_api/src/model"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PutToken inserts a token or replaces the one stored under the same kind and key.
+func PutToken(db *gorm.DB, token model.Token) error {
+	err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "kind"}, {Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value", "expires_at", "created_at"}),
+	}).Create(&token).Error
+	if err != nil {
+		return fmt.Errorf("could not save %s token: %w", token.Kind, err)
+	}
+	return nil
+}
+
+// GetToken returns the value of a token that has not expired at now.
+func GetToken(db *gorm.DB, kind, key string, now int64) (string, bool, error) {
+	var tokens []model.Token
+	if err := db.Where("kind = ? AND key = ? AND expires_at > ?", kind, key, now).Limit(1).Find(&tokens).Error; err != nil {
+		return "", false, fmt.Errorf("could not query %s token: %w", kind, err)
+	}
+	if len(tokens) == 0 {
+		return "", false, nil
+	}
+	return tokens[0].Value, true, nil
+}
+
+// TakeToken deletes a token and returns its value if it had not expired at now.
+// The delete and read are a single statement, so concurrent callers cannot both take the same token.
+func TakeToken(db *gorm.DB, kind, key string, now int64) (string, bool, error) {
+	var rows []struct {
+		Value string
+		Valid bool
+	}
+	err := db.Raw("DELETE FROM tokens WHERE kind = ? AND key = ? RETURNING value, expires_at > ? AS valid", kind, key, now).
+		Scan(&rows).Error
+	if err != nil {
+		return "", false, fmt.Errorf("could not take %s token: %w", kind, err)
+	}
+	if len(rows) == 0 || !rows[0].Valid {
+		return "", false, nil
+	}
+	return rows[0].Value, true, nil
+}
+
+// DeleteToken removes a token.
+func DeleteToken(db *gorm.DB, kind, key string) error {
+	if err := db.Where("kind = ? AND key = ?", kind, key).Delete(&model.Token{}).Error; err != nil {
+		return fmt.Errorf("could not delete %s token: %w", kind, err)
+	}
+	return nil
+}
+
+// DeleteExpiredTokens removes every token that expired at or before now.
+func DeleteExpiredTokens(db *gorm.DB, now int64) (int64, error) {
+	res := db.Where("expires_at <= ?", now).Delete(&model.Token{})
+	if res.Error != nil {
+		return 0, fmt.Errorf("could not delete expired tokens: %w", res.Error)
+	}
+	return res.RowsAffected, nil
+}
@@ -0,0 +1,520 @@
+package transferRepositories
+
+import (
+	"blog_api/src/model"
+	friendsRepositories "blog_api/src/repositories/friend"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// errDryRun rolls back the import transaction of a dry run.
+var errDryRun = errors.New("dry run")
+
+// Export reads all friend links, feeds, posts, moments (with media and reactions) and images.
+func Export(db *gorm.DB) (*model.ExportBundle, error) {
+	bundle := &model.ExportBundle{
+		Version:     model.ExportVersion,
+		ExportedAt:  time.Now().Unix(),
+		FriendLinks: []model.ExportFriendLink{},
+		Moments:     []model.ExportMoment{},
+		Images:      []model.ExportImage{},
+	}
+
+	var links []model.FriendWebsite
+	if err := db.Order("id").Find(&links).Error; err != nil {
+		return nil, fmt.Errorf("could not read friend links: %w", err)
+	}
+	var feeds []model.FriendRss
+	if err := db.Order("id").Find(&feeds).Error; err != nil {
+		return nil, fmt.Errorf("could not read feeds: %w", err)
+	}
+	var posts []model.RssPost
+	if err := db.Order("id").Find(&posts).Error; err != nil {
+		return nil, fmt.Errorf("could not read posts: %w", err)
+	}
+
+	postsByFeed := map[int][]model.ExportPost{}
+	for _, post := range posts {
+		postsByFeed[post.RssID] = append(postsByFeed[post.RssID], model.ExportPost{
+			Title:       post.Title,
+			Link:        post.Link,
+			Description: post.Description,
+			Author:      post.Author,
+			Time:        post.Time,
+			GUID:        post.GUID,
+		})
+	}
+	feedsByLink := map[int][]model.ExportFeed{}
+	for _, feed := range feeds {
+		feedsByLink[feed.FriendLinkID] = append(feedsByLink[feed.FriendLinkID], model.ExportFeed{
+			Name:   feed.Name,
+			RssURL: feed.RssURL,
+			Status: feed.Status,
+			Posts:  postsByFeed[feed.ID],
+		})
+	}
+	for _, link := range links {
+		bundle.FriendLinks = append(bundle.FriendLinks, model.ExportFriendLink{
+			Name:           link.Name,
+			Link:           link.Link,
+			Avatar:         link.Avatar,
+			Description:    link.Info,
+			Email:          link.Email,
+			Status:         link.Status,
+			EnableRss:      link.EnableRss,
+			FriendsPageURL: link.FriendsPageURL,
+			Feeds:          feedsByLink[link.ID],
+		})
+	}
+
+	var moments []model.Moment
+	if err := db.Order("id").Find(&moments).Error; err != nil {
+		return nil, fmt.Errorf("could not read moments: %w", err)
+	}
+	var media []model.MomentMedia
+	if err := db.Order("id").Find(&media).Error; err != nil {
+		return nil, fmt.Errorf("could not read moment media: %w", err)
+	}
+	var reactions []struct {
+		MomentID    int
+		Fingerprint string
+		Reaction    string
+		CreatedAt   int64
+	}
+	err := db.Table("moment_reactions AS r").
+		Select("r.moment_id, f.fingerprint, r.reaction, r.created_at").
+		Joins("JOIN fingerprints f ON f.id = r.fingerprint_id").
+		Order("r.id").
+		Scan(&reactions).Error
+	if err != nil {
+		return nil, fmt.Errorf("could not read moment reactions: %w", err)
+	}
+
+	mediaByMoment := map[int][]model.ExportMomentMedia{}
+	for _, item := range media {
+		mediaByMoment[item.MomentID] = append(mediaByMoment[item.MomentID], model.ExportMomentMedia{
+			Name:      item.Name,
+			MediaURL:  item.MediaURL,
+			MediaType: item.MediaType,
+			IsLocal:   item.IsLocal,
+			IsDeleted: item.IsDeleted,
+		})
+	}
+	reactionsByMoment := map[int][]model.ExportReaction{}
+	for _, reaction := range reactions {
+		reactionsByMoment[reaction.MomentID] = append(reactionsByMoment[reaction.MomentID], model.ExportReaction{
+			Fingerprint: reaction.Fingerprint,
+			Reaction:    reaction.Reaction,
+			CreatedAt:   reaction.CreatedAt,
+		})
+	}
+	for _, moment := range moments {
+		bundle.Moments = append(bundle.Moments, model.ExportMoment{
+			Content:     moment.Content,
+			Status:      moment.Status,
+			GuildID:     moment.GuildID,
+			ChannelID:   moment.ChannelID,
+			MessageID:   moment.MessageID,
+			MessageLink: moment.MessageLink,
+			CreatedAt:   moment.CreatedAt,
+			Media:       mediaByMoment[moment.ID],
+			Reactions:   reactionsByMoment[moment.ID],
+		})
+	}
+
+	var images []model.Image
+	if err := db.Order("id").Find(&images).Error; err != nil {
+		return nil, fmt.Errorf("could not read images: %w", err)
+	}
+	for _, image := range images {
+		bundle.Images = append(bundle.Images, model.ExportImage{
+			Name:      image.Name,
+			URL:       image.URL,
+			LocalPath: image.LocalPath,
+			IsLocal:   image.IsLocal,
+			IsOss:     image.IsOss,
+			Status:    image.Status,
+		})
+	}
+	return bundle, nil
+}
+
+// Import merges the bundle into the database in a single transaction and fills report.
+// Records are matched by natural key: friend links by website_url, feeds by rss_url,
+// posts by link, moments by (channel_id, message_id) or (content, created_at), images by url.
+// A matched record whose content differs is a conflict: it is kept unless opts.Overwrite is set.
+// With opts.DryRun the transaction is rolled back after the report is built.
+func Import(db *gorm.DB, bundle *model.ExportBundle, opts model.ImportOptions, report *model.ImportReport) error {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		im := &importer{tx: tx, opts: opts, report: report, fingerprints: map[string]int{}}
+		for _, link := range bundle.FriendLinks {
+			if err := im.friendLink(link); err != nil {
+				return err
+			}
+		}
+		for _, moment := range bundle.Moments {
+			if err := im.moment(moment); err != nil {
+				return err
+			}
+		}
+		for _, image := range bundle.Images {
+			if err := im.image(image); err != nil {
+				return err
+			}
+		}
+		if opts.DryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if errors.Is(err, errDryRun) {
+		return nil
+	}
+	return err
+}
+
+type importer struct {
+	tx           *gorm.DB
+	opts         model.ImportOptions
+	report       *model.ImportReport
+	fingerprints map[string]int
+}
+
+// fieldDiff collects the names of differing fields.
+type fieldDiff struct {
+	fields  []string
+	updates map[string]interface{}
+}
+
+func (d *fieldDiff) compare(field string, local, imported interface{}) {
+	if local != imported {
+		d.fields = append(d.fields, field)
+		if d.updates == nil {
+			d.updates = map[string]interface{}{}
+		}
+		d.updates[field] = imported
+	}
+}
+
+// resolve records the outcome for a matched record and applies the update when overwriting.
+func (im *importer) resolve(kind, key string, record interface{}, diff fieldDiff) error {
+	if len(diff.fields) == 0 {
+		im.report.Count(kind).Unchanged++
+		return nil
+	}
+	im.report.Conflict(kind, key, diff.fields, im.opts.Overwrite)
+	if !im.opts.Overwrite {
+		return nil
+	}
+	if err := im.tx.Model(record).Updates(diff.updates).Error; err != nil {
+		return fmt.Errorf("could not update %s %s: %w", kind, key, err)
+	}
+	return nil
+}
+
+// failed records a record that could not be imported; the import carries on.
+func (im *importer) failed(kind, key string, err error) {
+	im.report.Count(kind).Skipped++
+	im.report.Errors = append(im.report.Errors, fmt.Sprintf("%s %s: %v", kind, key, err))
+}
+
+func (im *importer) friendLink(item model.ExportFriendLink) error {
+	if item.Link == "" {
+		im.failed("friend_link", item.Name, errors.New("website_url is empty"))
+		return nil
+	}
+
+	var link model.FriendWebsite
+	err := im.tx.Where("website_url = ?", item.Link).First(&link).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		link = model.FriendWebsite{
+			Name:           item.Name,
+			Link:           item.Link,
+			Avatar:         item.Avatar,
+			Info:           item.Description,
+			Email:          item.Email,
+			Status:         defaultString(item.Status, "survival"),
+			EnableRss:      item.EnableRss,
+			FriendsPageURL: item.FriendsPageURL,
+		}
+		if err := im.tx.Create(&link).Error; err != nil {
+			im.failed("friend_link", item.Link, err)
+			return nil
+		}
+		im.report.Count("friend_link").Added++
+	case err != nil:
+		return fmt.Errorf("could not look up friend link %s: %w", item.Link, err)
+	default:
+		var diff fieldDiff
+		diff.compare("website_name", link.Name, item.Name)
+		diff.compare("website_icon_url", link.Avatar, item.Avatar)
+		diff.compare("description", link.Info, item.Description)
+		diff.compare("email", link.Email, item.Email)
+		diff.compare("enable_rss", link.EnableRss, item.EnableRss)
+		diff.compare("friends_page_url", link.FriendsPageURL, item.FriendsPageURL)
+		if err := im.resolve("friend_link", item.Link, &link, diff); err != nil {
+			return err
+		}
+	}
+
+	for _, feed := range item.Feeds {
+		if err := im.feed(link.ID, feed); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (im *importer) feed(friendLinkID int, item model.ExportFeed) error {
+	if item.RssURL == "" {
+		im.failed("feed", item.Name, errors.New("rss_url is empty"))
+		return nil
+	}
+
+	var feed model.FriendRss
+	err := im.tx.Where("rss_url = ?", item.RssURL).First(&feed).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		feed = model.FriendRss{
+			FriendLinkID: friendLinkID,
+			Name:         item.Name,
+			RssURL:       item.RssURL,
+			Status:       defaultString(item.Status, "survival"),
+		}
+		if err := im.tx.Create(&feed).Error; err != nil {
+			im.failed("feed", item.RssURL, err)
+			return nil
+		}
+		im.report.Count("feed").Added++
+	case err != nil:
+		return fmt.Errorf("could not look up feed %s: %w", item.RssURL, err)
+	default:
+		var diff fieldDiff
+		diff.compare("name", feed.Name, item.Name)
+		if err := im.resolve("feed", item.RssURL, &feed, diff); err != nil {
+			return err
+		}
+	}
+
+	for _, post := range item.Posts {
+		if err := im.post(feed.ID, post); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (im *importer) post(rssID int, item model.ExportPost) error {
+	if item.Link == "" {
+		im.failed("post", item.Title, errors.New("link is empty"))
+		return nil
+	}
+
+	var post model.RssPost
+	query := im.tx.Where("link = ?", item.Link)
+	if item.GUID != "" {
+		query = im.tx.Where("link = ? OR (rss_id = ? AND guid = ?)", item.Link, rssID, item.GUID)
+	}
+	err := query.Order("id").First(&post).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		post = model.RssPost{
+			RssID:       rssID,
+			Title:       item.Title,
+			Link:        item.Link,
+			Description: item.Description,
+			Author:      item.Author,
+			Time:        item.Time,
+			GUID:        item.GUID,
+			UpdatedAt:   time.Now().Unix(),
+			LinkKey:     friendsRepositories.NormalizePostLink(item.Link),
+		}
+		if err := im.tx.Create(&post).Error; err != nil {
+			im.failed("post", item.Link, err)
+			return nil
+		}
+		im.report.Count("post").Added++
+		return nil
+	case err != nil:
+		return fmt.Errorf("could not look up post %s: %w", item.Link, err)
+	}
+
+	var diff fieldDiff
+	diff.compare("title", post.Title, item.Title)
+	diff.compare("description", post.Description, item.Description)
+	diff.compare("author", post.Author, item.Author)
+	diff.compare("time", post.Time, item.Time)
+	return im.resolve("post", item.Link, &post, diff)
+}
+
+func (im *importer) moment(item model.ExportMoment) error {
+	var key string
+	query := im.tx.Model(&model.Moment{})
+	if item.ChannelID > 0 && item.MessageID > 0 {
+		key = fmt.Sprintf("%d:%d", item.ChannelID, item.MessageID)
+		query = query.Where("channel_id = ? AND message_id = ?", item.ChannelID, item.MessageID)
+	} else {
+		key = fmt.Sprintf("created_at=%d", item.CreatedAt)
+		query = query.Where("content = ? AND created_at = ?", item.Content, item.CreatedAt)
+	}
+
+	var moment model.Moment
+	err := query.First(&moment).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		moment = model.Moment{
+			Content:     item.Content,
+			Status:      defaultString(item.Status, "visible"),
+			GuildID:     item.GuildID,
+			ChannelID:   item.ChannelID,
+			MessageID:   item.MessageID,
+			MessageLink: item.MessageLink,
+			CreatedAt:   item.CreatedAt,
+			UpdatedAt:   time.Now().Unix(),
+		}
+		if err := im.tx.Create(&moment).Error; err != nil {
+			im.failed("moment", key, err)
+			return nil
+		}
+		im.report.Count("moment").Added++
+	case err != nil:
+		return fmt.Errorf("could not look up moment %s: %w", key, err)
+	default:
+		var diff fieldDiff
+		diff.compare("content", moment.Content, item.Content)
+		diff.compare("status", moment.Status, item.Status)
+		diff.compare("message_link", moment.MessageLink, item.MessageLink)
+		if err := im.resolve("moment", key, &moment, diff); err != nil {
+			return err
+		}
+	}
+
+	for _, media := range item.Media {
+		if err := im.momentMedia(moment.ID, key, media); err != nil {
+			return err
+		}
+	}
+	for _, reaction := range item.Reactions {
+		if err := im.reaction(moment.ID, key, reaction); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (im *importer) momentMedia(momentID int, momentKey string, item model.ExportMomentMedia) error {
+	key := momentKey + " " + item.MediaURL
+	var count int64
+	if err := im.tx.Model(&model.MomentMedia{}).Where("moment_id = ? AND media_url = ?", momentID, item.MediaURL).Count(&count).Error; err != nil {
+		return fmt.Errorf("could not look up media %s: %w", key, err)
+	}
+	if count > 0 {
+		im.report.Count("moment_media").Unchanged++
+		im.report.Media[item.MediaURL] = true
+		return nil
+	}
+
+	media := model.MomentMedia{
+		MomentID:  momentID,
+		Name:      item.Name,
+		MediaURL:  item.MediaURL,
+		MediaType: defaultString(item.MediaType, "image"),
+		IsLocal:   item.IsLocal,
+		IsDeleted: item.IsDeleted,
+	}
+	if err := im.tx.Create(&media).Error; err != nil {
+		im.failed("moment_media", key, err)
+		return nil
+	}
+	im.report.Count("moment_media").Added++
+	im.report.Media[item.MediaURL] = true
+	return nil
+}
+
+func (im *importer) reaction(momentID int, momentKey string, item model.ExportReaction) error {
+	key := fmt.Sprintf("%s %s %s", momentKey, item.Fingerprint, item.Reaction)
+	if item.Fingerprint == "" {
+		im.failed("reaction", key, errors.New("fingerprint is empty"))
+		return nil
+	}
+
+	fingerprintID, ok := im.fingerprints[item.Fingerprint]
+	if !ok {
+		var fingerprint model.Fingerprint
+		err := im.tx.Where("fingerprint = ?", item.Fingerprint).First(&fingerprint).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			fingerprint = model.Fingerprint{Fingerprint: item.Fingerprint, PermissionsLevel: "normal", CreatedAt: item.CreatedAt}
+			err = im.tx.Create(&fingerprint).Error
+		}
+		if err != nil {
+			return fmt.Errorf("could not resolve fingerprint for reaction %s: %w", key, err)
+		}
+		fingerprintID = fingerprint.ID
+		im.fingerprints[item.Fingerprint] = fingerprintID
+	}
+
+	var count int64
+	err := im.tx.Model(&model.MomentReaction{}).
+		Where("moment_id = ? AND fingerprint_id = ? AND reaction = ?", momentID, fingerprintID, item.Reaction).
+		Count(&count).Error
+	if err != nil {
+		return fmt.Errorf("could not look up reaction %s: %w", key, err)
+	}
+	if count > 0 {
+		im.report.Count("reaction").Unchanged++
+		return nil
+	}
+
+	reaction := model.MomentReaction{MomentID: momentID, FingerprintID: fingerprintID, Reaction: item.Reaction, CreatedAt: item.CreatedAt}
+	if err := im.tx.Create(&reaction).Error; err != nil {
+		im.failed("reaction", key, err)
+		return nil
+	}
+	im.report.Count("reaction").Added++
+	return nil
+}
+
+func (im *importer) image(item model.ExportImage) error {
+	if item.URL == "" {
+		im.failed("image", item.Name, errors.New("url is empty"))
+		return nil
+	}
+
+	var image model.Image
+	err := im.tx.Where("url = ?", item.URL).First(&image).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		image = model.Image{
+			Name:      item.Name,
+			URL:       item.URL,
+			LocalPath: item.LocalPath,
+			IsLocal:   item.IsLocal,
+			IsOss:     item.IsOss,
+			Status:    defaultString(item.Status, "normal"),
+		}
+		if err := im.tx.Create(&image).Error; err != nil {
+			im.failed("image", item.URL, err)
+			return nil
+		}
+		im.report.Count("image").Added++
+		return nil
+	case err != nil:
+		return fmt.Errorf("could not look up image %s: %w", item.URL, err)
+	}
+
+	var diff fieldDiff
+	diff.compare("name", image.Name, item.Name)
+	diff.compare("is_oss", image.IsOss, item.IsOss)
+	return im.resolve("image", item.URL, &image, diff)
+}
+
+func defaultString(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
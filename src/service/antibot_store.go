@@ -3,22 +3,12 @@ package service
 import (
 	"crypto/rand"
 	"encoding/hex"
-	"sync"
+	"log"
 	"time"
 )
 
 const defaultAntiBotTTLSeconds = 300
 
-// AntiBotStore keeps short-lived anti-bot tokens in memory.
-type AntiBotStore struct {
-	mu     sync.Mutex
-	tokens map[string]int64
-}
-
-var antiBotStore = &AntiBotStore{
-	tokens: make(map[string]int64),
-}
-
 // IssueAntiBotToken creates a new anti-bot token.
 func IssueAntiBotToken() (string, int64, error) {
 	bytes := make([]byte, 32)
@@ -28,11 +18,9 @@ func IssueAntiBotToken() (string, int64, error) {
 	token := hex.EncodeToString(bytes)
 	expiresAt := time.Now().Add(defaultAntiBotTTLSeconds * time.Second).Unix()
 
-	antiBotStore.mu.Lock()
-	antiBotStore.tokens[token] = expiresAt
-	antiBotStore.cleanupLocked(time.Now().Unix())
-	antiBotStore.mu.Unlock()
-
+	if err := currentTokenStore().Put(tokenKindAntiBot, hashTokenKey(token), "", expiresAt); err != nil {
+		return "", 0, err
+	}
 	return token, expiresAt, nil
 }
 
@@ -43,25 +31,10 @@ func AntiBotTTLSeconds() int {
 
 // ValidateAntiBotToken validates a token and checks expiration.
 func ValidateAntiBotToken(token string) bool {
-	now := time.Now().Unix()
-	antiBotStore.mu.Lock()
-	defer antiBotStore.mu.Unlock()
-
-	expiresAt, ok := antiBotStore.tokens[token]
-	if !ok || expiresAt <= now {
-		if ok {
-			delete(antiBotStore.tokens, token)
-		}
+	_, ok, err := currentTokenStore().Get(tokenKindAntiBot, hashTokenKey(token))
+	if err != nil {
+		log.Printf("[token][ERR] 校验反机器人令牌失败: %v", err)
 		return false
 	}
-
-	return true
-}
-
-func (s *AntiBotStore) cleanupLocked(now int64) {
-	for token, expiresAt := range s.tokens {
-		if expiresAt <= now {
-			delete(s.tokens, token)
-		}
-	}
+	return ok
 }
@@ -0,0 +1,229 @@
+package backupService
+
+import (
+	"blog_api/migrations"
+	"blog_api/src/model"
+	"blog_api/src/repositories"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	backupPrefix = "blog_api-"
+	backupSuffix = ".db"
+	timeLayout   = "20060102-150405"
+)
+
+// ErrBackupNotFound 指定的备份文件不存在
+var ErrBackupNotFound = errors.New("backup not found")
+
+// backupNamePattern 备份文件名只允许字母、数字、点、下划线与短横线，防止路径穿越
+var backupNamePattern = regexp.MustCompile(`^[A-Za-z0-9._-]+\.db$`)
+
+// mu 串行化备份与恢复，避免恢复时同时写入备份
+var mu sync.Mutex
+
+// Create 使用 VACUUM INTO 生成一份一致的备份，并只保留最新的 keep 份
+func Create(db *gorm.DB, dir string, keep int) (model.BackupInfo, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	return create(db, dir, keep, "")
+}
+
+func create(db *gorm.DB, dir string, keep int, label string) (model.BackupInfo, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return model.BackupInfo{}, fmt.Errorf("创建备份目录失败: %w", err)
+	}
+
+	base := backupPrefix + time.Now().Format(timeLayout)
+	if label != "" {
+		base += "-" + label
+	}
+	name := base + backupSuffix
+	for i := 2; fileExists(filepath.Join(dir, name)); i++ {
+		name = fmt.Sprintf("%s-%d%s", base, i, backupSuffix)
+	}
+
+	// 先写入临时文件，完成后再重命名，避免列表中出现写了一半的备份
+	tmp := filepath.Join(dir, "."+name+".tmp")
+	os.Remove(tmp)
+	if err := repositories.VacuumInto(db, tmp); err != nil {
+		os.Remove(tmp)
+		return model.BackupInfo{}, err
+	}
+	path := filepath.Join(dir, name)
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return model.BackupInfo{}, fmt.Errorf("保存备份失败: %w", err)
+	}
+
+	info, err := stat(path)
+	if err != nil {
+		return model.BackupInfo{}, err
+	}
+	log.Printf("[Backup] 已创建数据库备份: %s (%d 字节)", info.Name, info.Size)
+
+	if err := rotate(dir, keep); err != nil {
+		log.Printf("[Backup] 清理旧备份失败: %v", err)
+	}
+	return info, nil
+}
+
+// rotate 删除超出保留数量的旧备份
+func rotate(dir string, keep int) error {
+	if keep <= 0 {
+		return nil
+	}
+	backups, err := List(dir)
+	if err != nil {
+		return err
+	}
+	for _, backup := range backups[min(keep, len(backups)):] {
+		if err := os.Remove(filepath.Join(dir, backup.Name)); err != nil {
+			return err
+		}
+		log.Printf("[Backup] 已删除旧备份: %s", backup.Name)
+	}
+	return nil
+}
+
+// List 返回备份目录中的备份，新的在前
+func List(dir string) ([]model.BackupInfo, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []model.BackupInfo{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取备份目录失败: %w", err)
+	}
+
+	type entryInfo struct {
+		info    model.BackupInfo
+		modTime time.Time
+	}
+	found := []entryInfo{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, backupPrefix) || !strings.HasSuffix(name, backupSuffix) {
+			continue
+		}
+		fileInfo, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		found = append(found, entryInfo{
+			info:    model.BackupInfo{Name: name, Size: fileInfo.Size(), CreatedAt: fileInfo.ModTime().Unix()},
+			modTime: fileInfo.ModTime(),
+		})
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].modTime.After(found[j].modTime) })
+
+	backups := make([]model.BackupInfo, 0, len(found))
+	for _, entry := range found {
+		backups = append(backups, entry.info)
+	}
+	return backups, nil
+}
+
+// Path 返回备份文件的路径，名称无效或文件不存在时返回 ErrBackupNotFound
+func Path(dir, name string) (string, error) {
+	if !backupNamePattern.MatchString(name) || !strings.HasPrefix(name, backupPrefix) {
+		return "", ErrBackupNotFound
+	}
+	path := filepath.Join(dir, name)
+	if !fileExists(path) {
+		return "", ErrBackupNotFound
+	}
+	return path, nil
+}
+
+// Delete 删除一个备份
+func Delete(dir, name string) error {
+	path, err := Path(dir, name)
+	if err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// Snapshot 生成一份临时的一致快照用于下载，调用方使用完毕后需调用 cleanup
+func Snapshot(db *gorm.DB, dir string) (path string, cleanup func(), err error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", nil, fmt.Errorf("创建备份目录失败: %w", err)
+	}
+	file, err := os.CreateTemp(dir, ".snapshot-*.db")
+	if err != nil {
+		return "", nil, fmt.Errorf("创建快照文件失败: %w", err)
+	}
+	path = file.Name()
+	file.Close()
+	// VACUUM INTO 要求目标文件不存在
+	os.Remove(path)
+
+	cleanup = func() { os.Remove(path) }
+	if err := repositories.VacuumInto(db, path); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	return path, cleanup, nil
+}
+
+// SnapshotName 下载快照时使用的文件名
+func SnapshotName() string {
+	return backupPrefix + time.Now().Format(timeLayout) + backupSuffix
+}
+
+// Restore 校验 src 后用其内容替换当前数据库
+// 恢复前会先备份当前数据库（文件名带 pre_restore），恢复后补齐缺少的迁移
+// 返回恢复前的备份
+func Restore(db *gorm.DB, dir string, keep int, src string) (model.BackupInfo, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if err := repositories.ValidateBackup(src, migrations.FS); err != nil {
+		return model.BackupInfo{}, err
+	}
+
+	// 恢复前的备份不计入轮换数量，避免挤掉刚要恢复的备份
+	before, err := create(db, dir, 0, "pre_restore")
+	if err != nil {
+		return model.BackupInfo{}, fmt.Errorf("恢复前备份当前数据库失败: %w", err)
+	}
+
+	log.Printf("[Backup] 正在从 %s 恢复数据库", filepath.Base(src))
+	if err := repositories.RestoreFrom(db, src); err != nil {
+		return before, err
+	}
+	if err := repositories.Migrate(db); err != nil {
+		return before, fmt.Errorf("数据库已恢复，但执行迁移失败: %w", err)
+	}
+	log.Printf("[Backup] 数据库恢复完成，恢复前的数据已备份为 %s", before.Name)
+	return before, nil
+}
+
+func stat(path string) (model.BackupInfo, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return model.BackupInfo{}, err
+	}
+	return model.BackupInfo{
+		Name:      filepath.Base(path),
+		Size:      info.Size(),
+		CreatedAt: info.ModTime().Unix(),
+	}, nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
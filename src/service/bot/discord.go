@@ -81,6 +81,11 @@ func StartDiscordListener(db *gorm.DB, cfg *model.Config) {
 }
 
 func (l *discordListener) onMessageCreate(s *discordgo.Session, m *discordgo.MessageCreate) {
+	if !beginHandling() {
+		return
+	}
+	defer endHandling()
+
 	if m == nil || m.Message == nil || m.Author == nil {
 		return
 	}
@@ -109,6 +114,11 @@ func (l *discordListener) onMessageCreate(s *discordgo.Session, m *discordgo.Mes
 }
 
 func (l *discordListener) onMessageDelete(s *discordgo.Session, e *discordgo.MessageDelete) {
+	if !beginHandling() {
+		return
+	}
+	defer endHandling()
+
 	if !l.syncDelete || e == nil {
 		return
 	}
@@ -129,6 +139,11 @@ func (l *discordListener) onMessageDelete(s *discordgo.Session, e *discordgo.Mes
 }
 
 func (l *discordListener) onMessageDeleteBulk(s *discordgo.Session, e *discordgo.MessageDeleteBulk) {
+	if !beginHandling() {
+		return
+	}
+	defer endHandling()
+
 	if !l.syncDelete || e == nil {
 		return
 	}
@@ -193,7 +208,11 @@ func (l *discordListener) downloadAttachment(att *discordgo.MessageAttachment, m
 		return nil, nil
 	}
 
-	resp, err := http.Get(att.URL)
+	req, err := http.NewRequestWithContext(downloadCtx, http.MethodGet, att.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
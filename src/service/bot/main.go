@@ -2,10 +2,24 @@ package bot
 
 import (
 	"blog_api/src/model"
+	"context"
+	"log"
+	"sync"
 
 	"gorm.io/gorm"
 )
 
+var (
+	lifecycleMu sync.Mutex
+	stopping    bool
+	handlers    sync.WaitGroup // 正在运行的监听循环与消息处理
+
+	// listenersCtx 监听循环的上下文，StopListeners 时取消
+	listenersCtx, stopListening = context.WithCancel(context.Background())
+	// downloadCtx 媒体下载的上下文，等待超时后取消以中止未完成的下载
+	downloadCtx, cancelDownloads = context.WithCancel(context.Background())
+)
+
 // StartListeners starts all bot listeners with a shared config.
 func StartListeners(db *gorm.DB, cfg *model.Config) {
 	if cfg == nil {
@@ -14,3 +28,47 @@ func StartListeners(db *gorm.DB, cfg *model.Config) {
 	StartTelegramListener(db, cfg)
 	StartDiscordListener(db, cfg)
 }
+
+// StopListeners stops receiving new messages and waits for the ones being handled.
+// When ctx expires first, pending downloads are aborted and ctx's error is returned.
+func StopListeners(ctx context.Context) error {
+	lifecycleMu.Lock()
+	stopping = true
+	lifecycleMu.Unlock()
+	stopListening()
+
+	if session := GetDiscordSession(); session != nil {
+		if err := session.Close(); err != nil {
+			log.Printf("[discord] close session failed: %v", err)
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		handlers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		cancelDownloads()
+		return ctx.Err()
+	}
+}
+
+// beginHandling registers a running handler; it returns false once the listeners are stopping.
+func beginHandling() bool {
+	lifecycleMu.Lock()
+	defer lifecycleMu.Unlock()
+	if stopping {
+		return false
+	}
+	handlers.Add(1)
+	return true
+}
+
+func endHandling() {
+	handlers.Done()
+}
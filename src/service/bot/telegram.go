@@ -79,6 +79,9 @@ func StartTelegramListener(db *gorm.DB, cfg *model.Config) {
 		}
 	}
 
+	if !beginHandling() {
+		return
+	}
 	go listener.run()
 }
 
@@ -95,6 +98,7 @@ func parseTelegramChannel(raw string) (int64, string, error) {
 }
 
 func (l *telegramListener) run() {
+	defer endHandling()
 	log.Println("[telegram] listener started")
 	u := tgbotapi.NewUpdate(0)
 	u.Timeout = 30
@@ -110,7 +114,13 @@ func (l *telegramListener) run() {
 			}
 			l.handleUpdate(update)
 		case <-ticker.C:
-			l.flushGroups()
+			l.flushGroups(false)
+		case <-listenersCtx.Done():
+			// 停止拉取更新，并处理尚在等待的媒体组后退出
+			l.bot.StopReceivingUpdates()
+			l.flushGroups(true)
+			log.Println("[telegram] listener stopped")
+			return
 		}
 	}
 }
@@ -166,10 +176,10 @@ func (l *telegramListener) collectGroup(msg *tgbotapi.Message) {
 	group.LastSeen = time.Now()
 }
 
-func (l *telegramListener) flushGroups() {
+func (l *telegramListener) flushGroups(all bool) {
 	now := time.Now()
 	for id, group := range l.pendingGroups {
-		if now.Sub(group.LastSeen) >= 2*time.Second {
+		if all || now.Sub(group.LastSeen) >= 2*time.Second {
 			l.processMediaGroup(group.Messages)
 			delete(l.pendingGroups, id)
 		}
@@ -286,7 +296,11 @@ func (l *telegramListener) downloadAndStore(msg *tgbotapi.Message) ([]model.Mome
 		return nil, fmt.Errorf("telegram file path is empty")
 	}
 
-	resp, err := http.Get(file.Link(l.bot.Token))
+	req, err := http.NewRequestWithContext(downloadCtx, http.MethodGet, file.Link(l.bot.Token), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
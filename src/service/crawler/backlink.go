@@ -0,0 +1,136 @@
+package crawlerService
+
+import (
+	"blog_api/src/config"
+	"blog_api/src/model"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html/charset"
+)
+
+// backlinkDomains 返回用于反链检查的本站域名（小写、去掉 www. 前缀）
+// 未配置 backlink_domains 时使用 feed_conf.site_url 的域名，两者都为空则不检查
+func backlinkDomains() []string {
+	cfg := config.GetConfig()
+	candidates := cfg.Crawler.BacklinkDomains
+	if len(candidates) == 0 && cfg.Feed.SiteURL != "" {
+		candidates = []string{cfg.Feed.SiteURL}
+	}
+
+	domains := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		if host := normalizeBacklinkHost(candidate); host != "" {
+			domains = append(domains, host)
+		}
+	}
+	return domains
+}
+
+// normalizeBacklinkHost 从域名或 URL 中取出主机名
+func normalizeBacklinkHost(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ""
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "//" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+// findBacklink 检查页面中是否有指向本站域名（含子域名）的链接
+func findBacklink(doc *goquery.Document, base *url.URL, domains []string) bool {
+	found := false
+	doc.Find("a[href]").EachWithBreak(func(i int, s *goquery.Selection) bool {
+		href, _ := s.Attr("href")
+		absoluteURL := toAbsoluteURL(base, strings.TrimSpace(href))
+		if absoluteURL == "" {
+			return true
+		}
+		host := normalizeBacklinkHost(absoluteURL)
+		// 同站链接不算反链
+		if host == "" || host == normalizeBacklinkHost(base.String()) {
+			return true
+		}
+		for _, domain := range domains {
+			if host == domain || strings.HasSuffix(host, "."+domain) {
+				found = true
+				return false
+			}
+		}
+		return true
+	})
+	return found
+}
+
+// checkFriendsPage 抓取友链页并检查其中是否有本站链接
+func checkFriendsPage(ctx context.Context, pageURL string, domains []string) (bool, error) {
+	client := newCrawlerClient(10*time.Second, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", pageURL, nil)
+	if err != nil {
+		return false, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return false, err
+	}
+	e, _, _ := charset.DetermineEncoding(bodyBytes, resp.Header.Get("Content-Type"))
+	doc, err := goquery.NewDocumentFromReader(e.NewDecoder().Reader(bytes.NewBuffer(bodyBytes)))
+	if err != nil {
+		return false, err
+	}
+	return findBacklink(doc, resp.Request.URL, domains), nil
+}
+
+// checkBacklink 在首页结果的基础上补充友链页的反链检查
+// 首页已找到反链时不再请求友链页；友链页抓取失败且首页未找到时结果置为未检查，避免误判为丢失
+func checkBacklink(ctx context.Context, result *CrawlJobResult) {
+	link := result.Link
+	if link.FriendsPageURL == "" || result.Result.Status != "survival" {
+		return
+	}
+	if result.Result.Backlink != nil && *result.Result.Backlink {
+		return
+	}
+	domains := backlinkDomains()
+	if len(domains) == 0 {
+		return
+	}
+
+	found, err := checkFriendsPage(ctx, link.FriendsPageURL, domains)
+	if err != nil {
+		log.Printf("[crawler]检查 %s 的友链页 %s 时出错: %v", link.Name, link.FriendsPageURL, err)
+		result.Result.Backlink = nil
+		return
+	}
+	result.Result.Backlink = &found
+}
+
+// CrawlFriendLink 爬取单个友链并检查反链，判断方式与定时爬取一致
+func CrawlFriendLink(ctx context.Context, link model.FriendWebsite) model.CrawlResult {
+	result := CrawlJobResult{Link: link, Result: CrawlWebsite(ctx, link.Link)}
+	checkBacklink(ctx, &result)
+	return result.Result
+}
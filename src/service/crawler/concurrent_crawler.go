@@ -3,6 +3,7 @@ package crawlerService
 import (
 	"blog_api/src/config"
 	"blog_api/src/model"
+	"context"
 	"log"
 	"sync"
 )
@@ -30,8 +31,8 @@ type ImageCheckJob struct {
 }
 
 // CrawlWebsitesConcurrently 并发爬取多个网站
-// 使用 worker pool 模式，并发数量由配置文件控制
-func CrawlWebsitesConcurrently(links []model.FriendWebsite) []CrawlJobResult {
+// 使用 worker pool 模式，并发数量由配置文件控制；ctx 被取消后不再爬取剩余网站，已中止的爬取不返回结果
+func CrawlWebsitesConcurrently(ctx context.Context, links []model.FriendWebsite) []CrawlJobResult {
 	if len(links) == 0 {
 		return []CrawlJobResult{}
 	}
@@ -47,7 +48,7 @@ func CrawlWebsitesConcurrently(links []model.FriendWebsite) []CrawlJobResult {
 	var wg sync.WaitGroup
 	for i := 0; i < concurrency; i++ {
 		wg.Add(1)
-		go crawlWorker(i, jobs, results, &wg)
+		go crawlWorker(ctx, i, jobs, results, &wg)
 	}
 
 	// 发送任务到任务通道
@@ -73,22 +74,30 @@ func CrawlWebsitesConcurrently(links []model.FriendWebsite) []CrawlJobResult {
 }
 
 // crawlWorker 是 worker goroutine，从任务通道获取任务并执行爬取
-func crawlWorker(id int, jobs <-chan CrawlJob, results chan<- CrawlJobResult, wg *sync.WaitGroup) {
+func crawlWorker(ctx context.Context, id int, jobs <-chan CrawlJob, results chan<- CrawlJobResult, wg *sync.WaitGroup) {
 	defer wg.Done()
 
 	for job := range jobs {
+		if ctx.Err() != nil {
+			continue
+		}
 		log.Printf("[ConcurrentCrawler][Worker %d] 正在爬取: %s", id, job.Link.Link)
-		result := CrawlWebsite(job.Link.Link)
-		results <- CrawlJobResult{
+		jobResult := CrawlJobResult{
 			Link:   job.Link,
-			Result: result,
+			Result: CrawlWebsite(ctx, job.Link.Link),
+		}
+		checkBacklink(ctx, &jobResult)
+		if ctx.Err() != nil {
+			log.Printf("[ConcurrentCrawler][Worker %d] 已取消爬取: %s", id, job.Link.Link)
+			continue
 		}
-		log.Printf("[ConcurrentCrawler][Worker %d] 完成爬取: %s, 状态: %s", id, job.Link.Link, result.Status)
+		results <- jobResult
+		log.Printf("[ConcurrentCrawler][Worker %d] 完成爬取: %s, 状态: %s", id, job.Link.Link, jobResult.Result.Status)
 	}
 }
 
-// ParseRssFeedsConcurrently 并发解析多个 RSS 订阅源
-func ParseRssFeedsConcurrently(feeds []model.FriendRss, parseFunc func(friendRssID int, rssURL string)) {
+// ParseRssFeedsConcurrently 并发解析多个 RSS 订阅源，ctx 被取消后不再解析剩余订阅源
+func ParseRssFeedsConcurrently(ctx context.Context, feeds []model.FriendRss, parseFunc func(friendRssID int, rssURL string)) {
 	if len(feeds) == 0 {
 		return
 	}
@@ -116,7 +125,7 @@ func ParseRssFeedsConcurrently(feeds []model.FriendRss, parseFunc func(friendRss
 	var wg sync.WaitGroup
 	for i := 0; i < concurrency; i++ {
 		wg.Add(1)
-		go rssParseWorker(i, jobs, parseFunc, &wg)
+		go rssParseWorker(ctx, i, jobs, parseFunc, &wg)
 	}
 
 	// 发送任务到任务通道
@@ -135,18 +144,21 @@ func ParseRssFeedsConcurrently(feeds []model.FriendRss, parseFunc func(friendRss
 }
 
 // rssParseWorker 是 RSS 解析的 worker goroutine
-func rssParseWorker(id int, jobs <-chan RssParseJob, parseFunc func(friendRssID int, rssURL string), wg *sync.WaitGroup) {
+func rssParseWorker(ctx context.Context, id int, jobs <-chan RssParseJob, parseFunc func(friendRssID int, rssURL string), wg *sync.WaitGroup) {
 	defer wg.Done()
 
 	for job := range jobs {
+		if ctx.Err() != nil {
+			continue
+		}
 		log.Printf("[ConcurrentCrawler][Worker %d] 正在解析 RSS: %s", id, job.RssURL)
 		parseFunc(job.FriendRssID, job.RssURL)
 		log.Printf("[ConcurrentCrawler][Worker %d] 完成解析 RSS: %s", id, job.RssURL)
 	}
 }
 
-// CheckImagesConcurrently 并发检查图片
-func CheckImagesConcurrently(images []model.Image, checkFunc func(image model.Image)) {
+// CheckImagesConcurrently 并发检查图片，ctx 被取消后不再检查剩余图片
+func CheckImagesConcurrently(ctx context.Context, images []model.Image, checkFunc func(image model.Image)) {
 	if len(images) == 0 {
 		return
 	}
@@ -158,7 +170,7 @@ func CheckImagesConcurrently(images []model.Image, checkFunc func(image model.Im
 	var wg sync.WaitGroup
 	for i := 0; i < concurrency; i++ {
 		wg.Add(1)
-		go imageCheckWorker(i, jobs, checkFunc, &wg)
+		go imageCheckWorker(ctx, i, jobs, checkFunc, &wg)
 	}
 
 	for _, img := range images {
@@ -170,10 +182,13 @@ func CheckImagesConcurrently(images []model.Image, checkFunc func(image model.Im
 	log.Printf("[ConcurrentCrawler] 完成并发检查 %d 张图片", len(images))
 }
 
-func imageCheckWorker(id int, jobs <-chan ImageCheckJob, checkFunc func(image model.Image), wg *sync.WaitGroup) {
+func imageCheckWorker(ctx context.Context, id int, jobs <-chan ImageCheckJob, checkFunc func(image model.Image), wg *sync.WaitGroup) {
 	defer wg.Done()
 
 	for job := range jobs {
+		if ctx.Err() != nil {
+			continue
+		}
 		log.Printf("[ConcurrentCrawler][Worker %d] 正在检查图片: %s", id, job.Image.URL)
 		checkFunc(job.Image)
 		log.Printf("[ConcurrentCrawler][Worker %d] 完成检查图片: %s", id, job.Image.URL)
@@ -1,10 +1,15 @@
 package crawlerService
 
 import (
+	"blog_api/src/config"
 	"blog_api/src/model"
+	"context"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
 	"bytes"
@@ -15,44 +20,63 @@ import (
 )
 
 // CrawlWebsite 获取并解析网站以提取 SEO 信息
-func CrawlWebsite(url string) model.CrawlResult {
-	client := &http.Client{
-		Timeout: 10 * time.Second, // 设置超时以防止挂起
-		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			return http.ErrUseLastResponse // 不跟随重定向
-		},
-	}
+// 跟随重定向并爬取最终页面，RedirectURL 为最终地址，PermanentRedirect 表示每一跳都是 301/308
+// ctx 被取消时请求随之中止，调用方应丢弃此时的结果
+func CrawlWebsite(ctx context.Context, url string) model.CrawlResult {
+	maxRedirects := config.GetConfig().Crawler.MaxRedirects
+	hops := 0
+	permanent := true
+	// 设置超时以防止挂起
+	client := newCrawlerClient(10*time.Second, func(req *http.Request, via []*http.Request) error {
+		if len(via) > maxRedirects {
+			return fmt.Errorf("stopped after %d redirects", maxRedirects)
+		}
+		hops = len(via)
+		if req.Response != nil && req.Response.StatusCode != http.StatusMovedPermanently && req.Response.StatusCode != http.StatusPermanentRedirect {
+			permanent = false
+		}
+		return nil
+	})
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		log.Printf("[crawler]创建获取 %s 的请求时出错: %v", url, err)
-		return model.CrawlResult{Status: "error"}
+		return model.CrawlResult{Status: "error", Error: err.Error()}
 	}
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36 -  blog_api_webCrawler")
+	start := time.Now()
 	resp, err := client.Do(req)
+	latency := time.Since(start).Milliseconds()
+	if errors.Is(err, ErrRobotsDisallowed) {
+		log.Printf("[crawler]%s 被 robots.txt 禁止抓取，跳过", url)
+		return model.CrawlResult{Status: "disallowed", Error: err.Error()}
+	}
 	if err != nil {
 		log.Printf("[crawler]获取 URL %s 时出错: %v", url, err)
-		return model.CrawlResult{Status: "timeout"}
+		if resp != nil {
+			// 重定向次数超限
+			resp.Body.Close()
+			return model.CrawlResult{Status: "error", HTTPStatus: resp.StatusCode, LatencyMs: latency, Error: err.Error()}
+		}
+		return model.CrawlResult{Status: "timeout", LatencyMs: latency, Error: err.Error()}
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode >= 300 && resp.StatusCode < 400 {
-		redirectLocation := resp.Header.Get("Location")
-		absoluteRedirectURL := toAbsoluteURL(resp.Request.URL, redirectLocation)
-		log.Printf("[crawler]检测到 %s 重定向到 %s (resolved to %s)", url, redirectLocation, absoluteRedirectURL)
-		return model.CrawlResult{Status: "survival", RedirectURL: absoluteRedirectURL}
+	redirectURL := ""
+	if finalURL := resp.Request.URL.String(); hops > 0 && !sameSiteURL(url, finalURL) {
+		redirectURL = finalURL
+		log.Printf("[crawler]检测到 %s 经 %d 次重定向到 %s (永久: %t)", url, hops, finalURL, permanent)
 	}
 
 	if resp.StatusCode != http.StatusOK {
 		log.Printf("[crawler]错误: %s 的状态码非 200: %d", url, resp.StatusCode)
-		return model.CrawlResult{Status: "error"}
+		return model.CrawlResult{Status: "error", HTTPStatus: resp.StatusCode, LatencyMs: latency, Error: resp.Status, RedirectURL: redirectURL, RedirectHops: hops}
 	}
 
 	// 读取响应体内容
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
 		log.Printf("[crawler]读取 %s 的响应体时出错: %v", url, err)
-		return model.CrawlResult{Status: "error"}
+		return model.CrawlResult{Status: "error", HTTPStatus: resp.StatusCode, LatencyMs: latency, Error: err.Error(), RedirectURL: redirectURL, RedirectHops: hops}
 	}
 	// 重置响应体以便后续读取
 	resp.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
@@ -67,7 +91,7 @@ func CrawlWebsite(url string) model.CrawlResult {
 	doc, err := goquery.NewDocumentFromReader(utf8Reader)
 	if err != nil {
 		log.Printf("[crawler]解析 %s 的 HTML 时出错: %v", url, err)
-		return model.CrawlResult{Status: "error"}
+		return model.CrawlResult{Status: "error", HTTPStatus: resp.StatusCode, LatencyMs: latency, Error: err.Error(), RedirectURL: redirectURL, RedirectHops: hops}
 	}
 
 	// 查找描述
@@ -110,12 +134,38 @@ func CrawlWebsite(url string) model.CrawlResult {
 		rssURLs = rss2URLs
 	}
 
-	return model.CrawlResult{
+	// 图标按最终页面地址解析，避免跨域重定向后相对路径指向旧站
+	if iconURL != "" {
+		if absoluteIconURL := toAbsoluteURL(resp.Request.URL, iconURL); absoluteIconURL != "" {
+			iconURL = absoluteIconURL
+		}
+	}
+
+	result := model.CrawlResult{
 		Description: description,
 		IconURL:     iconURL,
 		Status:      "survival",
 		RssURLs:     rssURLs,
+		HTTPStatus:  resp.StatusCode,
+		LatencyMs:   latency,
+		RedirectURL: redirectURL,
+
+		RedirectHops:      hops,
+		PermanentRedirect: redirectURL != "" && permanent,
+	}
+
+	// 检查首页是否有指向本站的链接
+	if domains := backlinkDomains(); len(domains) > 0 {
+		found := findBacklink(doc, resp.Request.URL, domains)
+		result.Backlink = &found
 	}
+
+	return result
+}
+
+// sameSiteURL 判断两个地址是否只在末尾斜杠上不同
+func sameSiteURL(a, b string) bool {
+	return strings.TrimSuffix(a, "/") == strings.TrimSuffix(b, "/")
 }
 
 // toAbsoluteURL 根据基础 URL 将相对 URL 转换为绝对 URL
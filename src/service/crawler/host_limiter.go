@@ -0,0 +1,174 @@
+package crawlerService
+
+import (
+	"blog_api/src/config"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// hostSweepInterval 清理空闲主机槽位的最小间隔
+const hostSweepInterval = 10 * time.Minute
+
+// hostSlot 单个主机的并发槽位与下一次允许请求的时间
+type hostSlot struct {
+	sem   chan struct{}
+	users int // 正在排队或持有槽位的请求数，由 hostLimiter.mu 保护
+	mu    sync.Mutex
+	next  time.Time
+}
+
+// hostLimiter 按主机限制并发数与请求间隔，友链爬取、RSS 解析和图片检查共用
+type hostLimiter struct {
+	mu        sync.Mutex
+	slots     map[string]*hostSlot
+	lastSweep time.Time
+}
+
+var hosts = &hostLimiter{slots: make(map[string]*hostSlot)}
+
+// acquire 等待该主机的空闲槽位，并保证与上一次请求至少间隔 delay；返回释放函数
+func (l *hostLimiter) acquire(ctx context.Context, host string, delay time.Duration) (func(), error) {
+	slot := l.get(strings.ToLower(host))
+
+	select {
+	case slot.sem <- struct{}{}:
+	case <-ctx.Done():
+		l.put(slot)
+		return nil, ctx.Err()
+	}
+	release := func() {
+		<-slot.sem
+		l.put(slot)
+	}
+
+	slot.mu.Lock()
+	now := time.Now()
+	wait := max(slot.next.Sub(now), 0)
+	slot.next = now.Add(wait + delay)
+	slot.mu.Unlock()
+
+	if wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			release()
+			return nil, ctx.Err()
+		}
+	}
+	return release, nil
+}
+
+// get 取出主机的槽位并登记使用者，同时定期清理空闲的主机
+func (l *hostLimiter) get(host string) *hostSlot {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if now := time.Now(); now.Sub(l.lastSweep) >= hostSweepInterval {
+		l.sweep(now)
+		l.lastSweep = now
+	}
+	slot, ok := l.slots[host]
+	if !ok {
+		concurrency := config.GetConfig().Crawler.PerHostConcurrency
+		if concurrency <= 0 {
+			concurrency = 1
+		}
+		slot = &hostSlot{sem: make(chan struct{}, concurrency)}
+		l.slots[host] = slot
+	}
+	slot.users++
+	return slot
+}
+
+// put 注销 get 登记的使用者
+func (l *hostLimiter) put(slot *hostSlot) {
+	l.mu.Lock()
+	slot.users--
+	l.mu.Unlock()
+}
+
+// sweep 删除没有使用者且请求间隔已过的主机；调用方需持有 l.mu
+func (l *hostLimiter) sweep(now time.Time) {
+	for host, slot := range l.slots {
+		if slot.users > 0 {
+			continue
+		}
+		slot.mu.Lock()
+		idle := !slot.next.After(now)
+		slot.mu.Unlock()
+		if idle {
+			delete(l.slots, host)
+		}
+	}
+}
+
+// politeTransport 为爬虫请求统一设置 User-Agent，检查 robots.txt 并按主机限速
+// 重定向的每一跳都会经过这里；超时从拿到主机槽位后开始计算，排队时间不计入
+type politeTransport struct {
+	base       http.RoundTripper
+	timeout    time.Duration
+	skipRobots bool
+}
+
+func (t *politeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	delay := time.Duration(config.GetConfig().Crawler.PerHostDelayMs) * time.Millisecond
+	if !t.skipRobots {
+		allowed, crawlDelay := robotsAllowed(req.Context(), req.URL)
+		if !allowed {
+			return nil, ErrRobotsDisallowed
+		}
+		delay = max(delay, crawlDelay)
+	}
+
+	release, err := hosts.acquire(req.Context(), req.URL.Host, delay)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), t.timeout)
+	req = req.Clone(ctx)
+	req.Header.Set("User-Agent", crawlerUserAgent())
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		cancel()
+		release()
+		return nil, err
+	}
+	// 响应体读完关闭后才释放槽位
+	resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: func() {
+		cancel()
+		release()
+	}}
+	return resp, nil
+}
+
+// releaseOnClose 关闭响应体时释放主机槽位
+type releaseOnClose struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releaseOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
+
+// newCrawlerClient 创建爬虫使用的 HTTP 客户端，timeout 为每次请求（每一跳）的超时
+func newCrawlerClient(timeout time.Duration, checkRedirect func(req *http.Request, via []*http.Request) error) *http.Client {
+	return &http.Client{
+		Transport:     &politeTransport{base: http.DefaultTransport, timeout: timeout},
+		CheckRedirect: checkRedirect,
+	}
+}
+
+// crawlerUserAgent 爬虫请求使用的 User-Agent，由 crawler_conf.user_agent 配置
+func crawlerUserAgent() string {
+	return config.GetConfig().Crawler.UserAgent
+}
@@ -3,6 +3,9 @@ package crawlerService
 import (
 	"blog_api/src/model"
 	imageRepositories "blog_api/src/repositories/image"
+	jobService "blog_api/src/service/job"
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -15,30 +18,37 @@ import (
 )
 
 // CheckImagesHealth checks local and remote images and updates status when broken or recovered.
-func CheckImagesHealth(db *gorm.DB) {
+// Progress is reported to run, which may be nil.
+func CheckImagesHealth(ctx context.Context, db *gorm.DB, run *jobService.Run) error {
 	images, err := imageRepositories.ListImages(db)
 	if err != nil {
 		log.Printf("[crawler][image][ERR] Failed to list images: %v", err)
-		return
+		return err
 	}
 
 	if len(images) == 0 {
 		log.Println("[crawler][image] No images found for health check.")
-		return
+		return nil
 	}
+	run.SetTotal(len(images))
 
-	client := &http.Client{Timeout: 10 * time.Second}
+	client := newCrawlerClient(10*time.Second, nil)
 	checkedCount := 0
 	brokenCount := 0
 	recoveredCount := 0
 	var mu sync.Mutex
 
-	CheckImagesConcurrently(images, func(img model.Image) {
-		exists, checked, err := checkImageExists(img, client)
+	CheckImagesConcurrently(ctx, images, func(img model.Image) {
+		exists, checked, err := checkImageExists(ctx, img, client)
+		if ctx.Err() != nil {
+			return
+		}
 		if err != nil {
 			log.Printf("[crawler][image][WARN] Image check failed (ID: %d, URL: %s): %v", img.ID, img.URL, err)
+			run.ItemFailed(fmt.Sprintf("image %d", img.ID), err)
 			return
 		}
+		run.ItemDone()
 		if !checked {
 			return
 		}
@@ -72,9 +82,10 @@ func CheckImagesHealth(db *gorm.DB) {
 	})
 
 	log.Printf("[crawler][image] Image health check finished. checked=%d broken=%d recovered=%d", checkedCount, brokenCount, recoveredCount)
+	return nil
 }
 
-func checkImageExists(img model.Image, client *http.Client) (bool, bool, error) {
+func checkImageExists(ctx context.Context, img model.Image, client *http.Client) (bool, bool, error) {
 	if img.IsLocal == 1 || img.LocalPath != "" {
 		if img.LocalPath == "" {
 			return false, false, fmt.Errorf("local image has empty local_path")
@@ -93,11 +104,15 @@ func checkImageExists(img model.Image, client *http.Client) (bool, bool, error)
 		if img.URL == "" {
 			return false, false, fmt.Errorf("remote image has empty url")
 		}
-		req, err := http.NewRequest(http.MethodHead, img.URL, nil)
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, img.URL, nil)
 		if err != nil {
 			return false, true, err
 		}
 		resp, err := client.Do(req)
+		if errors.Is(err, ErrRobotsDisallowed) {
+			// robots.txt 禁止抓取时不检查，保持原状态
+			return false, false, nil
+		}
 		if err != nil {
 			return false, true, err
 		}
@@ -0,0 +1,51 @@
+package crawlerService
+
+import (
+	"blog_api/src/config"
+	"blog_api/src/model"
+	friendsRepositories "blog_api/src/repositories/friend"
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// ApplyRedirectPolicy 根据爬取结果跟踪友链的永久重定向
+// 只有连续多次爬取都以 301/308 重定向到同一地址才视为迁移，随后按配置自动更新地址或提交管理员审核；
+// 临时重定向或未重定向时清除尚未确认的观察记录
+func ApplyRedirectPolicy(db *gorm.DB, link model.FriendWebsite, result model.CrawlResult) {
+	if result.Status != "survival" {
+		return
+	}
+
+	if result.RedirectURL == "" || !result.PermanentRedirect {
+		if err := friendsRepositories.ClearFriendLinkRedirectObservations(db, link.ID); err != nil {
+			log.Printf("[crawler]清除友链 %s 的重定向观察记录失败: %v", link.Name, err)
+		}
+		return
+	}
+
+	change, err := friendsRepositories.ObserveFriendLinkRedirect(db, link.ID, link.Link, result.RedirectURL)
+	if err != nil {
+		log.Printf("[crawler]记录友链 %s 的重定向失败: %v", link.Name, err)
+		return
+	}
+	crawlerCfg := config.GetConfig().Crawler
+	if change.Status != "observing" || change.Hits < crawlerCfg.RedirectConfirmCount {
+		return
+	}
+
+	if crawlerCfg.RedirectAutoUpdate {
+		if err := friendsRepositories.ApplyFriendLinkURLChange(db, change, "applied"); err != nil {
+			log.Printf("[crawler]自动更新友链 %s 的地址失败: %v", link.Name, err)
+			return
+		}
+		log.Printf("[crawler]友链 %s 已迁移，地址自动更新为 %s", link.Name, change.NewURL)
+		return
+	}
+
+	if err := friendsRepositories.MarkFriendLinkURLChangePending(db, change.ID); err != nil {
+		log.Printf("[crawler]提交友链 %s 的地址变更审核失败: %v", link.Name, err)
+		return
+	}
+	log.Printf("[crawler]友链 %s 疑似迁移到 %s，等待管理员审核", link.Name, change.NewURL)
+}
@@ -0,0 +1,309 @@
+package crawlerService
+
+import (
+	"blog_api/src/config"
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrRobotsDisallowed 目标地址被 robots.txt 禁止抓取
+var ErrRobotsDisallowed = errors.New("disallowed by robots.txt")
+
+const (
+	// robotsMaxBytes robots.txt 最多读取的字节数（RFC 9309 要求至少解析 500 KiB）
+	robotsMaxBytes = 512 * 1024
+	// robotsMaxCrawlDelay Crawl-delay 的上限，避免个别站点拖慢整个任务
+	robotsMaxCrawlDelay = 30 * time.Second
+	// robotsRetryAfter robots.txt 无法获取时的缓存时间，到期后重新获取
+	robotsRetryAfter = 10 * time.Minute
+	// robotsSweepInterval 清理过期缓存项的最小间隔
+	robotsSweepInterval = 10 * time.Minute
+)
+
+// robotsRule 一条 Allow / Disallow 规则
+type robotsRule struct {
+	allow   bool
+	pattern string
+}
+
+// robotsGroup 一组 User-agent 及其规则
+type robotsGroup struct {
+	agents     []string
+	rules      []robotsRule
+	crawlDelay time.Duration
+}
+
+// robotsRules 适用于本爬虫的规则
+type robotsRules struct {
+	rules      []robotsRule
+	crawlDelay time.Duration
+}
+
+// robotsDisallowAll 站点 robots.txt 返回 5xx 时按全部禁止处理（RFC 9309 2.3.1.4）
+var robotsDisallowAll = &robotsRules{rules: []robotsRule{{allow: false, pattern: "/"}}}
+
+// robotsEntry robots.txt 缓存项
+type robotsEntry struct {
+	mu        sync.Mutex
+	rules     *robotsRules
+	expiresAt time.Time
+}
+
+var (
+	robotsCacheMu   sync.Mutex
+	robotsCache     = make(map[string]*robotsEntry)
+	robotsLastSweep time.Time
+)
+
+// robotsAllowed 判断是否允许抓取该地址，同时返回站点声明的 Crawl-delay
+// 同一站点的 robots.txt 只获取一次并缓存 robots_cache_minutes 分钟
+func robotsAllowed(ctx context.Context, u *url.URL) (bool, time.Duration) {
+	if config.GetConfig().Crawler.IgnoreRobotsTxt {
+		return true, 0
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return true, 0
+	}
+
+	rules := cachedRobotsRules(ctx, u.Scheme+"://"+u.Host)
+	if rules == nil {
+		return true, 0
+	}
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	return rules.allowed(path), rules.crawlDelay
+}
+
+func cachedRobotsRules(ctx context.Context, origin string) *robotsRules {
+	robotsCacheMu.Lock()
+	if now := time.Now(); now.Sub(robotsLastSweep) >= robotsSweepInterval {
+		sweepRobotsCache(now)
+		robotsLastSweep = now
+	}
+	entry, ok := robotsCache[origin]
+	if !ok {
+		entry = &robotsEntry{}
+		robotsCache[origin] = entry
+	}
+	robotsCacheMu.Unlock()
+
+	// 每个站点单独加锁，同一站点并发请求时只获取一次
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if time.Now().Before(entry.expiresAt) {
+		return entry.rules
+	}
+
+	rules, err := fetchRobotsRules(ctx, origin)
+	ttl := time.Duration(config.GetConfig().Crawler.RobotsCacheMinutes) * time.Minute
+	if err != nil {
+		// 请求被取消时不缓存结果，下次重新获取
+		if ctx.Err() != nil {
+			return rules
+		}
+		// 5xx 按全部禁止处理，网络错误按允许处理，稍后重试
+		if rules == robotsDisallowAll {
+			log.Printf("[crawler][robots]获取 %s/robots.txt 失败，暂按全部禁止处理: %v", origin, err)
+		} else {
+			log.Printf("[crawler][robots]获取 %s/robots.txt 失败，暂按允许处理: %v", origin, err)
+		}
+		ttl = robotsRetryAfter
+	}
+	entry.rules = rules
+	entry.expiresAt = time.Now().Add(ttl)
+	return rules
+}
+
+// sweepRobotsCache 删除已过期的缓存项，正在获取中的站点跳过；调用方需持有 robotsCacheMu
+func sweepRobotsCache(now time.Time) {
+	for origin, entry := range robotsCache {
+		if !entry.mu.TryLock() {
+			continue
+		}
+		if now.After(entry.expiresAt) {
+			delete(robotsCache, origin)
+		}
+		entry.mu.Unlock()
+	}
+}
+
+// fetchRobotsRules 获取并解析站点的 robots.txt
+// 4xx 视为没有限制；5xx 返回全部禁止的规则与 error；网络错误返回 error
+func fetchRobotsRules(ctx context.Context, origin string) (*robotsRules, error) {
+	// 获取 robots.txt 本身只限速，不再检查 robots 规则
+	client := &http.Client{Transport: &politeTransport{base: http.DefaultTransport, timeout: 10 * time.Second, skipRobots: true}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, origin+"/robots.txt", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 {
+		return nil, nil
+	}
+	if resp.StatusCode >= 500 {
+		return robotsDisallowAll, errors.New("unexpected status code: " + strconv.Itoa(resp.StatusCode))
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New("unexpected status code: " + strconv.Itoa(resp.StatusCode))
+	}
+	groups := parseRobots(io.LimitReader(resp.Body, robotsMaxBytes))
+	return selectRobotsGroup(groups, robotsProductToken(crawlerUserAgent())), nil
+}
+
+// parseRobots 解析 robots.txt，相邻的多个 User-agent 行共享同一组规则
+func parseRobots(r io.Reader) []*robotsGroup {
+	var groups []*robotsGroup
+	var current *robotsGroup
+	inAgents := false
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), robotsMaxBytes)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			if !inAgents {
+				current = &robotsGroup{}
+				groups = append(groups, current)
+				inAgents = true
+			}
+			current.agents = append(current.agents, strings.ToLower(value))
+		case "allow", "disallow":
+			inAgents = false
+			if current == nil {
+				continue
+			}
+			// 空的 Disallow 表示不限制
+			if value == "" {
+				continue
+			}
+			current.rules = append(current.rules, robotsRule{allow: key == "allow", pattern: value})
+		case "crawl-delay":
+			inAgents = false
+			if current == nil {
+				continue
+			}
+			if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+				current.crawlDelay = min(time.Duration(seconds*float64(time.Second)), robotsMaxCrawlDelay)
+			}
+		}
+	}
+	return groups
+}
+
+// selectRobotsGroup 选出适用于 token 的规则：优先使用 User-agent 与产品名完全一致（不区分大小写）的组，否则使用 * 组
+func selectRobotsGroup(groups []*robotsGroup, token string) *robotsRules {
+	var matched, wildcard []*robotsGroup
+	for _, group := range groups {
+		for _, agent := range group.agents {
+			if agent == "*" {
+				wildcard = append(wildcard, group)
+				break
+			}
+			if agent != "" && agent == token {
+				matched = append(matched, group)
+				break
+			}
+		}
+	}
+	if len(matched) == 0 {
+		matched = wildcard
+	}
+	if len(matched) == 0 {
+		return nil
+	}
+
+	rules := &robotsRules{}
+	for _, group := range matched {
+		rules.rules = append(rules.rules, group.rules...)
+		rules.crawlDelay = max(rules.crawlDelay, group.crawlDelay)
+	}
+	return rules
+}
+
+// allowed 按最长匹配原则判断，长度相同时 Allow 优先
+func (r *robotsRules) allowed(path string) bool {
+	bestLen := -1
+	allow := true
+	for _, rule := range r.rules {
+		if !robotsPatternMatch(rule.pattern, path) {
+			continue
+		}
+		if len(rule.pattern) > bestLen || (len(rule.pattern) == bestLen && rule.allow) {
+			bestLen = len(rule.pattern)
+			allow = rule.allow
+		}
+	}
+	return allow
+}
+
+// robotsPatternMatch 支持 * 通配符与结尾的 $ 锚点
+func robotsPatternMatch(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	if anchored {
+		pattern = strings.TrimSuffix(pattern, "$")
+	}
+
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	pos := len(parts[0])
+	for i, part := range parts[1:] {
+		if part == "" {
+			continue
+		}
+		// 锚定时最后一段必须出现在末尾
+		if anchored && i == len(parts)-2 {
+			return strings.HasSuffix(path[pos:], part)
+		}
+		idx := strings.Index(path[pos:], part)
+		if idx < 0 {
+			return false
+		}
+		pos += idx + len(part)
+	}
+	if anchored && !strings.HasSuffix(pattern, "*") {
+		return pos == len(path)
+	}
+	return true
+}
+
+// robotsProductToken 取 User-Agent 的产品名（第一个 / 或空格之前的部分），用于匹配 robots.txt 的 User-agent
+func robotsProductToken(userAgent string) string {
+	token := strings.Fields(userAgent)
+	if len(token) == 0 {
+		return ""
+	}
+	name, _, _ := strings.Cut(token[0], "/")
+	return strings.ToLower(name)
+}
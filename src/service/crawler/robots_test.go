@@ -0,0 +1,170 @@
+package crawlerService
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSelectRobotsGroup(t *testing.T) {
+	tests := []struct {
+		name      string
+		robots    string
+		token     string
+		wantNil   bool
+		wantRules []string // "+pattern" for Allow, "-pattern" for Disallow
+		wantDelay time.Duration
+	}{
+		{
+			name:      "exact product token wins over wildcard",
+			robots:    "User-agent: *\nDisallow: /\n\nUser-agent: BlogBot\nDisallow: /private\n",
+			token:     "blogbot",
+			wantRules: []string{"-/private"},
+		},
+		{
+			name:      "product token match is case-insensitive",
+			robots:    "User-agent: BLOGBOT\nDisallow: /a\n",
+			token:     "blogbot",
+			wantRules: []string{"-/a"},
+		},
+		{
+			name:      "substring of the token does not match",
+			robots:    "User-agent: bot\nDisallow: /\n\nUser-agent: *\nDisallow: /tmp\n",
+			token:     "blogbot",
+			wantRules: []string{"-/tmp"},
+		},
+		{
+			name:      "longer agent containing the token does not match",
+			robots:    "User-agent: blogbot-images\nDisallow: /\n\nUser-agent: *\nAllow: /\n",
+			token:     "blogbot",
+			wantRules: []string{"+/"},
+		},
+		{
+			name:      "groups for the same agent are merged",
+			robots:    "User-agent: blogbot\nDisallow: /a\nCrawl-delay: 2\n\nUser-agent: *\nDisallow: /\n\nUser-agent: blogbot\nAllow: /b\nCrawl-delay: 5\n",
+			token:     "blogbot",
+			wantRules: []string{"-/a", "+/b"},
+			wantDelay: 5 * time.Second,
+		},
+		{
+			name:      "consecutive user-agent lines share a group",
+			robots:    "User-agent: otherbot\nUser-agent: blogbot\nDisallow: /shared\n",
+			token:     "blogbot",
+			wantRules: []string{"-/shared"},
+		},
+		{
+			name:      "crawl-delay is capped",
+			robots:    "User-agent: *\nCrawl-delay: 3600\n",
+			token:     "blogbot",
+			wantDelay: robotsMaxCrawlDelay,
+		},
+		{
+			name:    "no matching group",
+			robots:  "User-agent: otherbot\nDisallow: /\n",
+			token:   "blogbot",
+			wantNil: true,
+		},
+		{
+			name:      "empty token only uses the wildcard group",
+			robots:    "User-agent: blogbot\nDisallow: /a\n\nUser-agent: *\nDisallow: /b\n",
+			token:     "",
+			wantRules: []string{"-/b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := selectRobotsGroup(parseRobots(strings.NewReader(tt.robots)), tt.token)
+			if tt.wantNil {
+				if rules != nil {
+					t.Fatalf("got %+v, want nil", rules)
+				}
+				return
+			}
+			if rules == nil {
+				t.Fatal("got nil rules")
+			}
+			var got []string
+			for _, rule := range rules.rules {
+				prefix := "-"
+				if rule.allow {
+					prefix = "+"
+				}
+				got = append(got, prefix+rule.pattern)
+			}
+			if strings.Join(got, " ") != strings.Join(tt.wantRules, " ") {
+				t.Errorf("rules = %v, want %v", got, tt.wantRules)
+			}
+			if rules.crawlDelay != tt.wantDelay {
+				t.Errorf("crawlDelay = %v, want %v", rules.crawlDelay, tt.wantDelay)
+			}
+		})
+	}
+}
+
+func TestRobotsRulesAllowed(t *testing.T) {
+	tests := []struct {
+		rules string
+		path  string
+		want  bool
+	}{
+		{"Disallow: /private", "/private/a", false},
+		{"Disallow: /private", "/public", true},
+		{"Disallow: /private", "/", true},
+		{"Disallow: /", "/anything", false},
+		{"Disallow:", "/anything", true},
+		// Longest match wins, Allow wins ties
+		{"Disallow: /a\nAllow: /a/b", "/a/b/c", true},
+		{"Allow: /a\nDisallow: /a/b", "/a/b/c", false},
+		{"Disallow: /a\nAllow: /a", "/a", true},
+		// * wildcard
+		{"Disallow: /*.php", "/index.php", false},
+		{"Disallow: /*.php", "/dir/index.php?x=1", false},
+		{"Disallow: /*.php", "/index.html", true},
+		{"Disallow: /a*b*c", "/a-x-b-y-c", false},
+		{"Disallow: /a*b*c", "/a-x-c-y-b", true},
+		// $ anchor
+		{"Disallow: /*.php$", "/index.php", false},
+		{"Disallow: /*.php$", "/index.php?x=1", true},
+		{"Disallow: /exact$", "/exact", false},
+		{"Disallow: /exact$", "/exact/more", true},
+		{"Disallow: /a*$", "/a/anything", false},
+		// The query string takes part in matching
+		{"Disallow: /search?q=", "/search?q=go", false},
+		{"Disallow: /search?q=", "/search", true},
+		// Paths are case-sensitive
+		{"Disallow: /Private", "/private", true},
+	}
+
+	for _, tt := range tests {
+		rules := selectRobotsGroup(parseRobots(strings.NewReader("User-agent: *\n"+tt.rules)), "blogbot")
+		if got := rules.allowed(tt.path); got != tt.want {
+			t.Errorf("rules %q: allowed(%q) = %v, want %v", tt.rules, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestRobotsDisallowAll(t *testing.T) {
+	for _, path := range []string{"/", "/robots.txt", "/a/b?c=d"} {
+		if robotsDisallowAll.allowed(path) {
+			t.Errorf("robotsDisallowAll.allowed(%q) = true", path)
+		}
+	}
+}
+
+func TestRobotsProductToken(t *testing.T) {
+	tests := []struct {
+		userAgent string
+		want      string
+	}{
+		{"BlogBot/1.0 (+https://example.com)", "blogbot"},
+		{"BlogBot", "blogbot"},
+		{"  Mozilla/5.0 BlogBot/1.0", "mozilla"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := robotsProductToken(tt.userAgent); got != tt.want {
+			t.Errorf("robotsProductToken(%q) = %q, want %q", tt.userAgent, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package crawlerService
+
+import (
+	"blog_api/src/config"
+	"blog_api/src/model"
+	friendsRepositories "blog_api/src/repositories/friend"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const maxRssErrorLength = 500
+
+// recordRssFetch 记录一次抓取结果，并根据连续失败次数迁移订阅源状态
+// 成功时恢复为 survival；连续失败达到阈值后依次进入 timeout、error
+func recordRssFetch(db *gorm.DB, feed model.FriendRss, statusCode int, fetchErr error) {
+	health := model.FriendRssHealth{
+		FetchedAt:      time.Now().Unix(),
+		LastHTTPStatus: statusCode,
+	}
+	if fetchErr == nil {
+		health.Success = true
+		health.Status = "survival"
+	} else {
+		health.FailCount = feed.FailCount + 1
+		health.LastError = truncateRssError(fetchErr.Error())
+		health.Status = nextRssFailureStatus(feed.Status, health.FailCount, config.GetConfig().Crawler)
+	}
+
+	// 已暂停的订阅源只记录抓取结果，不改变状态
+	if feed.Status == "pause" {
+		health.Status = ""
+	}
+
+	if err := friendsRepositories.UpdateFriendRssHealth(db, feed.ID, feed.Status, health); err != nil {
+		log.Printf("记录 RSS 源 %d 的抓取状态失败: %v", feed.ID, err)
+	}
+}
+
+func nextRssFailureStatus(current string, failCount int, cfg model.CrawlerConfig) string {
+	switch {
+	case failCount >= cfg.RssErrorThreshold:
+		return "error"
+	case failCount >= cfg.RssTimeoutThreshold:
+		return "timeout"
+	default:
+		return current
+	}
+}
+
+func truncateRssError(msg string) string {
+	runes := []rune(msg)
+	if len(runes) <= maxRssErrorLength {
+		return msg
+	}
+	return string(runes[:maxRssErrorLength])
+}
@@ -4,8 +4,11 @@ import (
 	"blog_api/src/config"
 	"blog_api/src/model"
 	friendsRepositories "blog_api/src/repositories/friend"
+	"context"
+	"errors"
 	"log"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/microcosm-cc/bluemonday"
@@ -13,34 +16,114 @@ import (
 	"gorm.io/gorm"
 )
 
-func newRssParser() *gofeed.Parser {
-	fp := gofeed.NewParser()
+// rssFetchResult 保存一次 RSS 拉取的结果
+type rssFetchResult struct {
+	Feed         *gofeed.Feed
+	NotModified  bool
+	StatusCode   int
+	ETag         string
+	LastModified string
+}
+
+func newRssHTTPClient() *http.Client {
 	timeoutSeconds := config.GetConfig().Crawler.RssTimeoutSeconds
 	if timeoutSeconds <= 0 {
 		timeoutSeconds = 15
 	}
-	fp.Client = &http.Client{Timeout: time.Duration(timeoutSeconds) * time.Second}
+	return newCrawlerClient(time.Duration(timeoutSeconds)*time.Second, nil)
+}
+
+func newRssParser() *gofeed.Parser {
+	fp := gofeed.NewParser()
+	fp.Client = newRssHTTPClient()
 	return fp
 }
 
-// ParseRssFeed parses an RSS feed and saves the articles to the database.
-func ParseRssFeed(db *gorm.DB, friendRssID int, rssURL string) {
+// fetchRssFeed 使用条件请求拉取 RSS 订阅源
+// 携带上次保存的 ETag / Last-Modified，服务端返回 304 时不再解析
+func fetchRssFeed(ctx context.Context, rssURL, etag, lastModified string) (*rssFetchResult, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rssURL, nil)
+	if err != nil {
+		return nil, err
+	}
 	fp := newRssParser()
-	feed, err := fp.ParseURL(rssURL)
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+
+	resp, err := fp.Client.Do(req)
 	if err != nil {
-		log.Printf("解析 RSS feed %s 时出错: %v", rssURL, err)
-		return
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	result := &rssFetchResult{
+		StatusCode:   resp.StatusCode,
+		ETag:         etag,
+		LastModified: lastModified,
+	}
+	if resp.StatusCode == http.StatusNotModified {
+		result.NotModified = true
+		return result, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return result, gofeed.HTTPError{StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+
+	feed, err := fp.Parse(resp.Body)
+	if err != nil {
+		return result, err
+	}
+	result.Feed = feed
+	result.ETag = resp.Header.Get("ETag")
+	result.LastModified = resp.Header.Get("Last-Modified")
+	return result, nil
+}
+
+// ParseRssFeed parses an RSS feed and saves the articles to the database.
+// Feeds skipped because of robots.txt or an unchanged response (304) are not errors.
+// Cancelling ctx aborts the fetch; posts are only written once the whole feed has been parsed.
+func ParseRssFeed(ctx context.Context, db *gorm.DB, friendRssID int, rssURL string) error {
+	friendRss := model.FriendRss{ID: friendRssID}
+	found := true
+	if err := db.Select("name, status, etag, last_modified, fail_count").Where("id = ?", friendRssID).First(&friendRss).Error; err != nil {
+		log.Printf("获取 RSS 源信息失败 (id=%d): %v", friendRssID, err)
+		found = false
 	}
 
-	friendRssName := ""
-	var friendRss model.FriendRss
-	if err := db.Select("name").Where("id = ?", friendRssID).First(&friendRss).Error; err == nil {
-		friendRssName = friendRss.Name
-	} else {
-		log.Printf("获取 RSS 源名称失败 (id=%d): %v", friendRssID, err)
+	result, err := fetchRssFeed(ctx, rssURL, friendRss.ETag, friendRss.LastModified)
+	if err != nil && ctx.Err() != nil {
+		// 取消不算作抓取失败
+		return ctx.Err()
+	}
+	if errors.Is(err, ErrRobotsDisallowed) {
+		// robots.txt 禁止抓取不算作失败，不改变订阅源状态
+		log.Printf("RSS feed %s 被 robots.txt 禁止抓取，跳过", rssURL)
+		return nil
+	}
+	if found {
+		statusCode := 0
+		if result != nil {
+			statusCode = result.StatusCode
+		}
+		recordRssFetch(db, friendRss, statusCode, err)
+	}
+	if err != nil {
+		log.Printf("解析 RSS feed %s 时出错: %v", rssURL, err)
+		return err
+	}
+	if result.NotModified {
+		log.Printf("RSS feed %s 未更新 (304)，跳过解析", rssURL)
+		return nil
 	}
+	feed := result.Feed
+	friendRssName := friendRss.Name
 
 	p := bluemonday.StripTagsPolicy()
+	posts := make([]*model.RssPost, 0, len(feed.Items))
 	for _, item := range feed.Items {
 		publishedTime := item.PublishedParsed
 		if publishedTime == nil {
@@ -84,26 +167,43 @@ func ParseRssFeed(db *gorm.DB, friendRssID int, rssURL string) {
 			author = friendRssName
 		}
 
-		post := &model.RssPost{
+		posts = append(posts, &model.RssPost{
 			RssID:       friendRssID,
 			Title:       item.Title,
 			Link:        item.Link,
 			Description: p.Sanitize(item.Description),
 			Author:      author,
 			Time:        time,
-		}
+			GUID:        strings.TrimSpace(item.GUID),
+		})
+	}
+
+	policy := config.GetConfig().Crawler.RssRetention
+	totalCutoff, err := friendsRepositories.RssTotalCapCutoff(db, policy)
+	if err != nil {
+		// 查询失败时只按时间和单个订阅源的限制过滤
+		log.Printf("查询 RSS 文章保留下限失败: %v", err)
+	}
+	posts = applyRssRetention(posts, policy, totalCutoff)
+
+	if _, _, err := friendsRepositories.UpsertRssPosts(db, friendRssID, posts); err != nil {
+		log.Printf("写入 RSS feed %s 的文章时出错: %v", rssURL, err)
+		return err
+	}
 
-		err := friendsRepositories.InsertRssPost(db, post)
-		if err != nil {
-			log.Printf("插入文章 '%s' 时出错: %v", item.Title, err)
+	// 文章入库成功后再保存校验头，失败时下次仍会完整拉取
+	if result.ETag != friendRss.ETag || result.LastModified != friendRss.LastModified {
+		if err := friendsRepositories.UpdateFriendRssCacheHeaders(db, friendRssID, result.ETag, result.LastModified); err != nil {
+			log.Printf("保存 RSS feed %s 的缓存校验头失败: %v", rssURL, err)
 		}
 	}
+	return nil
 }
 
 // GetRssTitle fetches and returns the title of an RSS feed.
-func GetRssTitle(rssURL string) (string, error) {
+func GetRssTitle(ctx context.Context, rssURL string) (string, error) {
 	fp := newRssParser()
-	feed, err := fp.ParseURL(rssURL)
+	feed, err := fp.ParseURLWithContext(rssURL, ctx)
 	if err != nil {
 		log.Printf("解析 RSS feed %s 时出错: %v", rssURL, err)
 		return "", err
@@ -0,0 +1,37 @@
+package crawlerService
+
+import (
+	"blog_api/src/model"
+	"sort"
+	"time"
+)
+
+// applyRssRetention drops the fetched posts that the retention policy would prune right away,
+// so the prune job and the parser do not keep deleting and re-inserting the same entries.
+// totalCutoff is the publish time of the oldest post kept under max_total_posts, 0 when the cap is not reached.
+func applyRssRetention(posts []*model.RssPost, policy model.RssRetentionConfig, totalCutoff int64) []*model.RssPost {
+	var cutoff int64
+	if policy.MaxAgeDays > 0 {
+		cutoff = time.Now().AddDate(0, 0, -policy.MaxAgeDays).Unix()
+	}
+	if policy.MaxTotalPosts > 0 {
+		cutoff = max(cutoff, totalCutoff)
+	}
+	if cutoff > 0 {
+		kept := posts[:0]
+		for _, post := range posts {
+			if post.Time >= cutoff {
+				kept = append(kept, post)
+			}
+		}
+		posts = kept
+	}
+
+	if policy.MaxPostsPerFeed > 0 && len(posts) > policy.MaxPostsPerFeed {
+		sort.SliceStable(posts, func(i, j int) bool {
+			return posts[i].Time > posts[j].Time
+		})
+		posts = posts[:policy.MaxPostsPerFeed]
+	}
+	return posts
+}
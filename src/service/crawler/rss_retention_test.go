@@ -0,0 +1,82 @@
+package crawlerService
+
+import (
+	"blog_api/src/model"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestApplyRssRetention(t *testing.T) {
+	now := time.Now().Unix()
+	day := int64(24 * 60 * 60)
+
+	tests := []struct {
+		name        string
+		times       []int64 // publish times of the fetched posts, in feed order
+		policy      model.RssRetentionConfig
+		totalCutoff int64
+		want        []int64
+	}{
+		{
+			name:  "no limits",
+			times: []int64{now, now - 10*day},
+			want:  []int64{now, now - 10*day},
+		},
+		{
+			name:   "older than max age",
+			times:  []int64{now, now - 10*day, now - 2*day},
+			policy: model.RssRetentionConfig{MaxAgeDays: 7},
+			want:   []int64{now, now - 2*day},
+		},
+		{
+			name:   "newest posts per feed",
+			times:  []int64{now - 3*day, now, now - day},
+			policy: model.RssRetentionConfig{MaxPostsPerFeed: 2},
+			want:   []int64{now, now - day},
+		},
+		{
+			name:        "older than the oldest post kept under the total cap",
+			times:       []int64{now, now - 5*day, now - 3*day},
+			policy:      model.RssRetentionConfig{MaxTotalPosts: 100},
+			totalCutoff: now - 3*day,
+			want:        []int64{now, now - 3*day},
+		},
+		{
+			name:        "total cap not reached",
+			times:       []int64{now, now - 5*day},
+			policy:      model.RssRetentionConfig{MaxTotalPosts: 100},
+			totalCutoff: 0,
+			want:        []int64{now, now - 5*day},
+		},
+		{
+			name:        "max age is stricter than the total cap",
+			times:       []int64{now, now - 5*day, now - 9*day},
+			policy:      model.RssRetentionConfig{MaxAgeDays: 7, MaxTotalPosts: 100},
+			totalCutoff: now - 30*day,
+			want:        []int64{now, now - 5*day},
+		},
+		{
+			name:        "total cutoff is ignored without a total cap",
+			times:       []int64{now, now - 5*day},
+			totalCutoff: now,
+			want:        []int64{now, now - 5*day},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			posts := make([]*model.RssPost, 0, len(tt.times))
+			for _, postTime := range tt.times {
+				posts = append(posts, &model.RssPost{Time: postTime})
+			}
+			var got []int64
+			for _, post := range applyRssRetention(posts, tt.policy, tt.totalCutoff) {
+				got = append(got, post.Time)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("kept = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package service
+
+import (
+	"blog_api/src/config"
+	"blog_api/src/model"
+	emailRepositories "blog_api/src/repositories/email"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrEmailDisabled 邮件服务未启用
+var ErrEmailDisabled = errors.New("email is disabled")
+
+const (
+	emailBatchSize = 20          // 每轮最多发送的邮件数
+	emailIdlePoll  = time.Minute // 没有待发送邮件时的检查间隔
+)
+
+var (
+	outboxMu     sync.Mutex
+	outboxCancel context.CancelFunc
+	outboxDone   chan struct{}
+	outboxWake   = make(chan struct{}, 1)
+)
+
+// EnqueueEmail 将邮件写入发件箱，由后台任务发送，失败时按指数退避重试
+// expiresAt 之后不再发送（如验证码邮件），0 表示不过期；邮件服务未启用时只记录日志并返回 ErrEmailDisabled
+func EnqueueEmail(db *gorm.DB, template string, to []string, content EmailContent, expiresAt int64) (model.EmailOutbox, error) {
+	if !config.GetConfig().Email.Enable {
+		log.Printf("[email][disabled] To=%s Subject=%s Body=%s", strings.Join(to, ","), content.Subject, content.Body)
+		return model.EmailOutbox{}, ErrEmailDisabled
+	}
+	if len(to) == 0 {
+		return model.EmailOutbox{}, fmt.Errorf("email recipients are required")
+	}
+	if content.Subject == "" {
+		return model.EmailOutbox{}, fmt.Errorf("email subject is required")
+	}
+	if content.IsHTML && content.HTML == "" {
+		content.HTML, content.Body = content.Body, ""
+	}
+
+	now := time.Now().Unix()
+	email := model.EmailOutbox{
+		Template:      template,
+		Recipients:    to,
+		Subject:       content.Subject,
+		Body:          content.Body,
+		HTML:          content.HTML,
+		Status:        "queued",
+		NextAttemptAt: now,
+		ExpiresAt:     expiresAt,
+		CreatedAt:     now,
+	}
+	if err := emailRepositories.CreateEmail(db, &email); err != nil {
+		return email, err
+	}
+	wakeEmailWorker()
+	return email, nil
+}
+
+// RetryEmail 将发送失败的邮件重新排队，重新计算尝试次数
+func RetryEmail(db *gorm.DB, id int) error {
+	if err := emailRepositories.RetryEmail(db, id); err != nil {
+		return err
+	}
+	wakeEmailWorker()
+	return nil
+}
+
+// StartEmailWorker 启动发件箱的后台发送任务，并将上次进程退出时发送中的邮件重新排队
+func StartEmailWorker(db *gorm.DB) {
+	if count, err := emailRepositories.RequeueInterruptedEmails(db); err != nil {
+		log.Printf("[email][ERR] 重新排队中断的邮件失败: %v", err)
+	} else if count > 0 {
+		log.Printf("[email] 已将 %d 封中断发送的邮件重新排队", count)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	outboxMu.Lock()
+	outboxCancel, outboxDone = cancel, done
+	outboxMu.Unlock()
+	go runEmailWorker(ctx, db, done)
+}
+
+// StopEmailWorker 停止发送新的邮件并等待正在发送的邮件结束，ctx 到期时返回 ctx 的错误
+// 未发送的邮件保留在发件箱中，下次启动后继续发送
+func StopEmailWorker(ctx context.Context) error {
+	outboxMu.Lock()
+	cancel, done := outboxCancel, outboxDone
+	outboxMu.Unlock()
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func wakeEmailWorker() {
+	select {
+	case outboxWake <- struct{}{}:
+	default:
+	}
+}
+
+func runEmailWorker(ctx context.Context, db *gorm.DB, done chan struct{}) {
+	defer close(done)
+	var lastPrune time.Time
+	for {
+		if time.Since(lastPrune) >= time.Hour {
+			pruneEmails(db)
+			lastPrune = time.Now()
+		}
+
+		wait := emailIdlePoll
+		if deliverDueEmails(ctx, db) == emailBatchSize {
+			// 可能还有到期的邮件，立即继续
+			wait = 0
+		} else if next, err := emailRepositories.GetNextAttemptAt(db); err != nil {
+			log.Printf("[email][ERR] %v", err)
+		} else if next > 0 {
+			wait = min(max(time.Until(time.Unix(next, 0)), time.Second), emailIdlePoll)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-outboxWake:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// deliverDueEmails 发送到期的邮件，返回本轮处理的数量
+func deliverDueEmails(ctx context.Context, db *gorm.DB) int {
+	emails, err := emailRepositories.GetDueEmails(db, time.Now().Unix(), emailBatchSize)
+	if err != nil {
+		log.Printf("[email][ERR] %v", err)
+		return 0
+	}
+	for i, email := range emails {
+		if ctx.Err() != nil {
+			return i
+		}
+		deliverEmail(db, email)
+	}
+	return len(emails)
+}
+
+func deliverEmail(db *gorm.DB, email model.EmailOutbox) {
+	conf := config.GetConfig().Email
+	to := strings.Join(email.Recipients, ",")
+
+	if email.ExpiresAt > 0 && time.Now().Unix() >= email.ExpiresAt {
+		log.Printf("[email] 邮件 %d (%q 至 %s) 已过期，不再发送", email.ID, email.Subject, to)
+		reason := "expired before delivery"
+		if email.LastError != "" {
+			reason += ": " + email.LastError
+		}
+		if err := emailRepositories.MarkEmailFailed(db, email.ID, reason); err != nil {
+			log.Printf("[email][ERR] %v", err)
+		}
+		return
+	}
+
+	claimed, err := emailRepositories.ClaimEmail(db, email.ID)
+	if err != nil {
+		log.Printf("[email][ERR] %v", err)
+		return
+	}
+	if !claimed {
+		return
+	}
+	attempts := email.Attempts + 1
+
+	sendErr := SendEmail(conf, email.Recipients, EmailContent{Subject: email.Subject, Body: email.Body, HTML: email.HTML})
+	if sendErr == nil {
+		log.Printf("[email] 邮件 %d (%q) 已发送至 %s", email.ID, email.Subject, to)
+		if err := emailRepositories.MarkEmailSent(db, email.ID); err != nil {
+			log.Printf("[email][ERR] %v", err)
+		}
+		return
+	}
+
+	if attempts >= conf.MaxAttempts {
+		log.Printf("[email][ERR] 邮件 %d (%q 至 %s) 第 %d 次发送失败，不再重试: %v", email.ID, email.Subject, to, attempts, sendErr)
+		err = emailRepositories.MarkEmailFailed(db, email.ID, sendErr.Error())
+	} else {
+		delay := emailRetryDelay(conf, attempts)
+		log.Printf("[email][ERR] 邮件 %d (%q 至 %s) 第 %d 次发送失败，%s 后重试: %v", email.ID, email.Subject, to, attempts, delay, sendErr)
+		err = emailRepositories.MarkEmailRetry(db, email.ID, sendErr.Error(), time.Now().Add(delay).Unix())
+	}
+	if err != nil {
+		log.Printf("[email][ERR] %v", err)
+	}
+}
+
+// emailRetryDelay 第 attempts 次失败后的等待时间：retry_base_seconds * 2^(attempts-1)，不超过 retry_max_seconds
+func emailRetryDelay(conf model.EmailConf, attempts int) time.Duration {
+	delay := time.Duration(conf.RetryBaseSeconds) * time.Second
+	limit := time.Duration(conf.RetryMaxSeconds) * time.Second
+	for i := 1; i < attempts && delay < limit; i++ {
+		delay *= 2
+	}
+	return min(delay, limit)
+}
+
+// pruneEmails 删除超过 log_retention_days 的已发送与失败记录
+func pruneEmails(db *gorm.DB) {
+	days := config.GetConfig().Email.LogRetentionDays
+	cutoff := time.Now().AddDate(0, 0, -days).Unix()
+	if count, err := emailRepositories.PruneEmails(db, cutoff); err != nil {
+		log.Printf("[email][ERR] %v", err)
+	} else if count > 0 {
+		log.Printf("[email] 已清理 %d 条超过 %d 天的投递记录", count, days)
+	}
+}
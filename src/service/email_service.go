@@ -5,52 +5,52 @@ import (
 	"bytes"
 	"crypto/tls"
 	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
 	"net/smtp"
+	"net/textproto"
 	"strings"
+	"time"
 )
 
 // EmailContent holds the content to send.
+// When both HTML and Body are set, Body is sent as the plain-text alternative in a multipart/alternative message.
 type EmailContent struct {
 	Subject string
 	Body    string
 	IsHTML  bool
+	HTML    string
 }
 
-// SendEmail sends an email using the provided config, recipients, and content.
+// SendEmail sends an email right away through the configured transport.
+// Most callers should use EnqueueEmail, which retries failed deliveries in the background.
 func SendEmail(conf model.EmailConf, to []string, content EmailContent) error {
 	if !conf.Enable {
 		return fmt.Errorf("email is disabled")
 	}
-	if conf.Host == "" {
-		return fmt.Errorf("email host is required")
-	}
-	if conf.Port == 0 {
-		return fmt.Errorf("email port is required")
-	}
-	if conf.UserName == "" {
-		return fmt.Errorf("email username is required")
-	}
 	if len(to) == 0 {
 		return fmt.Errorf("email recipients are required")
 	}
+	transport, err := NewEmailTransport(conf)
+	if err != nil {
+		return err
+	}
 
 	sender := conf.Sender
 	if sender == "" {
 		sender = conf.UserName
 	}
+	if sender == "" {
+		sender = "blog_api@localhost"
+	}
 
 	msg, err := buildEmailMessage(sender, to, content)
 	if err != nil {
 		return err
 	}
-
-	addr := fmt.Sprintf("%s:%d", conf.Host, conf.Port)
-	auth := smtp.PlainAuth("", conf.UserName, conf.Password, conf.Host)
-
-	if conf.Port == 465 {
-		return sendWithImplicitTLS(addr, conf.Host, sender, to, auth, msg)
-	}
-	return sendWithSTARTTLS(addr, conf.Host, sender, to, auth, msg)
+	return transport.Send(sender, to, msg)
 }
 
 func buildEmailMessage(sender string, to []string, content EmailContent) ([]byte, error) {
@@ -60,18 +60,62 @@ func buildEmailMessage(sender string, to []string, content EmailContent) ([]byte
 	var buf bytes.Buffer
 	buf.WriteString(fmt.Sprintf("From: %s\r\n", sender))
 	buf.WriteString(fmt.Sprintf("To: %s\r\n", strings.Join(to, ", ")))
-	buf.WriteString(fmt.Sprintf("Subject: %s\r\n", content.Subject))
-	if content.IsHTML {
-		buf.WriteString("MIME-Version: 1.0\r\n")
-		buf.WriteString("Content-Type: text/html; charset=\"UTF-8\"\r\n")
-	} else {
-		buf.WriteString("Content-Type: text/plain; charset=\"UTF-8\"\r\n")
-	}
-	buf.WriteString("\r\n")
-	buf.WriteString(content.Body)
+	buf.WriteString(fmt.Sprintf("Subject: %s\r\n", mime.QEncoding.Encode("UTF-8", content.Subject)))
+	buf.WriteString(fmt.Sprintf("Date: %s\r\n", time.Now().Format(time.RFC1123Z)))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+
+	if content.HTML == "" || content.Body == "" {
+		contentType, body := "text/plain", content.Body
+		if content.IsHTML {
+			contentType = "text/html"
+		}
+		if content.HTML != "" {
+			contentType, body = "text/html", content.HTML
+		}
+		buf.WriteString(fmt.Sprintf("Content-Type: %s; charset=\"UTF-8\"\r\n", contentType))
+		buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+		if err := writeQuotedPrintable(&buf, body); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	writer := multipart.NewWriter(&buf)
+	buf.WriteString(fmt.Sprintf("Content-Type: multipart/alternative; boundary=%q\r\n\r\n", writer.Boundary()))
+	// Clients render the last part they understand, so plain text goes first.
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain", content.Body},
+		{"text/html", content.HTML},
+	} {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", part.contentType+`; charset="UTF-8"`)
+		header.Set("Content-Transfer-Encoding", "quoted-printable")
+		w, err := writer.CreatePart(header)
+		if err != nil {
+			return nil, fmt.Errorf("email build failed: %w", err)
+		}
+		if err := writeQuotedPrintable(w, part.body); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("email build failed: %w", err)
+	}
 	return buf.Bytes(), nil
 }
 
+// writeQuotedPrintable encodes body as quoted-printable; line breaks become CRLF.
+func writeQuotedPrintable(w io.Writer, body string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(body)); err != nil {
+		return fmt.Errorf("email build failed: %w", err)
+	}
+	if err := qp.Close(); err != nil {
+		return fmt.Errorf("email build failed: %w", err)
+	}
+	return nil
+}
+
 // doSend performs the SMTP transaction after a connection is established.
 func doSend(client *smtp.Client, sender string, to []string, auth smtp.Auth, msg []byte) error {
 	if auth != nil {
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"blog_api/src/config"
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	htmlTemplate "html/template"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	textTemplate "text/template"
+)
+
+// 邮件模板：
+//   - <name>.txt  纯文本正文（text/template），必须用 {{define "subject"}} 定义主题
+//   - <name>.html HTML 正文（html/template），可选；与 layout.html 一起解析，可以引用其中的 {{template "layout" .}}
+//
+// 语言变体命名为 <name>.<lang>.txt / <name>.<lang>.html，例如 verify_code.en.txt。
+// 按 请求语言（en-US）→ 主语言（en）→ email_conf.language → 无语言后缀 的顺序匹配，
+// 同一语言先查找 <config_path>/email_templates 覆盖目录，再使用内置模板。
+// 覆盖按文件生效：只覆盖 .txt 时仍会附带内置的 HTML 正文，放一个空的 <name>.html 可以只发送纯文本。
+// 模板在每次发送时读取，修改覆盖目录后无需重启。
+
+//go:embed email_templates
+var defaultEmailTemplates embed.FS
+
+// EmailTemplateDir 覆盖模板所在的子目录
+const EmailTemplateDir = "email_templates"
+
+// 内置的邮件模板名称
+const (
+	EmailTemplateVerifyCode           = "verify_code"
+	EmailTemplateFriendApplyAdmin     = "friend_application_admin"
+	EmailTemplateFriendApplyApplicant = "friend_application_applicant"
+)
+
+// EmailTemplateData 模板数据，模板中可以通过 .Site / .SiteURL 引用站点信息
+type EmailTemplateData map[string]interface{}
+
+// RenderEmail 按语言渲染邮件模板，返回主题、纯文本正文以及可选的 HTML 正文
+func RenderEmail(name, lang string, data EmailTemplateData) (EmailContent, error) {
+	cfg := config.GetConfig()
+	if data == nil {
+		data = EmailTemplateData{}
+	}
+	if _, ok := data["Site"]; !ok {
+		data["Site"] = cfg.Feed.Title
+	}
+	if _, ok := data["SiteURL"]; !ok {
+		data["SiteURL"] = cfg.Feed.SiteURL
+	}
+	langs := emailLanguages(lang, cfg.Email.Language)
+	dirs := []fs.FS{os.DirFS(filepath.Join(cfg.ConfigPath, EmailTemplateDir)), mustSub(defaultEmailTemplates, EmailTemplateDir)}
+
+	textSource, textFile, err := findEmailTemplate(dirs, name, "txt", langs)
+	if err != nil {
+		return EmailContent{}, err
+	}
+	textTmpl, err := textTemplate.New(textFile).Parse(textSource)
+	if err != nil {
+		return EmailContent{}, fmt.Errorf("could not parse email template %s: %w", textFile, err)
+	}
+	if textTmpl.Lookup("subject") == nil {
+		return EmailContent{}, fmt.Errorf("email template %s does not define a subject", textFile)
+	}
+
+	var subject, body bytes.Buffer
+	if err := textTmpl.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return EmailContent{}, fmt.Errorf("could not render email subject %s: %w", textFile, err)
+	}
+	if err := textTmpl.Execute(&body, data); err != nil {
+		return EmailContent{}, fmt.Errorf("could not render email template %s: %w", textFile, err)
+	}
+	content := EmailContent{
+		Subject: strings.Join(strings.Fields(subject.String()), " "),
+		Body:    strings.TrimSpace(body.String()) + "\n",
+	}
+
+	htmlSource, htmlFile, err := findEmailTemplate(dirs, name, "html", langs)
+	if errors.Is(err, fs.ErrNotExist) || (err == nil && strings.TrimSpace(htmlSource) == "") {
+		return content, nil
+	}
+	if err != nil {
+		return EmailContent{}, err
+	}
+	htmlTmpl := htmlTemplate.New(htmlFile)
+	if layout, layoutFile, err := findEmailTemplate(dirs, "layout", "html", langs); err == nil {
+		if _, err := htmlTmpl.New(layoutFile).Parse(layout); err != nil {
+			return EmailContent{}, fmt.Errorf("could not parse email template %s: %w", layoutFile, err)
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return EmailContent{}, err
+	}
+	if _, err := htmlTmpl.Parse(htmlSource); err != nil {
+		return EmailContent{}, fmt.Errorf("could not parse email template %s: %w", htmlFile, err)
+	}
+	var html bytes.Buffer
+	if err := htmlTmpl.ExecuteTemplate(&html, htmlFile, data); err != nil {
+		return EmailContent{}, fmt.Errorf("could not render email template %s: %w", htmlFile, err)
+	}
+	content.HTML = strings.TrimSpace(html.String())
+	return content, nil
+}
+
+// findEmailTemplate 返回第一个匹配的模板内容与文件名，都不存在时返回 fs.ErrNotExist
+func findEmailTemplate(dirs []fs.FS, name, ext string, langs []string) (string, string, error) {
+	for _, lang := range langs {
+		for _, dir := range dirs {
+			file := name + "." + ext
+			if lang != "" {
+				file = name + "." + lang + "." + ext
+			}
+			data, err := fs.ReadFile(dir, file)
+			if err == nil {
+				return string(data), file, nil
+			}
+			if !errors.Is(err, fs.ErrNotExist) {
+				return "", "", fmt.Errorf("could not read email template %s: %w", file, err)
+			}
+		}
+	}
+	return "", "", fmt.Errorf("email template %s.%s: %w", name, ext, fs.ErrNotExist)
+}
+
+// emailLanguages 返回依次尝试的语言后缀，最后一项为空（无后缀的默认模板）
+func emailLanguages(lang, fallback string) []string {
+	langs := []string{}
+	add := func(tag string) {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || strings.ContainsAny(tag, `/\.`) {
+			return
+		}
+		for _, existing := range langs {
+			if strings.EqualFold(existing, tag) {
+				return
+			}
+		}
+		langs = append(langs, tag)
+	}
+	for _, tag := range []string{lang, fallback} {
+		add(tag)
+		if base, _, ok := strings.Cut(tag, "-"); ok {
+			add(base)
+		}
+	}
+	return append(langs, "")
+}
+
+// ParseAcceptLanguage 返回 Accept-Language 请求头中优先级最高的语言，如 "en-US,en;q=0.9" 返回 "en-US"
+func ParseAcceptLanguage(header string) string {
+	best, bestQ := "", -1.0
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if _, err := fmt.Sscanf(value, "%g", &q); err != nil {
+				continue
+			}
+		}
+		if q > bestQ {
+			best, bestQ = tag, q
+		}
+	}
+	return best
+}
+
+func mustSub(fsys fs.FS, dir string) fs.FS {
+	sub, err := fs.Sub(fsys, dir)
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
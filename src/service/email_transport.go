@@ -0,0 +1,136 @@
+package service
+
+import (
+	"blog_api/src/model"
+	"fmt"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// EmailTransport delivers a fully built message.
+// Transports are selected by email_conf.transport; SetEmailTransport replaces them, e.g. in tests.
+type EmailTransport interface {
+	Send(sender string, to []string, msg []byte) error
+}
+
+var (
+	transportMu       sync.Mutex
+	transportOverride EmailTransport
+
+	sinkSeq atomic.Uint64
+)
+
+// SetEmailTransport makes every email go through t instead of the configured transport.
+// Passing nil restores the configured transport.
+func SetEmailTransport(t EmailTransport) {
+	transportMu.Lock()
+	transportOverride = t
+	transportMu.Unlock()
+}
+
+// NewEmailTransport returns the transport configured in conf.
+func NewEmailTransport(conf model.EmailConf) (EmailTransport, error) {
+	transportMu.Lock()
+	override := transportOverride
+	transportMu.Unlock()
+	if override != nil {
+		return override, nil
+	}
+
+	switch conf.Transport {
+	case "", "smtp":
+		if conf.Host == "" {
+			return nil, fmt.Errorf("email host is required")
+		}
+		if conf.Port == 0 {
+			return nil, fmt.Errorf("email port is required")
+		}
+		if conf.UserName == "" {
+			return nil, fmt.Errorf("email username is required")
+		}
+		return smtpTransport{conf: conf}, nil
+	case "file":
+		return fileTransport{dir: conf.SinkPath}, nil
+	case "maildir":
+		return maildirTransport{dir: conf.SinkPath}, nil
+	default:
+		return nil, fmt.Errorf("unknown email transport %q", conf.Transport)
+	}
+}
+
+// smtpTransport sends through the configured SMTP server.
+type smtpTransport struct {
+	conf model.EmailConf
+}
+
+func (t smtpTransport) Send(sender string, to []string, msg []byte) error {
+	addr := fmt.Sprintf("%s:%d", t.conf.Host, t.conf.Port)
+	auth := smtp.PlainAuth("", t.conf.UserName, t.conf.Password, t.conf.Host)
+
+	if t.conf.Port == 465 {
+		return sendWithImplicitTLS(addr, t.conf.Host, sender, to, auth, msg)
+	}
+	return sendWithSTARTTLS(addr, t.conf.Host, sender, to, auth, msg)
+}
+
+// fileTransport writes each message to <dir>/<time>_<seq>.eml.
+type fileTransport struct {
+	dir string
+}
+
+func (t fileTransport) Send(sender string, to []string, msg []byte) error {
+	if err := os.MkdirAll(t.dir, 0700); err != nil {
+		return fmt.Errorf("email sink mkdir failed: %w", err)
+	}
+	name := fmt.Sprintf("%s_%d.eml", time.Now().Format("20060102T150405.000000000"), sinkSeq.Add(1))
+	return writeFileAtomic(filepath.Join(t.dir, name), envelope(sender, to, msg))
+}
+
+// maildirTransport delivers into a Maildir (tmp/ then new/), readable by most mail clients.
+type maildirTransport struct {
+	dir string
+}
+
+func (t maildirTransport) Send(sender string, to []string, msg []byte) error {
+	for _, sub := range []string{"tmp", "new", "cur"} {
+		if err := os.MkdirAll(filepath.Join(t.dir, sub), 0700); err != nil {
+			return fmt.Errorf("email maildir mkdir failed: %w", err)
+		}
+	}
+	host, _ := os.Hostname()
+	host = strings.NewReplacer("/", "\\057", ":", "\\072").Replace(host)
+	name := fmt.Sprintf("%d.M%dP%dQ%d.%s", time.Now().Unix(), time.Now().Nanosecond()/1000, os.Getpid(), sinkSeq.Add(1), host)
+
+	tmp := filepath.Join(t.dir, "tmp", name)
+	if err := os.WriteFile(tmp, envelope(sender, to, msg), 0600); err != nil {
+		return fmt.Errorf("email maildir write failed: %w", err)
+	}
+	if err := os.Rename(tmp, filepath.Join(t.dir, "new", name)); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("email maildir deliver failed: %w", err)
+	}
+	return nil
+}
+
+// envelope prefixes msg with the SMTP envelope, which is otherwise lost when writing to disk.
+func envelope(sender string, to []string, msg []byte) []byte {
+	header := fmt.Sprintf("Return-Path: <%s>\r\nX-Envelope-To: %s\r\n", sender, strings.Join(to, ", "))
+	return append([]byte(header), msg...)
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("email sink write failed: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("email sink write failed: %w", err)
+	}
+	return nil
+}
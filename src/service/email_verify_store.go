@@ -2,10 +2,11 @@ package service
 
 import (
 	"crypto/rand"
+	"crypto/subtle"
 	"encoding/hex"
 	"fmt"
+	"log"
 	"math/big"
-	"sync"
 	"time"
 )
 
@@ -14,28 +15,6 @@ const (
 	defaultEmailTokenTTLSeconds = 86400
 )
 
-type emailCodeEntry struct {
-	code      string
-	expiresAt int64
-}
-
-type emailTokenEntry struct {
-	email     string
-	expiresAt int64
-}
-
-// EmailVerifyStore keeps short-lived email verification codes and tokens in memory.
-type EmailVerifyStore struct {
-	mu     sync.Mutex
-	codes  map[string]emailCodeEntry
-	tokens map[string]emailTokenEntry
-}
-
-var emailVerifyStore = &EmailVerifyStore{
-	codes:  make(map[string]emailCodeEntry),
-	tokens: make(map[string]emailTokenEntry),
-}
-
 // EmailCodeTTLSeconds returns the default TTL for email verification codes.
 func EmailCodeTTLSeconds() int {
 	return defaultEmailCodeTTLSeconds
@@ -46,7 +25,7 @@ func EmailTokenTTLSeconds() int {
 	return defaultEmailTokenTTLSeconds
 }
 
-// IssueEmailVerifyCode creates a new verification code for the given email.
+// IssueEmailVerifyCode creates a new verification code for the given email, replacing any earlier one.
 func IssueEmailVerifyCode(email string) (string, int64, error) {
 	code, err := generateEmailCode()
 	if err != nil {
@@ -54,35 +33,31 @@ func IssueEmailVerifyCode(email string) (string, int64, error) {
 	}
 	expiresAt := time.Now().Add(defaultEmailCodeTTLSeconds * time.Second).Unix()
 
-	emailVerifyStore.mu.Lock()
-	emailVerifyStore.codes[email] = emailCodeEntry{
-		code:      code,
-		expiresAt: expiresAt,
+	if err := currentTokenStore().Put(tokenKindEmailCode, email, code, expiresAt); err != nil {
+		return "", 0, err
 	}
-	emailVerifyStore.cleanupLocked(time.Now().Unix())
-	emailVerifyStore.mu.Unlock()
-
 	return code, expiresAt, nil
 }
 
 // ValidateEmailVerifyCode verifies and consumes a verification code for the email.
+// A wrong code leaves the stored one in place.
 func ValidateEmailVerifyCode(email, code string) bool {
-	now := time.Now().Unix()
-	emailVerifyStore.mu.Lock()
-	defer emailVerifyStore.mu.Unlock()
-
-	entry, ok := emailVerifyStore.codes[email]
-	if !ok || entry.expiresAt <= now {
-		if ok {
-			delete(emailVerifyStore.codes, email)
-		}
+	store := currentTokenStore()
+	stored, ok, err := store.Get(tokenKindEmailCode, email)
+	if err != nil {
+		log.Printf("[token][ERR] 校验邮箱验证码失败: %v", err)
+		return false
+	}
+	if !ok || subtle.ConstantTimeCompare([]byte(stored), []byte(code)) != 1 {
 		return false
 	}
-	if entry.code != code {
+
+	taken, ok, err := store.Take(tokenKindEmailCode, email)
+	if err != nil {
+		log.Printf("[token][ERR] 校验邮箱验证码失败: %v", err)
 		return false
 	}
-	delete(emailVerifyStore.codes, email)
-	return true
+	return ok && taken == code
 }
 
 // IssueEmailToken creates a new short-lived token bound to the email.
@@ -93,61 +68,30 @@ func IssueEmailToken(email string) (string, int64, error) {
 	}
 	expiresAt := time.Now().Add(defaultEmailTokenTTLSeconds * time.Second).Unix()
 
-	emailVerifyStore.mu.Lock()
-	emailVerifyStore.tokens[token] = emailTokenEntry{
-		email:     email,
-		expiresAt: expiresAt,
+	if err := currentTokenStore().Put(tokenKindEmail, hashTokenKey(token), email, expiresAt); err != nil {
+		return "", 0, err
 	}
-	emailVerifyStore.cleanupLocked(time.Now().Unix())
-	emailVerifyStore.mu.Unlock()
-
 	return token, expiresAt, nil
 }
 
 // ConsumeEmailToken validates and consumes a token, returning the bound email.
 func ConsumeEmailToken(token string) (string, bool) {
-	now := time.Now().Unix()
-	emailVerifyStore.mu.Lock()
-	defer emailVerifyStore.mu.Unlock()
-
-	entry, ok := emailVerifyStore.tokens[token]
-	if !ok || entry.expiresAt <= now {
-		if ok {
-			delete(emailVerifyStore.tokens, token)
-		}
+	email, ok, err := currentTokenStore().Take(tokenKindEmail, hashTokenKey(token))
+	if err != nil {
+		log.Printf("[token][ERR] 校验邮箱令牌失败: %v", err)
 		return "", false
 	}
-	delete(emailVerifyStore.tokens, token)
-	return entry.email, true
+	return email, ok
 }
 
 // ValidateEmailToken validates a token without consuming it.
 func ValidateEmailToken(token string) (string, bool) {
-	now := time.Now().Unix()
-	emailVerifyStore.mu.Lock()
-	defer emailVerifyStore.mu.Unlock()
-
-	entry, ok := emailVerifyStore.tokens[token]
-	if !ok || entry.expiresAt <= now {
-		if ok {
-			delete(emailVerifyStore.tokens, token)
-		}
+	email, ok, err := currentTokenStore().Get(tokenKindEmail, hashTokenKey(token))
+	if err != nil {
+		log.Printf("[token][ERR] 校验邮箱令牌失败: %v", err)
 		return "", false
 	}
-	return entry.email, true
-}
-
-func (s *EmailVerifyStore) cleanupLocked(now int64) {
-	for email, entry := range s.codes {
-		if entry.expiresAt <= now {
-			delete(s.codes, email)
-		}
-	}
-	for token, entry := range s.tokens {
-		if entry.expiresAt <= now {
-			delete(s.tokens, token)
-		}
-	}
+	return email, ok
 }
 
 func generateEmailCode() (string, error) {
@@ -0,0 +1,150 @@
+package fcircleService
+
+import (
+	"blog_api/src/model"
+	friendsRepositories "blog_api/src/repositories/friend"
+	feedService "blog_api/src/service/feed"
+	"bytes"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidList 表示无法识别的友链列表
+var ErrInvalidList = errors.New("invalid friend list")
+
+// containerKeys 依次尝试的列表字段：
+// friends（Friend-Circle-Lite / fcircle friend.json）、SETTINGS_FRIENDS_LINKS.list（hexo-circle-of-friends fc_settings.yaml）、
+// link_list（Butterfly 等主题的 link.yml）、friend_links_conf.website（本项目 friend_list.json）
+var containerKeys = []string{"friends", "SETTINGS_FRIENDS_LINKS", "list", "link_list", "friend_links_conf", "website", "links", "data"}
+
+// Import 解析友链列表并按地址合并到 friend_link / friend_rss
+func Import(db *gorm.DB, data []byte, opts model.ImportOptions) (*model.ImportReport, error) {
+	entries, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	report := model.NewImportReport(opts.DryRun)
+	if err := friendsRepositories.ImportFriendLinks(db, entries, opts, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// Parse 读取 fcircle 风格的友链列表（JSON 或 YAML）或 OPML 订阅列表
+// 列表条目可以是 [name, link, avatar, rss 后缀] 数组，也可以是包含 name/link/avatar/descr 等字段的对象
+func Parse(data []byte) ([]model.FriendImportEntry, error) {
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	if len(trimmed) == 0 {
+		return nil, fmt.Errorf("%w: empty file", ErrInvalidList)
+	}
+	if trimmed[0] == '<' {
+		entries, err := feedService.ParseOPML(trimmed)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidList, err)
+		}
+		return entries, nil
+	}
+
+	// YAML 是 JSON 的超集，两种格式用同一个解析器
+	var doc interface{}
+	if err := yaml.Unmarshal(trimmed, &doc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidList, err)
+	}
+	entries := []model.FriendImportEntry{}
+	if !collect(doc, &entries) {
+		return nil, fmt.Errorf("%w: no friend list found", ErrInvalidList)
+	}
+	return entries, nil
+}
+
+// collect 递归查找友链条目，找到列表时返回 true
+func collect(node interface{}, entries *[]model.FriendImportEntry) bool {
+	switch value := node.(type) {
+	case []interface{}:
+		for _, item := range value {
+			switch item := item.(type) {
+			case []interface{}:
+				*entries = append(*entries, arrayEntry(item))
+			case map[string]interface{}:
+				if isEntry(item) {
+					*entries = append(*entries, objectEntry(item))
+				} else {
+					// 分组，如 link.yml 的 class_name + link_list
+					collect(item, entries)
+				}
+			}
+		}
+		return true
+	case map[string]interface{}:
+		for _, key := range containerKeys {
+			if child, ok := value[key]; ok && collect(child, entries) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// arrayEntry 解析 [name, link, avatar, rss 后缀] 格式的条目
+func arrayEntry(item []interface{}) model.FriendImportEntry {
+	field := func(i int) string {
+		if i < len(item) && item[i] != nil {
+			return strings.TrimSpace(fmt.Sprint(item[i]))
+		}
+		return ""
+	}
+	entry := model.FriendImportEntry{Name: field(0), Link: field(1), Avatar: field(2)}
+	entry.RssURL = resolveFeedURL(field(3), entry.Link)
+	return entry
+}
+
+func isEntry(item map[string]interface{}) bool {
+	return firstString(item, "link", "url", "href", "website_url", "htmlUrl") != ""
+}
+
+func objectEntry(item map[string]interface{}) model.FriendImportEntry {
+	entry := model.FriendImportEntry{
+		Name:        firstString(item, "name", "title", "website_name"),
+		Link:        firstString(item, "link", "url", "href", "website_url", "htmlUrl"),
+		Avatar:      firstString(item, "avatar", "icon", "logo", "img", "image", "website_icon_url"),
+		Description: firstString(item, "descr", "description", "desc", "info", "intro", "siteDesc"),
+	}
+	entry.RssURL = resolveFeedURL(firstString(item, "rss", "feed", "rss_url", "feed_url", "xmlUrl", "suffix"), entry.Link)
+	return entry
+}
+
+func firstString(item map[string]interface{}, keys ...string) string {
+	for _, key := range keys {
+		if value, ok := item[key].(string); ok && strings.TrimSpace(value) != "" {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+// resolveFeedURL 将订阅后缀（如 atom.xml）解析为相对友链地址的完整地址
+func resolveFeedURL(feed, link string) string {
+	if feed == "" {
+		return ""
+	}
+	ref, err := url.Parse(feed)
+	if err != nil {
+		return feed
+	}
+	if ref.IsAbs() {
+		return feed
+	}
+	base, err := url.Parse(link)
+	if err != nil || base.Host == "" {
+		return feed
+	}
+	if !strings.HasSuffix(base.Path, "/") {
+		base.Path += "/"
+	}
+	return base.ResolveReference(&url.URL{Path: strings.TrimPrefix(ref.Path, "/"), RawQuery: ref.RawQuery}).String()
+}
@@ -0,0 +1,318 @@
+package feedService
+
+import (
+	"blog_api/src/model"
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"time"
+)
+
+const generatorName = "blog_api"
+
+// Meta 描述聚合订阅的频道级信息
+type Meta struct {
+	Title       string
+	Description string
+	SiteURL     string
+	FeedURL     string
+	Updated     time.Time
+}
+
+type rssDocument struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	DcNS    string     `xml:"xmlns:dc,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	AtomLink      *atomLink `xml:"atom:link,omitempty"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Generator     string    `xml:"generator"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string     `xml:"title"`
+	Link        string     `xml:"link,omitempty"`
+	GUID        rssGUID    `xml:"guid"`
+	Description string     `xml:"description"`
+	Creator     string     `xml:"dc:creator,omitempty"`
+	PubDate     string     `xml:"pubDate,omitempty"`
+	Source      *rssSource `xml:"source,omitempty"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssSource struct {
+	URL   string `xml:"url,attr"`
+	Value string `xml:",chardata"`
+}
+
+type atomFeed struct {
+	XMLName   xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title     string      `xml:"title"`
+	Subtitle  string      `xml:"subtitle,omitempty"`
+	ID        string      `xml:"id"`
+	Updated   string      `xml:"updated"`
+	Links     []atomLink  `xml:"link"`
+	Author    atomPerson  `xml:"author"`
+	Generator string      `xml:"generator"`
+	Entries   []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+	URI  string `xml:"uri,omitempty"`
+}
+
+type atomEntry struct {
+	Title     string      `xml:"title"`
+	ID        string      `xml:"id"`
+	Updated   string      `xml:"updated"`
+	Published string      `xml:"published"`
+	Link      *atomLink   `xml:"link,omitempty"`
+	Author    atomPerson  `xml:"author"`
+	Summary   string      `xml:"summary,omitempty"`
+	Source    *atomSource `xml:"source,omitempty"`
+}
+
+type atomSource struct {
+	Title string    `xml:"title"`
+	Link  *atomLink `xml:"link,omitempty"`
+}
+
+type jsonFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url,omitempty"`
+	FeedURL     string         `json:"feed_url,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	ID            string           `json:"id"`
+	URL           string           `json:"url,omitempty"`
+	Title         string           `json:"title,omitempty"`
+	ContentText   string           `json:"content_text"`
+	DatePublished string           `json:"date_published,omitempty"`
+	DateModified  string           `json:"date_modified,omitempty"`
+	Authors       []jsonFeedAuthor `json:"authors,omitempty"`
+}
+
+type jsonFeedAuthor struct {
+	Name string `json:"name,omitempty"`
+	URL  string `json:"url,omitempty"`
+}
+
+// BuildRSS 渲染 RSS 2.0 格式的聚合订阅
+func BuildRSS(meta Meta, posts []model.FeedPost) ([]byte, error) {
+	channel := rssChannel{
+		Title:       meta.Title,
+		Link:        meta.SiteURL,
+		Description: meta.Description,
+		Generator:   generatorName,
+		Items:       make([]rssItem, 0, len(posts)),
+	}
+	if channel.Link == "" {
+		channel.Link = meta.FeedURL
+	}
+	if channel.Description == "" {
+		channel.Description = meta.Title
+	}
+	if meta.FeedURL != "" {
+		channel.AtomLink = &atomLink{Href: meta.FeedURL, Rel: "self", Type: "application/rss+xml"}
+	}
+	if !meta.Updated.IsZero() {
+		channel.LastBuildDate = meta.Updated.UTC().Format(time.RFC1123Z)
+	}
+
+	for _, post := range posts {
+		item := rssItem{
+			Title:       post.Title,
+			Link:        post.Link,
+			GUID:        rssGUID{IsPermaLink: post.Link != "", Value: postID(post)},
+			Description: post.Description,
+			Creator:     postAuthor(post),
+			PubDate:     time.Unix(post.Time, 0).UTC().Format(time.RFC1123Z),
+		}
+		if post.SiteURL != "" {
+			item.Source = &rssSource{URL: post.SiteURL, Value: sourceTitle(post)}
+		}
+		channel.Items = append(channel.Items, item)
+	}
+
+	doc := rssDocument{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		DcNS:    "http://purl.org/dc/elements/1.1/",
+		Channel: channel,
+	}
+	return marshalXML(doc)
+}
+
+// BuildAtom 渲染 Atom 1.0 格式的聚合订阅
+func BuildAtom(meta Meta, posts []model.FeedPost) ([]byte, error) {
+	feed := atomFeed{
+		Title:     meta.Title,
+		Subtitle:  meta.Description,
+		ID:        meta.FeedURL,
+		Updated:   meta.Updated.UTC().Format(time.RFC3339),
+		Author:    atomPerson{Name: meta.Title, URI: meta.SiteURL},
+		Generator: generatorName,
+		Entries:   make([]atomEntry, 0, len(posts)),
+	}
+	if feed.ID == "" {
+		feed.ID = meta.SiteURL
+	}
+	if meta.FeedURL != "" {
+		feed.Links = append(feed.Links, atomLink{Href: meta.FeedURL, Rel: "self", Type: "application/atom+xml"})
+	}
+	if meta.SiteURL != "" {
+		feed.Links = append(feed.Links, atomLink{Href: meta.SiteURL, Rel: "alternate", Type: "text/html"})
+	}
+
+	for _, post := range posts {
+		published := time.Unix(post.Time, 0).UTC().Format(time.RFC3339)
+		updated := published
+		if post.UpdatedAt > post.Time {
+			updated = time.Unix(post.UpdatedAt, 0).UTC().Format(time.RFC3339)
+		}
+		entry := atomEntry{
+			Title:     post.Title,
+			ID:        postID(post),
+			Updated:   updated,
+			Published: published,
+			Author:    atomPerson{Name: postAuthor(post), URI: post.SiteURL},
+			Summary:   post.Description,
+		}
+		if entry.Author.Name == "" {
+			entry.Author.Name = meta.Title
+		}
+		if post.Link != "" {
+			entry.Link = &atomLink{Href: post.Link, Rel: "alternate", Type: "text/html"}
+		}
+		if post.SiteURL != "" {
+			entry.Source = &atomSource{
+				Title: sourceTitle(post),
+				Link:  &atomLink{Href: post.SiteURL, Rel: "alternate", Type: "text/html"},
+			}
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+
+	return marshalXML(feed)
+}
+
+// BuildJSONFeed 渲染 JSON Feed 1.1 格式的聚合订阅
+func BuildJSONFeed(meta Meta, posts []model.FeedPost) ([]byte, error) {
+	feed := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       meta.Title,
+		HomePageURL: meta.SiteURL,
+		FeedURL:     meta.FeedURL,
+		Description: meta.Description,
+		Items:       make([]jsonFeedItem, 0, len(posts)),
+	}
+
+	for _, post := range posts {
+		item := jsonFeedItem{
+			ID:            postID(post),
+			URL:           post.Link,
+			Title:         post.Title,
+			ContentText:   post.Description,
+			DatePublished: time.Unix(post.Time, 0).UTC().Format(time.RFC3339),
+		}
+		if post.UpdatedAt > post.Time {
+			item.DateModified = time.Unix(post.UpdatedAt, 0).UTC().Format(time.RFC3339)
+		}
+		if author := postAuthor(post); author != "" {
+			item.Authors = []jsonFeedAuthor{{Name: author, URL: post.SiteURL}}
+		}
+		feed.Items = append(feed.Items, item)
+	}
+
+	return json.Marshal(feed)
+}
+
+// ComputeETag 根据输出格式、订阅元信息和文章内容计算 ETag
+// meta.Updated 由文章推导，不参与计算
+func ComputeETag(format string, meta Meta, posts []model.FeedPost) string {
+	h := sha1.New()
+	h.Write([]byte(format))
+	fmt.Fprintf(h, "|%q|%q|%q|%q", meta.Title, meta.Description, meta.SiteURL, meta.FeedURL)
+	for _, post := range posts {
+		fmt.Fprintf(h, "|%d:%d:%d:%s:%s:%s:%s", post.ID, post.Time, post.UpdatedAt, post.Title, post.Link, post.Author, post.Description)
+	}
+	return `"` + hex.EncodeToString(h.Sum(nil)) + `"`
+}
+
+// LastModified 返回文章列表中最新的发布或更新时间
+func LastModified(posts []model.FeedPost) time.Time {
+	var latest int64
+	for _, post := range posts {
+		if post.Time > latest {
+			latest = post.Time
+		}
+		if post.UpdatedAt > latest {
+			latest = post.UpdatedAt
+		}
+	}
+	if latest == 0 {
+		return time.Time{}
+	}
+	return time.Unix(latest, 0).UTC()
+}
+
+func marshalXML(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return nil, fmt.Errorf("could not encode feed: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func postID(post model.FeedPost) string {
+	if post.Link != "" {
+		return post.Link
+	}
+	return fmt.Sprintf("urn:blog-api:rss-post:%d", post.ID)
+}
+
+func postAuthor(post model.FeedPost) string {
+	if post.Author != "" {
+		return post.Author
+	}
+	if post.SiteName != "" {
+		return post.SiteName
+	}
+	return post.FeedName
+}
+
+func sourceTitle(post model.FeedPost) string {
+	if post.SiteName != "" {
+		return post.SiteName
+	}
+	return post.FeedName
+}
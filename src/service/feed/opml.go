@@ -0,0 +1,112 @@
+package feedService
+
+import (
+	"blog_api/src/model"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type opmlDocument struct {
+	XMLName xml.Name `xml:"opml"`
+	Version string   `xml:"version,attr"`
+	Head    opmlHead `xml:"head"`
+	Body    opmlBody `xml:"body"`
+}
+
+type opmlHead struct {
+	Title       string `xml:"title"`
+	DateCreated string `xml:"dateCreated,omitempty"`
+}
+
+type opmlBody struct {
+	Outlines []opmlOutline `xml:"outline"`
+}
+
+type opmlOutline struct {
+	Text        string        `xml:"text,attr"`
+	Title       string        `xml:"title,attr,omitempty"`
+	Type        string        `xml:"type,attr,omitempty"`
+	Description string        `xml:"description,attr,omitempty"`
+	XMLURL      string        `xml:"xmlUrl,attr,omitempty"`
+	HTMLURL     string        `xml:"htmlUrl,attr,omitempty"`
+	Outlines    []opmlOutline `xml:"outline"`
+}
+
+// BuildOPML 生成 OPML 2.0 订阅列表，每个订阅源一个条目，阅读器可一次性订阅全部友链
+func BuildOPML(meta Meta, feeds []model.OPMLFeed) ([]byte, error) {
+	doc := opmlDocument{
+		Version: "2.0",
+		Head: opmlHead{
+			Title:       meta.Title,
+			DateCreated: meta.Updated.UTC().Format(time.RFC1123Z),
+		},
+		Body: opmlBody{Outlines: make([]opmlOutline, 0, len(feeds))},
+	}
+	for _, feed := range feeds {
+		text := feed.SiteName
+		if text == "" {
+			text = feed.Name
+		}
+		doc.Body.Outlines = append(doc.Body.Outlines, opmlOutline{
+			Text:        text,
+			Title:       text,
+			Type:        "rss",
+			Description: feed.Description,
+			XMLURL:      feed.RssURL,
+			HTMLURL:     feed.SiteURL,
+		})
+	}
+
+	body, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("could not encode opml: %w", err)
+	}
+	return append([]byte(xml.Header), body...), nil
+}
+
+// ParseOPML 读取 OPML 订阅列表，每个带 xmlUrl 的条目对应一个友链订阅源
+// 条目没有 htmlUrl 时以订阅地址的站点根目录作为友链地址
+func ParseOPML(data []byte) ([]model.FriendImportEntry, error) {
+	var doc opmlDocument
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("could not parse opml: %w", err)
+	}
+
+	entries := []model.FriendImportEntry{}
+	var walk func(outlines []opmlOutline)
+	walk = func(outlines []opmlOutline) {
+		for _, outline := range outlines {
+			if outline.XMLURL != "" {
+				name := strings.TrimSpace(outline.Title)
+				if name == "" {
+					name = strings.TrimSpace(outline.Text)
+				}
+				link := strings.TrimSpace(outline.HTMLURL)
+				if link == "" {
+					link = siteRoot(outline.XMLURL)
+				}
+				entries = append(entries, model.FriendImportEntry{
+					Name:        name,
+					Link:        link,
+					Description: strings.TrimSpace(outline.Description),
+					RssURL:      strings.TrimSpace(outline.XMLURL),
+				})
+			}
+			walk(outline.Outlines)
+		}
+	}
+	walk(doc.Body.Outlines)
+	return entries, nil
+}
+
+// siteRoot 返回地址的站点根目录，如 https://example.com/
+func siteRoot(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host + "/"
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"blog_api/src/config"
+	"blog_api/src/model"
+	friendsRepositories "blog_api/src/repositories/friend"
+	crawlerService "blog_api/src/service/crawler"
+	jobService "blog_api/src/service/job"
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SubmitFriendLinkApplication 创建待审核的友链与申请记录，执行预检后通知管理员与申请人
+// 预检失败不影响提交，管理员可稍后重新检查
+// lang 为申请人的语言（如 Accept-Language），用于之后的通知邮件
+func SubmitFriendLinkApplication(ctx context.Context, db *gorm.DB, link model.FriendWebsite, lang string) (model.FriendLinkApplication, error) {
+	app, err := friendsRepositories.CreateFriendLinkApplication(db, link, lang)
+	if err != nil {
+		return app, err
+	}
+	log.Printf("[friend][apply] 收到友链申请 %d: %s (%s)", app.ID, app.Name, app.Link)
+
+	if checked, err := CheckFriendLinkApplication(ctx, db, app); err != nil {
+		log.Printf("[friend][apply][ERR] 预检友链申请 %d 失败: %v", app.ID, err)
+	} else {
+		app = checked
+	}
+	notifyFriendLinkApplication(db, app)
+	return app, nil
+}
+
+// CheckFriendLinkApplication 预检申请：站点能否访问、是否有指向本站的反链、是否已有同域名的友链
+func CheckFriendLinkApplication(ctx context.Context, db *gorm.DB, app model.FriendLinkApplication) (model.FriendLinkApplication, error) {
+	link := model.FriendWebsite{ID: app.FriendLinkID, Name: app.Name, Link: app.Link, FriendsPageURL: app.FriendsPageURL}
+	result := crawlerService.CrawlFriendLink(ctx, link)
+	if err := ctx.Err(); err != nil {
+		return app, err
+	}
+
+	check := model.FriendLinkApplicationCheck{
+		HTTPStatus:  result.HTTPStatus,
+		CheckError:  result.Error,
+		HasBacklink: result.Backlink,
+		CheckedAt:   time.Now().Unix(),
+	}
+	// robots.txt 禁止抓取时无法判断
+	if result.Status != "disallowed" {
+		reachable := result.Status == "survival"
+		check.Reachable = &reachable
+	}
+
+	duplicates, err := friendsRepositories.FindFriendLinksByDomain(db, app.Link, app.FriendLinkID)
+	if err != nil {
+		return app, err
+	}
+	check.DuplicateOf = duplicates
+
+	if err := friendsRepositories.SaveFriendLinkApplicationCheck(db, app.ID, check); err != nil {
+		return app, err
+	}
+	app.FriendLinkApplicationCheck = check
+	return app, nil
+}
+
+// ApproveFriendLinkApplication 通过申请，友链立即开始爬取
+func ApproveFriendLinkApplication(db *gorm.DB, app model.FriendLinkApplication, reason string) (model.FriendLinkApplication, error) {
+	if err := friendsRepositories.ApproveFriendLinkApplication(db, app, reason); err != nil {
+		return app, err
+	}
+	app.Status = "approved"
+	app.Reason = reason
+	app.ReviewedAt = time.Now().Unix()
+	log.Printf("[friend][apply] 友链申请 %d 已通过", app.ID)
+
+	if _, err := jobService.Trigger(jobService.FriendCrawl, app.FriendLinkID, "manual"); err != nil {
+		log.Printf("[friend][apply][ERR] 触发友链 %d 爬取失败: %v", app.FriendLinkID, err)
+	}
+	notifyFriendLinkApplication(db, app)
+	return app, nil
+}
+
+// RejectFriendLinkApplication 拒绝申请并删除待审核的友链
+func RejectFriendLinkApplication(db *gorm.DB, app model.FriendLinkApplication, reason string) (model.FriendLinkApplication, error) {
+	if err := friendsRepositories.RejectFriendLinkApplication(db, app, reason); err != nil {
+		return app, err
+	}
+	app.Status = "rejected"
+	app.Reason = reason
+	app.ReviewedAt = time.Now().Unix()
+	log.Printf("[friend][apply] 友链申请 %d 已拒绝: %s", app.ID, reason)
+
+	notifyFriendLinkApplication(db, app)
+	return app, nil
+}
+
+// notifyFriendLinkApplication 按申请当前状态分别通知管理员与申请人，邮件写入发件箱后在后台发送
+// 管理员邮件使用 email_conf.language，申请人邮件使用提交申请时的语言
+func notifyFriendLinkApplication(db *gorm.DB, app model.FriendLinkApplication) {
+	switch app.Status {
+	case "pending", "approved", "rejected":
+	default:
+		return
+	}
+	conf := config.GetConfig().Email
+	if conf.AdminAddress != "" {
+		sendTemplateEmail(db, []string{conf.AdminAddress}, EmailTemplateFriendApplyAdmin, "", friendApplicationEmailData(app))
+	} else {
+		log.Printf("[friend][apply] 未配置 email_conf.admin_address，跳过管理员通知: 友链申请 %d (%s)", app.ID, app.Status)
+	}
+	if app.Email != "" {
+		sendTemplateEmail(db, []string{app.Email}, EmailTemplateFriendApplyApplicant, app.Language, friendApplicationEmailData(app))
+	}
+}
+
+// friendApplicationEmailData 模板数据：.App 为申请记录，.Status 为当前状态，
+// .Reachable / .Backlink 为 yes、no 或 unknown，.Checked 表示是否完成预检
+func friendApplicationEmailData(app model.FriendLinkApplication) EmailTemplateData {
+	tristate := func(value *bool) string {
+		switch {
+		case value == nil:
+			return "unknown"
+		case *value:
+			return "yes"
+		default:
+			return "no"
+		}
+	}
+	return EmailTemplateData{
+		"App":       app,
+		"Status":    app.Status,
+		"Checked":   app.CheckedAt > 0,
+		"Reachable": tristate(app.Reachable),
+		"Backlink":  tristate(app.HasBacklink),
+	}
+}
+
+// sendTemplateEmail 渲染邮件模板并写入发件箱，失败只记录日志
+func sendTemplateEmail(db *gorm.DB, to []string, name, lang string, data EmailTemplateData) {
+	content, err := RenderEmail(name, lang, data)
+	if err != nil {
+		log.Printf("[email][ERR] 渲染邮件模板 %s 失败: %v", name, err)
+		return
+	}
+	if _, err := EnqueueEmail(db, name, to, content, 0); err != nil && !errors.Is(err, ErrEmailDisabled) {
+		log.Printf("[email][ERR] 邮件 %q 加入发件箱失败: %v", content.Subject, err)
+	}
+}
@@ -0,0 +1,395 @@
+package jobService
+
+import (
+	"blog_api/src/model"
+	jobRepositories "blog_api/src/repositories/job"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 已注册的任务名称
+const (
+	FriendCrawl     = "friend_crawl"
+	FriendDiedCheck = "friend_died_check"
+	RssParse        = "rss_parse"
+	RssPrune        = "rss_prune"
+	ImageCheck      = "image_check"
+	DBBackup        = "db_backup"
+)
+
+// maxItemErrors 每次运行最多保留的条目错误数
+const maxItemErrors = 100
+
+var (
+	// ErrUnknownJob 任务未注册
+	ErrUnknownJob = errors.New("unknown job")
+	// ErrTargetNotSupported 任务不支持针对单个条目运行
+	ErrTargetNotSupported = errors.New("job does not support a single target")
+	// ErrShuttingDown 服务正在关闭，不再接受新的运行
+	ErrShuttingDown = errors.New("shutting down")
+)
+
+// Func 任务的执行函数；target 为 0 表示处理全部条目
+type Func func(run *Run, target int) error
+
+type definition struct {
+	info model.JobInfo
+	fn   Func
+	slot chan struct{} // 同一任务同时只运行一次
+}
+
+// Run 一次任务运行，执行函数通过它上报进度与条目错误
+// 方法对 nil 接收者安全，直接调用任务函数时可传 nil
+type Run struct {
+	mu   sync.Mutex
+	data model.JobRun
+	done chan struct{} // 运行结束（或被跳过）时关闭
+}
+
+var (
+	mu          sync.Mutex
+	store       *gorm.DB
+	definitions = make(map[string]definition)
+	active      = make(map[string]*Run) // 排队中与运行中的任务，键为运行 ID
+
+	// baseCtx 所有运行的上下文，关闭服务时取消
+	baseCtx, cancelRuns = context.WithCancel(context.Background())
+)
+
+// Init 设置保存执行记录的数据库，并将上次进程退出时未结束的记录标记为失败
+func Init(db *gorm.DB) {
+	mu.Lock()
+	store = db
+	mu.Unlock()
+
+	if count, err := jobRepositories.FailInterruptedJobRuns(db); err != nil {
+		log.Printf("[Job] 标记中断的任务记录失败: %v", err)
+	} else if count > 0 {
+		log.Printf("[Job] 已将 %d 条未结束的任务记录标记为中断", count)
+	}
+}
+
+// Register 注册一个任务，targetable 表示是否支持针对单个条目运行
+func Register(name, description string, targetable bool, fn Func) {
+	mu.Lock()
+	defer mu.Unlock()
+	definitions[name] = definition{
+		info: model.JobInfo{Name: name, Description: description, Targetable: targetable},
+		fn:   fn,
+		slot: make(chan struct{}, 1),
+	}
+}
+
+// Jobs 返回已注册的任务，按名称排序
+func Jobs() []model.JobInfo {
+	mu.Lock()
+	defer mu.Unlock()
+	infos := make([]model.JobInfo, 0, len(definitions))
+	for _, def := range definitions {
+		infos = append(infos, def.info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// Trigger 在后台启动任务，立即返回运行记录
+// 同一任务正在运行时，定时与启动时触发的运行会被跳过，手动触发的运行排队等待
+func Trigger(name string, target int, trigger string) (model.JobRun, error) {
+	run, def, start, err := newRun(name, target, trigger)
+	if err != nil {
+		return model.JobRun{}, err
+	}
+	if start {
+		go execute(run, def)
+	}
+	return run.Snapshot(), nil
+}
+
+// Execute 同步执行任务，返回结束时的运行记录；重叠规则与 Trigger 相同
+func Execute(name string, target int, trigger string) (model.JobRun, error) {
+	run, def, start, err := newRun(name, target, trigger)
+	if err != nil {
+		return model.JobRun{}, err
+	}
+	if start {
+		execute(run, def)
+	}
+	return run.Snapshot(), nil
+}
+
+// GetRun 按 ID 查询运行记录，排队中与运行中的记录返回实时进度
+func GetRun(id string) (model.JobRun, bool, error) {
+	mu.Lock()
+	run, ok := active[id]
+	db := store
+	mu.Unlock()
+	if ok {
+		return run.Snapshot(), true, nil
+	}
+	if db == nil {
+		return model.JobRun{}, false, nil
+	}
+
+	data, err := jobRepositories.GetJobRunByID(db, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.JobRun{}, false, nil
+	}
+	if err != nil {
+		return model.JobRun{}, false, err
+	}
+	return data, true, nil
+}
+
+// ListRuns 分页返回执行记录（新的在前），job / state 为空表示不过滤
+func ListRuns(job, state string, page, pageSize int) ([]model.JobRun, int64, error) {
+	mu.Lock()
+	db := store
+	mu.Unlock()
+	if db == nil {
+		return []model.JobRun{}, 0, nil
+	}
+
+	runs, total, err := jobRepositories.QueryJobRuns(db, job, state, page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	// 数据库中只保存开始与结束时的状态，运行中的记录换成内存中的实时进度
+	mu.Lock()
+	for i := range runs {
+		if run, ok := active[runs[i].ID]; ok {
+			runs[i] = run.Snapshot()
+		}
+	}
+	mu.Unlock()
+	return runs, total, nil
+}
+
+// newRun 创建运行记录；返回的 start 为 false 表示该运行被跳过或已有相同的运行在排队
+func newRun(name string, target int, trigger string) (*Run, definition, bool, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	if baseCtx.Err() != nil {
+		return nil, definition{}, false, ErrShuttingDown
+	}
+	def, ok := definitions[name]
+	if !ok {
+		return nil, def, false, fmt.Errorf("%w: %s", ErrUnknownJob, name)
+	}
+	if target != 0 && !def.info.Targetable {
+		return nil, def, false, fmt.Errorf("%w: %s", ErrTargetNotSupported, name)
+	}
+
+	var busy *Run
+	for _, run := range active {
+		snapshot := run.Snapshot()
+		if snapshot.Job != name {
+			continue
+		}
+		// 相同的手动运行已在排队，不再重复排队
+		if trigger == "manual" && snapshot.State == "queued" && snapshot.Target == target {
+			return run, def, false, nil
+		}
+		busy = run
+	}
+
+	id, err := newRunID()
+	if err != nil {
+		return nil, def, false, err
+	}
+	now := time.Now().Unix()
+	run := &Run{data: model.JobRun{
+		ID:        id,
+		Job:       name,
+		Target:    target,
+		Trigger:   trigger,
+		State:     "queued",
+		Errors:    []model.JobItemError{},
+		CreatedAt: now,
+	}, done: make(chan struct{})}
+
+	start := true
+	if busy != nil && trigger != "manual" {
+		run.data.State = "skipped"
+		run.data.Error = fmt.Sprintf("previous run %s is still in progress", busy.data.ID)
+		run.data.FinishedAt = now
+		start = false
+		close(run.done)
+		log.Printf("[Job] 任务 %s 的上一次运行 (%s) 尚未结束，跳过本次%s触发", name, busy.data.ID, trigger)
+	} else {
+		active[id] = run
+	}
+
+	if store != nil {
+		if err := jobRepositories.CreateJobRun(store, &run.data); err != nil {
+			log.Printf("[Job] 保存任务记录失败: %v", err)
+		}
+	}
+	return run, def, start, nil
+}
+
+func execute(run *Run, def definition) {
+	defer func() {
+		mu.Lock()
+		delete(active, run.data.ID)
+		mu.Unlock()
+		close(run.done)
+	}()
+
+	// 等待同一任务的上一次运行结束，等待期间服务关闭则放弃
+	select {
+	case def.slot <- struct{}{}:
+	case <-baseCtx.Done():
+		run.finish(ErrShuttingDown)
+		return
+	}
+	defer func() { <-def.slot }()
+
+	run.mu.Lock()
+	run.data.State = "running"
+	run.data.StartedAt = time.Now().Unix()
+	run.mu.Unlock()
+	run.save()
+
+	run.finish(safeCall(def.fn, run, run.data.Target))
+}
+
+// finish 记录运行结果并保存；服务关闭导致提前结束的运行记为失败
+func (r *Run) finish(err error) {
+	if err == nil && baseCtx.Err() != nil {
+		err = fmt.Errorf("cancelled: %w", ErrShuttingDown)
+	}
+
+	r.mu.Lock()
+	r.data.FinishedAt = time.Now().Unix()
+	if err != nil {
+		r.data.State = "failed"
+		r.data.Error = err.Error()
+		log.Printf("[Job] 任务 %s (%s) 执行失败: %v", r.data.Job, r.data.ID, err)
+	} else {
+		r.data.State = "succeeded"
+	}
+	r.mu.Unlock()
+	r.save()
+}
+
+// Shutdown 取消所有运行并等待其结束，ctx 到期时返回 ctx 的错误
+// 之后的触发都会返回 ErrShuttingDown
+func Shutdown(ctx context.Context) error {
+	mu.Lock()
+	cancelRuns()
+	pending := make([]*Run, 0, len(active))
+	for _, run := range active {
+		pending = append(pending, run)
+	}
+	mu.Unlock()
+
+	for _, run := range pending {
+		select {
+		case <-run.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Context 返回运行的上下文，服务关闭时被取消；nil 接收者返回 context.Background()
+func (r *Run) Context() context.Context {
+	if r == nil {
+		return context.Background()
+	}
+	return baseCtx
+}
+
+// save 将当前状态写入数据库
+func (r *Run) save() {
+	mu.Lock()
+	db := store
+	mu.Unlock()
+	if db == nil {
+		return
+	}
+	snapshot := r.Snapshot()
+	if err := jobRepositories.SaveJobRun(db, &snapshot); err != nil {
+		log.Printf("[Job] 更新任务记录 %s 失败: %v", snapshot.ID, err)
+	}
+}
+
+// safeCall 执行任务函数，panic 时记为失败而不是让进程退出
+func safeCall(fn Func, run *Run, target int) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return fn(run, target)
+}
+
+func newRunID() (string, error) {
+	bytes := make([]byte, 8)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+// Snapshot 返回运行记录的副本
+func (r *Run) Snapshot() model.JobRun {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	snapshot := r.data
+	snapshot.Errors = append([]model.JobItemError{}, r.data.Errors...)
+	return snapshot
+}
+
+// SetTotal 设置需要处理的条目总数
+func (r *Run) SetTotal(total int) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	r.data.Total = total
+	r.mu.Unlock()
+}
+
+// ItemDone 记录一个条目处理成功
+func (r *Run) ItemDone() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	r.data.Done++
+	r.mu.Unlock()
+}
+
+// AddDone 记录批量处理成功的条目数
+func (r *Run) AddDone(n int) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	r.data.Done += n
+	r.mu.Unlock()
+}
+
+// ItemFailed 记录一个条目处理失败
+func (r *Run) ItemFailed(item string, err error) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.data.Failed++
+	if len(r.data.Errors) < maxItemErrors {
+		r.data.Errors = append(r.data.Errors, model.JobItemError{Item: item, Error: err.Error(), At: time.Now().Unix()})
+	}
+}
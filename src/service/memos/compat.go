@@ -0,0 +1,121 @@
+package memosService
+
+import (
+	"blog_api/src/model"
+	"fmt"
+	"mime"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const snippetRunes = 100
+
+// memosCreator 所有动态都归属于同一个作者
+const memosCreator = "users/1"
+
+// memos 的反应是逐条返回的，展开时限制每种表情与每条动态的条数，避免响应随点赞数无限增长
+const (
+	maxReactionsPerType = 20
+	maxReactions        = 100
+)
+
+var tagPattern = regexp.MustCompile(`(?:^|\s)#([^\s#]+)`)
+
+// ToMemo 将动态转换为 memos v1 的 Memo 结构，origin 用于把本地媒体地址补全为绝对地址
+func ToMemo(moment model.MomentWithMedia, origin string) model.MemosMemo {
+	name := fmt.Sprintf("memos/%d", moment.ID)
+	uid := fmt.Sprintf("%d", moment.ID)
+	created := formatTime(moment.CreatedAt)
+	updated := formatTime(moment.UpdatedAt)
+
+	resources := make([]model.MemosResource, 0, len(moment.Media))
+	for _, media := range moment.Media {
+		link := media.MediaURL
+		if strings.HasPrefix(link, "/") {
+			link = origin + link
+		}
+		filename := media.Name
+		if filename == "" {
+			filename = path.Base(media.MediaURL)
+		}
+		mimeType := mime.TypeByExtension(path.Ext(filename))
+		if mimeType == "" {
+			mimeType = media.MediaType + "/*"
+		}
+		resources = append(resources, model.MemosResource{
+			Name:         fmt.Sprintf("resources/%d", media.ID),
+			UID:          fmt.Sprintf("%d", media.ID),
+			CreateTime:   created,
+			Filename:     filename,
+			ExternalLink: link,
+			Type:         mimeType,
+			Size:         "0",
+			Memo:         name,
+		})
+	}
+
+	reactionTypes := make([]string, 0, len(moment.Reactions))
+	for reaction := range moment.Reactions {
+		reactionTypes = append(reactionTypes, reaction)
+	}
+	// 数量多的表情优先展开
+	sort.Slice(reactionTypes, func(i, j int) bool {
+		a, b := reactionTypes[i], reactionTypes[j]
+		if moment.Reactions[a] != moment.Reactions[b] {
+			return moment.Reactions[a] > moment.Reactions[b]
+		}
+		return a < b
+	})
+	reactions := []model.MemosReaction{}
+	for _, reaction := range reactionTypes {
+		count := min(moment.Reactions[reaction], maxReactionsPerType, maxReactions-len(reactions))
+		for i := 0; i < count; i++ {
+			reactions = append(reactions, model.MemosReaction{
+				ID:           len(reactions) + 1,
+				Creator:      "users/0",
+				ContentID:    name,
+				ReactionType: reaction,
+			})
+		}
+	}
+
+	tags := []string{}
+	for _, match := range tagPattern.FindAllStringSubmatch(moment.Content, -1) {
+		tags = append(tags, match[1])
+	}
+
+	return model.MemosMemo{
+		Name:        name,
+		UID:         uid,
+		RowStatus:   "ACTIVE",
+		State:       "NORMAL",
+		Creator:     memosCreator,
+		CreateTime:  created,
+		UpdateTime:  updated,
+		DisplayTime: created,
+		Content:     moment.Content,
+		Snippet:     snippet(moment.Content),
+		Visibility:  "PUBLIC",
+		Tags:        tags,
+		Resources:   resources,
+		Attachments: resources,
+		Relations:   []interface{}{},
+		Reactions:   reactions,
+	}
+}
+
+func formatTime(ts int64) string {
+	return time.Unix(ts, 0).UTC().Format(time.RFC3339)
+}
+
+func snippet(content string) string {
+	content = strings.Join(strings.Fields(content), " ")
+	if utf8.RuneCountInString(content) <= snippetRunes {
+		return content
+	}
+	return string([]rune(content)[:snippetRunes]) + "..."
+}
@@ -0,0 +1,58 @@
+package memosService
+
+import (
+	"blog_api/src/model"
+	"fmt"
+	"testing"
+)
+
+func TestToMemoReactions(t *testing.T) {
+	tests := []struct {
+		name      string
+		reactions map[string]int
+		want      map[string]int
+	}{
+		{
+			name:      "small counts are expanded in full",
+			reactions: map[string]int{"👍": 2, "🎉": 1},
+			want:      map[string]int{"👍": 2, "🎉": 1},
+		},
+		{
+			name:      "each type is capped",
+			reactions: map[string]int{"👍": 1000000, "🎉": 3},
+			want:      map[string]int{"👍": maxReactionsPerType, "🎉": 3},
+		},
+		{
+			name:      "types with fewer reactions are dropped once the total cap is reached",
+			reactions: manyTypes(maxReactions/maxReactionsPerType+1, maxReactionsPerType),
+			want:      manyTypes(maxReactions/maxReactionsPerType, maxReactionsPerType),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memo := ToMemo(model.MomentWithMedia{Moment: model.Moment{ID: 1}, Reactions: tt.reactions}, "")
+			got := map[string]int{}
+			for _, reaction := range memo.Reactions {
+				got[reaction.ReactionType]++
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("reactions = %v, want %v", got, tt.want)
+			}
+			for reaction, want := range tt.want {
+				if got[reaction] != want {
+					t.Errorf("%s = %d, want %d", reaction, got[reaction], want)
+				}
+			}
+		})
+	}
+}
+
+// manyTypes returns n reaction types r0, r1, ... with the given count each.
+func manyTypes(n, count int) map[string]int {
+	reactions := map[string]int{}
+	for i := 0; i < n; i++ {
+		reactions[fmt.Sprintf("r%d", i)] = count
+	}
+	return reactions
+}
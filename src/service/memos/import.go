@@ -0,0 +1,388 @@
+package memosService
+
+import (
+	"blog_api/src/model"
+	transferRepositories "blog_api/src/repositories/transfer"
+	"blog_api/src/service"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const sqliteHeader = "SQLite format 3\x00"
+
+// ErrInvalidExport 导入的文件既不是 memos 的 JSON 导出，也不是 memos 的 SQLite 数据库
+var ErrInvalidExport = errors.New("invalid memos export")
+
+// Options memos 导入选项
+type Options struct {
+	model.ImportOptions
+	// BaseURL memos 实例地址；附件既没有内嵌数据也没有外链时，用它拼出附件的下载地址
+	BaseURL string
+}
+
+// memo 导入时使用的 memos 动态，兼容 v0（createdTs / resourceList）与 v1（createTime / resources / attachments）
+type memo struct {
+	ID          int64           `json:"id"`
+	Name        string          `json:"name"`
+	Content     string          `json:"content"`
+	Visibility  string          `json:"visibility"`
+	RowStatus   string          `json:"rowStatus"`
+	State       string          `json:"state"`
+	CreatedTs   int64           `json:"createdTs"`
+	CreateTime  string          `json:"createTime"`
+	DisplayTime string          `json:"displayTime"`
+	Resources   []memosResource `json:"resources"`
+	Attachments []memosResource `json:"attachments"`
+	ResList     []memosResource `json:"resourceList"`
+}
+
+// memosResource memos 附件
+type memosResource struct {
+	ID           int64  `json:"id"`
+	Name         string `json:"name"` // v1: resources/{uid} 或 attachments/{uid}
+	UID          string `json:"uid"`
+	Filename     string `json:"filename"`
+	ExternalLink string `json:"externalLink"`
+	Type         string `json:"type"`
+	Content      string `json:"content"` // base64，仅在导出时包含内容才有
+	blob         []byte
+}
+
+// Import 将 memos 的 JSON 导出（ListMemos 响应或动态数组）或 SQLite 数据库导入为动态
+// 动态按内容与发布时间合并，附件按地址合并；内嵌在数据库中的附件保存到本地资源目录，
+// 已存在且内容不同的文件视为冲突，与记录一样按 opts.Overwrite 处理
+func Import(db *gorm.DB, cfg *model.Config, src io.ReaderAt, size int64, opts Options) (*model.ImportReport, error) {
+	header := make([]byte, len(sqliteHeader))
+	n, _ := src.ReadAt(header, 0)
+
+	var memos []memo
+	var err error
+	if n == len(header) && string(header) == sqliteHeader {
+		memos, err = readSQLite(src, size)
+	} else {
+		memos, err = readJSON(io.NewSectionReader(src, 0, size))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	report := model.NewImportReport(opts.DryRun)
+	bundle := &model.ExportBundle{Version: model.ExportVersion, Moments: make([]model.ExportMoment, 0, len(memos))}
+	resources := service.NewResourceService(cfg)
+	baseURL := strings.TrimRight(opts.BaseURL, "/")
+	var files []resourceFile
+	for _, item := range memos {
+		moment := model.ExportMoment{
+			Content:   item.Content,
+			Status:    momentStatus(item),
+			CreatedAt: createdAt(item),
+		}
+		for _, res := range append(append(item.Resources, item.Attachments...), item.ResList...) {
+			media, file, err := convertResource(res, resources, baseURL)
+			if err != nil {
+				report.Count("moment_media").Skipped++
+				report.Errors = append(report.Errors, fmt.Sprintf("moment_media %s: %v", resourceLabel(res), err))
+				continue
+			}
+			if file != nil {
+				files = append(files, *file)
+			}
+			moment.Media = append(moment.Media, media)
+		}
+		if moment.Content == "" && len(moment.Media) == 0 {
+			report.Count("moment").Skipped++
+			continue
+		}
+		bundle.Moments = append(bundle.Moments, moment)
+	}
+
+	if err := transferRepositories.Import(db, bundle, opts.ImportOptions, report); err != nil {
+		return nil, err
+	}
+	// 附件文件在动态导入后写入，跳过或导入失败的动态不会留下文件
+	writeResources(files, opts.ImportOptions, report)
+	return report, nil
+}
+
+// readJSON 解析 {"memos": [...]} 或 [...]
+func readJSON(r io.Reader) ([]memo, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimSpace(data)
+
+	var memos []memo
+	if len(data) > 0 && data[0] == '[' {
+		err = json.Unmarshal(data, &memos)
+	} else {
+		var resp struct {
+			Memos []memo `json:"memos"`
+		}
+		err = json.Unmarshal(data, &resp)
+		memos = resp.Memos
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidExport, err)
+	}
+	for i := range memos {
+		for _, list := range [][]memosResource{memos[i].Resources, memos[i].Attachments, memos[i].ResList} {
+			for j := range list {
+				if list[j].Content == "" {
+					continue
+				}
+				blob, err := base64.StdEncoding.DecodeString(list[j].Content)
+				if err != nil {
+					return nil, fmt.Errorf("%w: resource %s content is not base64", ErrInvalidExport, resourceLabel(list[j]))
+				}
+				list[j].blob = blob
+			}
+		}
+	}
+	return memos, nil
+}
+
+// readSQLite 读取 memos 的 memo 表与 resource（新版为 attachment）表，兼容各版本的列名差异
+func readSQLite(src io.ReaderAt, size int64) ([]memo, error) {
+	tmp, err := os.CreateTemp("", "memos-*.db")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, io.NewSectionReader(src, 0, size)); err != nil {
+		tmp.Close()
+		return nil, err
+	}
+	tmp.Close()
+
+	memosDB, err := gorm.Open(sqlite.Open("file:"+tmp.Name()+"?mode=ro"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidExport, err)
+	}
+	if sqlDB, err := memosDB.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+	if !memosDB.Migrator().HasTable("memo") {
+		return nil, fmt.Errorf("%w: memo table is missing", ErrInvalidExport)
+	}
+
+	var rows []map[string]interface{}
+	if err := memosDB.Table("memo").Order("id").Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("could not read memos: %w", err)
+	}
+	memos := make([]memo, 0, len(rows))
+	index := map[int64]int{}
+	for _, row := range rows {
+		item := memo{
+			ID:         toInt64(row["id"]),
+			Content:    toString(row["content"]),
+			Visibility: toString(row["visibility"]),
+			RowStatus:  toString(row["row_status"]),
+			CreatedTs:  toInt64(row["created_ts"]),
+		}
+		index[item.ID] = len(memos)
+		memos = append(memos, item)
+	}
+
+	table := ""
+	for _, name := range []string{"attachment", "resource"} {
+		if memosDB.Migrator().HasTable(name) {
+			table = name
+			break
+		}
+	}
+	if table == "" {
+		return memos, nil
+	}
+
+	var resRows []map[string]interface{}
+	if err := memosDB.Table(table).Order("id").Find(&resRows).Error; err != nil {
+		return nil, fmt.Errorf("could not read %s: %w", table, err)
+	}
+	for _, row := range resRows {
+		i, ok := index[toInt64(row["memo_id"])]
+		if !ok {
+			continue
+		}
+		uid := toString(row["uid"])
+		if uid == "" {
+			uid = toString(row["resource_name"])
+		}
+		res := memosResource{
+			ID:           toInt64(row["id"]),
+			UID:          uid,
+			Filename:     toString(row["filename"]),
+			ExternalLink: toString(row["external_link"]),
+			Type:         toString(row["type"]),
+		}
+		if uid != "" {
+			res.Name = table + "s/" + uid
+		}
+		switch blob := row["blob"].(type) {
+		case []byte:
+			res.blob = blob
+		case string:
+			res.blob = []byte(blob)
+		}
+		memos[i].Resources = append(memos[i].Resources, res)
+	}
+	return memos, nil
+}
+
+// convertResource 将附件转换为动态媒体；内嵌内容的附件同时返回待写入的文件
+func convertResource(res memosResource, resources *service.ResourceService, baseURL string) (model.ExportMomentMedia, *resourceFile, error) {
+	mediaType := ""
+	switch {
+	case strings.HasPrefix(res.Type, "image/"):
+		mediaType = "image"
+	case strings.HasPrefix(res.Type, "video/"):
+		mediaType = "video"
+	default:
+		return model.ExportMomentMedia{}, nil, fmt.Errorf("unsupported type %q", res.Type)
+	}
+
+	filename := path.Base("/" + res.Filename)
+	if filename == "/" {
+		filename = fmt.Sprintf("%d", res.ID)
+	}
+	media := model.ExportMomentMedia{Name: filename, MediaType: mediaType}
+
+	var file *resourceFile
+	switch {
+	case len(res.blob) > 0:
+		// 按附件标识保存到固定位置，重复导入时地址不变
+		key := res.UID
+		if key == "" {
+			key = fmt.Sprintf("%d", res.ID)
+		}
+		rel := path.Join("moments", "memos", path.Base("/"+key), filename)
+		target, err := resources.ResolveWritablePath(resources.BasePath(), filepath.FromSlash(rel))
+		if err != nil {
+			return model.ExportMomentMedia{}, nil, err
+		}
+		media.MediaURL = "/" + rel
+		media.IsLocal = 1
+		file = &resourceFile{url: media.MediaURL, target: target, data: res.blob}
+	case res.ExternalLink != "":
+		media.MediaURL = res.ExternalLink
+	case baseURL != "" && res.Name != "":
+		media.MediaURL = fmt.Sprintf("%s/file/%s/%s", baseURL, res.Name, filename)
+	case baseURL != "" && res.ID > 0:
+		media.MediaURL = fmt.Sprintf("%s/o/r/%d/%s", baseURL, res.ID, filename)
+	default:
+		return model.ExportMomentMedia{}, nil, errors.New("resource has no content or link; set base_url to link it from the memos instance")
+	}
+	return media, file, nil
+}
+
+// resourceFile 待写入资源目录的附件
+type resourceFile struct {
+	url    string // 动态媒体的 media_url
+	target string // 资源目录下的绝对路径
+	data   []byte
+}
+
+// writeResources 写入所属动态已导入（或已存在）的附件文件
+func writeResources(files []resourceFile, opts model.ImportOptions, report *model.ImportReport) {
+	for _, file := range files {
+		if !report.Media[file.url] {
+			continue
+		}
+		existing, err := os.ReadFile(file.target)
+		switch {
+		case err == nil && bytes.Equal(existing, file.data):
+			report.Count("file").Unchanged++
+			continue
+		case err == nil:
+			report.Conflict("file", file.url, []string{"content"}, opts.Overwrite)
+			if !opts.Overwrite {
+				continue
+			}
+		case errors.Is(err, os.ErrNotExist):
+			report.Count("file").Added++
+		default:
+			report.Count("file").Skipped++
+			report.Errors = append(report.Errors, fmt.Sprintf("file %s: %v", file.url, err))
+			continue
+		}
+
+		if opts.DryRun {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(file.target), 0o755); err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("file %s: %v", file.url, err))
+			continue
+		}
+		if err := os.WriteFile(file.target, file.data, 0o644); err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("file %s: %v", file.url, err))
+		}
+	}
+}
+
+// momentStatus 公开且未归档的动态可见，其余隐藏
+func momentStatus(item memo) string {
+	archived := strings.EqualFold(item.RowStatus, "ARCHIVED") || strings.EqualFold(item.State, "ARCHIVED")
+	if archived || (item.Visibility != "" && !strings.EqualFold(item.Visibility, "PUBLIC")) {
+		return "hidden"
+	}
+	return "visible"
+}
+
+func createdAt(item memo) int64 {
+	if item.CreatedTs > 0 {
+		return item.CreatedTs
+	}
+	for _, value := range []string{item.CreateTime, item.DisplayTime} {
+		if t, err := time.Parse(time.RFC3339, value); err == nil {
+			return t.Unix()
+		}
+	}
+	return time.Now().Unix()
+}
+
+func resourceLabel(res memosResource) string {
+	if res.Name != "" {
+		return res.Name
+	}
+	if res.Filename != "" {
+		return res.Filename
+	}
+	return fmt.Sprintf("%d", res.ID)
+}
+
+func toInt64(value interface{}) int64 {
+	switch v := value.(type) {
+	case int64:
+		return v
+	case int:
+		return int64(v)
+	case float64:
+		return int64(v)
+	}
+	return 0
+}
+
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	}
+	return ""
+}
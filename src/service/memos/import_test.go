@@ -0,0 +1,102 @@
+package memosService
+
+import (
+	"blog_api/src/model"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteResources(t *testing.T) {
+	tests := []struct {
+		name          string
+		existing      string // content of the target before the import, "" means absent
+		accepted      bool   // the media row was attached to an imported moment
+		opts          model.ImportOptions
+		want          string // content of the target after the import, "" means absent
+		wantCount     model.ImportCount
+		wantConflicts int
+	}{
+		{
+			name:      "file of an accepted moment is written",
+			accepted:  true,
+			want:      "new",
+			wantCount: model.ImportCount{Added: 1},
+		},
+		{
+			name: "file of a rejected moment is not written",
+			want: "",
+		},
+		{
+			name:      "identical file is unchanged",
+			existing:  "new",
+			accepted:  true,
+			want:      "new",
+			wantCount: model.ImportCount{Unchanged: 1},
+		},
+		{
+			name:          "conflict keeps local file by default",
+			existing:      "old",
+			accepted:      true,
+			want:          "old",
+			wantCount:     model.ImportCount{Skipped: 1},
+			wantConflicts: 1,
+		},
+		{
+			name:          "conflict is overwritten on request",
+			existing:      "old",
+			accepted:      true,
+			opts:          model.ImportOptions{Overwrite: true},
+			want:          "new",
+			wantCount:     model.ImportCount{Updated: 1},
+			wantConflicts: 1,
+		},
+		{
+			name:          "dry run writes nothing",
+			existing:      "old",
+			accepted:      true,
+			opts:          model.ImportOptions{DryRun: true, Overwrite: true},
+			want:          "old",
+			wantCount:     model.ImportCount{Updated: 1},
+			wantConflicts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := filepath.Join(t.TempDir(), "moments", "memos", "uid", "a.png")
+			if tt.existing != "" {
+				if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(target, []byte(tt.existing), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			file := resourceFile{url: "/moments/memos/uid/a.png", target: target, data: []byte("new")}
+			report := model.NewImportReport(tt.opts.DryRun)
+			if tt.accepted {
+				report.Media[file.url] = true
+			}
+
+			writeResources([]resourceFile{file}, tt.opts, report)
+
+			if got := *report.Count("file"); got != tt.wantCount {
+				t.Errorf("count = %+v, want %+v", got, tt.wantCount)
+			}
+			if len(report.Conflicts) != tt.wantConflicts {
+				t.Errorf("conflicts = %+v, want %d", report.Conflicts, tt.wantConflicts)
+			}
+			data, err := os.ReadFile(target)
+			if tt.want == "" {
+				if err == nil {
+					t.Errorf("file was written")
+				}
+				return
+			}
+			if err != nil || string(data) != tt.want {
+				t.Errorf("file = %q (%v), want %q", data, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package service
+
+import (
+	"blog_api/src/config"
+	"blog_api/src/model"
+	"math"
+	"sync"
+	"time"
+)
+
+// maxRateLimitWait 需要等待的时间上限，避免 per_minute 极小时换算溢出
+const maxRateLimitWait = 24 * time.Hour
+
+// RateLimitKey is the value a rule of a policy is counted under, e.g. {Key: "ip", Value: "1.2.3.4"}.
+type RateLimitKey struct {
+	Key   string
+	Value string
+}
+
+type rateLimitBucket struct {
+	tokens    float64
+	burst     float64
+	perMinute float64
+	last      time.Time
+}
+
+type loginFailure struct {
+	count       int
+	windowStart time.Time
+	lockedUntil time.Time
+}
+
+// rateLimiter 保存令牌桶与登录失败记录
+type rateLimiter struct {
+	mu       sync.Mutex
+	buckets  map[[3]string]*rateLimitBucket
+	failures map[string]*loginFailure
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{
+		buckets:  make(map[[3]string]*rateLimitBucket),
+		failures: make(map[string]*loginFailure),
+	}
+}
+
+var defaultRateLimiter = newRateLimiter()
+
+// AllowRequest 按 safe_conf.rate_limit.policies[policy] 为 keys 中的每个值各取一个令牌
+// 任意一个桶不足时不消耗任何令牌，返回 false 与需要等待的时间；未启用限流或策略为空时总是放行
+func AllowRequest(policy string, keys []RateLimitKey) (bool, time.Duration) {
+	return defaultRateLimiter.allow(config.GetConfig().Safe.RateLimit, policy, keys, time.Now())
+}
+
+// LoginLockedFor 返回该 IP 剩余的登录锁定时间，未锁定时为 0
+func LoginLockedFor(ip string) time.Duration {
+	return defaultRateLimiter.lockedFor(config.GetConfig().Safe.RateLimit, ip, time.Now())
+}
+
+// RecordLoginFailure 记录一次登录失败，在 login_window_seconds 内达到 login_max_failures 次后锁定该 IP
+// 返回锁定时长，未触发锁定时为 0
+func RecordLoginFailure(ip string) time.Duration {
+	return defaultRateLimiter.recordFailure(config.GetConfig().Safe.RateLimit, ip, time.Now())
+}
+
+// ResetLoginFailures 登录成功后清除该 IP 的失败记录
+func ResetLoginFailures(ip string) {
+	defaultRateLimiter.resetFailures(ip)
+}
+
+// sweepRateLimits 删除已经补满（与新建的桶没有区别）的令牌桶与过期的登录失败记录，返回删除的数量
+func sweepRateLimits(now time.Time) int {
+	return defaultRateLimiter.sweep(config.GetConfig().Safe.RateLimit, now)
+}
+
+func (l *rateLimiter) allow(conf model.RateLimitConfig, policy string, keys []RateLimitKey, now time.Time) (bool, time.Duration) {
+	if !conf.Enable {
+		return true, 0
+	}
+	rules := conf.Policies[policy]
+	if len(rules) == 0 {
+		return true, 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var buckets []*rateLimitBucket
+	var wait time.Duration
+	for _, rule := range rules {
+		// 配置加载时已过滤无效规则，这里再次跳过以免除以零
+		if rule.Burst <= 0 || !(rule.PerMinute > 0) {
+			continue
+		}
+		for _, key := range keys {
+			if key.Key != rule.Key || key.Value == "" {
+				continue
+			}
+			bucket := l.refill(policy, rule, key.Value, now)
+			if bucket.tokens < 1 {
+				minutes := (1 - bucket.tokens) / rule.PerMinute
+				need := maxRateLimitWait
+				if minutes < maxRateLimitWait.Minutes() {
+					need = time.Duration(minutes * float64(time.Minute))
+				}
+				wait = max(wait, need)
+			}
+			buckets = append(buckets, bucket)
+		}
+	}
+	if wait > 0 {
+		return false, wait
+	}
+	for _, bucket := range buckets {
+		bucket.tokens--
+	}
+	return true, 0
+}
+
+// refill 返回补充令牌后的桶，调用方需持有 l.mu
+func (l *rateLimiter) refill(policy string, rule model.RateLimitRule, value string, now time.Time) *rateLimitBucket {
+	id := [3]string{policy, rule.Key, value}
+	burst := float64(rule.Burst)
+	bucket, ok := l.buckets[id]
+	if !ok {
+		bucket = &rateLimitBucket{tokens: burst, last: now}
+		l.buckets[id] = bucket
+	}
+	elapsed := max(now.Sub(bucket.last).Minutes(), 0)
+	bucket.tokens = math.Min(bucket.tokens+elapsed*rule.PerMinute, burst)
+	bucket.burst = burst
+	bucket.perMinute = rule.PerMinute
+	bucket.last = now
+	return bucket
+}
+
+func (l *rateLimiter) lockedFor(conf model.RateLimitConfig, ip string, now time.Time) time.Duration {
+	if !conf.Enable {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if failure, ok := l.failures[ip]; ok {
+		return max(failure.lockedUntil.Sub(now), 0)
+	}
+	return 0
+}
+
+func (l *rateLimiter) recordFailure(conf model.RateLimitConfig, ip string, now time.Time) time.Duration {
+	if !conf.Enable || conf.LoginMaxFailures <= 0 {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	failure, ok := l.failures[ip]
+	if !ok || now.Sub(failure.windowStart) > time.Duration(conf.LoginWindowSeconds)*time.Second {
+		failure = &loginFailure{windowStart: now, lockedUntil: lockedUntilOf(failure)}
+		l.failures[ip] = failure
+	}
+	failure.count++
+	if failure.count < conf.LoginMaxFailures {
+		return 0
+	}
+	lockout := time.Duration(conf.LoginLockoutSeconds) * time.Second
+	failure.count = 0
+	failure.windowStart = now
+	failure.lockedUntil = now.Add(lockout)
+	return lockout
+}
+
+func lockedUntilOf(failure *loginFailure) time.Time {
+	if failure == nil {
+		return time.Time{}
+	}
+	return failure.lockedUntil
+}
+
+func (l *rateLimiter) resetFailures(ip string) {
+	l.mu.Lock()
+	delete(l.failures, ip)
+	l.mu.Unlock()
+}
+
+func (l *rateLimiter) sweep(conf model.RateLimitConfig, now time.Time) int {
+	window := time.Duration(conf.LoginWindowSeconds) * time.Second
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var count int
+	for id, bucket := range l.buckets {
+		if bucket.tokens+now.Sub(bucket.last).Minutes()*bucket.perMinute >= bucket.burst {
+			delete(l.buckets, id)
+			count++
+		}
+	}
+	for ip, failure := range l.failures {
+		if now.After(failure.lockedUntil) && now.Sub(failure.windowStart) > window {
+			delete(l.failures, ip)
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"blog_api/src/model"
+	"testing"
+	"time"
+)
+
+func testRateLimitConfig() model.RateLimitConfig {
+	return model.RateLimitConfig{
+		Enable: true,
+		Policies: map[string][]model.RateLimitRule{
+			"verify_email": {
+				{Key: "ip", Burst: 3, PerMinute: 6},
+				{Key: "email", Burst: 2, PerMinute: 1},
+			},
+			"broken": {
+				{Key: "ip", Burst: 1, PerMinute: 0},
+			},
+		},
+		LoginMaxFailures:    3,
+		LoginWindowSeconds:  60,
+		LoginLockoutSeconds: 120,
+	}
+}
+
+func TestAllowRequest(t *testing.T) {
+	base := time.Unix(1_700_000_000, 0)
+	ip := func(v string) RateLimitKey { return RateLimitKey{Key: "ip", Value: v} }
+	email := func(v string) RateLimitKey { return RateLimitKey{Key: "email", Value: v} }
+
+	type step struct {
+		at       time.Duration
+		keys     []RateLimitKey
+		allowed  bool
+		wantWait time.Duration
+	}
+	tests := []struct {
+		name   string
+		policy string
+		mutate func(*model.RateLimitConfig)
+		steps  []step
+	}{
+		{
+			name:   "burst then refill",
+			policy: "verify_email",
+			steps: []step{
+				{0, []RateLimitKey{ip("1.1.1.1")}, true, 0},
+				{0, []RateLimitKey{ip("1.1.1.1")}, true, 0},
+				{0, []RateLimitKey{ip("1.1.1.1")}, true, 0},
+				{0, []RateLimitKey{ip("1.1.1.1")}, false, 10 * time.Second},
+				{0, []RateLimitKey{ip("2.2.2.2")}, true, 0},
+				{10 * time.Second, []RateLimitKey{ip("1.1.1.1")}, true, 0},
+				{10 * time.Second, []RateLimitKey{ip("1.1.1.1")}, false, 10 * time.Second},
+			},
+		},
+		{
+			name:   "exhausted email bucket does not consume ip tokens",
+			policy: "verify_email",
+			steps: []step{
+				{0, []RateLimitKey{ip("1.1.1.1"), email("a@x.com")}, true, 0},
+				{0, []RateLimitKey{ip("1.1.1.1"), email("a@x.com")}, true, 0},
+				{0, []RateLimitKey{ip("1.1.1.1"), email("a@x.com")}, false, time.Minute},
+				{0, []RateLimitKey{ip("1.1.1.1"), email("b@x.com")}, true, 0},
+				{0, []RateLimitKey{ip("1.1.1.1"), email("c@x.com")}, false, 10 * time.Second},
+			},
+		},
+		{
+			name:   "empty key values are not counted",
+			policy: "verify_email",
+			steps: []step{
+				{0, []RateLimitKey{email("")}, true, 0},
+				{0, []RateLimitKey{email("")}, true, 0},
+				{0, []RateLimitKey{email("")}, true, 0},
+			},
+		},
+		{
+			name:   "unknown policy is unlimited",
+			policy: "missing",
+			steps: []step{
+				{0, []RateLimitKey{ip("1.1.1.1")}, true, 0},
+				{0, []RateLimitKey{ip("1.1.1.1")}, true, 0},
+			},
+		},
+		{
+			name:   "non-positive per_minute is skipped",
+			policy: "broken",
+			steps: []step{
+				{0, []RateLimitKey{ip("1.1.1.1")}, true, 0},
+				{0, []RateLimitKey{ip("1.1.1.1")}, true, 0},
+			},
+		},
+		{
+			name:   "disabled",
+			policy: "verify_email",
+			mutate: func(c *model.RateLimitConfig) { c.Enable = false },
+			steps: []step{
+				{0, []RateLimitKey{email("a@x.com")}, true, 0},
+				{0, []RateLimitKey{email("a@x.com")}, true, 0},
+				{0, []RateLimitKey{email("a@x.com")}, true, 0},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := testRateLimitConfig()
+			if tt.mutate != nil {
+				tt.mutate(&conf)
+			}
+			l := newRateLimiter()
+			for i, s := range tt.steps {
+				allowed, wait := l.allow(conf, tt.policy, s.keys, base.Add(s.at))
+				if allowed != s.allowed || wait != s.wantWait {
+					t.Fatalf("step %d: got (%v, %s), want (%v, %s)", i, allowed, wait, s.allowed, s.wantWait)
+				}
+			}
+		})
+	}
+}
+
+func TestRecordLoginFailure(t *testing.T) {
+	base := time.Unix(1_700_000_000, 0)
+	lockout := 120 * time.Second
+
+	type step struct {
+		at          time.Duration
+		action      string // fail, reset
+		wantLockout time.Duration
+		wantLocked  time.Duration
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "locks after max failures",
+			steps: []step{
+				{0, "fail", 0, 0},
+				{time.Second, "fail", 0, 0},
+				{2 * time.Second, "fail", lockout, lockout},
+				{62 * time.Second, "check", 0, lockout - 60*time.Second},
+				{122 * time.Second, "check", 0, 0},
+			},
+		},
+		{
+			name: "failures outside the window start a new count",
+			steps: []step{
+				{0, "fail", 0, 0},
+				{time.Second, "fail", 0, 0},
+				{62 * time.Second, "fail", 0, 0},
+				{63 * time.Second, "fail", 0, 0},
+				{64 * time.Second, "fail", lockout, lockout},
+			},
+		},
+		{
+			name: "success resets the count",
+			steps: []step{
+				{0, "fail", 0, 0},
+				{time.Second, "fail", 0, 0},
+				{2 * time.Second, "reset", 0, 0},
+				{3 * time.Second, "fail", 0, 0},
+				{4 * time.Second, "fail", 0, 0},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := testRateLimitConfig()
+			l := newRateLimiter()
+			for i, s := range tt.steps {
+				now := base.Add(s.at)
+				switch s.action {
+				case "fail":
+					if got := l.recordFailure(conf, "1.1.1.1", now); got != s.wantLockout {
+						t.Fatalf("step %d: lockout = %s, want %s", i, got, s.wantLockout)
+					}
+				case "reset":
+					l.resetFailures("1.1.1.1")
+				}
+				if got := l.lockedFor(conf, "1.1.1.1", now); got != s.wantLocked {
+					t.Fatalf("step %d: locked for %s, want %s", i, got, s.wantLocked)
+				}
+				if got := l.lockedFor(conf, "2.2.2.2", now); got != 0 {
+					t.Fatalf("step %d: other IP locked for %s", i, got)
+				}
+			}
+		})
+	}
+}
+
+func TestSweepRateLimits(t *testing.T) {
+	base := time.Unix(1_700_000_000, 0)
+	conf := testRateLimitConfig()
+	l := newRateLimiter()
+
+	l.allow(conf, "verify_email", []RateLimitKey{{Key: "ip", Value: "1.1.1.1"}}, base)
+	l.allow(conf, "verify_email", []RateLimitKey{{Key: "email", Value: "a@x.com"}}, base)
+	for range 3 {
+		l.recordFailure(conf, "1.1.1.1", base)
+	}
+	l.recordFailure(conf, "2.2.2.2", base)
+
+	tests := []struct {
+		name        string
+		at          time.Duration
+		wantRemoved int
+		wantBuckets int
+		wantFailure int
+	}{
+		{"nothing idle yet", 5 * time.Second, 0, 2, 2},
+		// ip bucket refills after 10s, email bucket after 1m, the 2.2.2.2 window ends after 60s
+		{"ip bucket refilled", 10 * time.Second, 1, 1, 2},
+		{"email bucket refilled and window over", 61 * time.Second, 2, 0, 1},
+		{"lockout over", 121 * time.Second, 1, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := l.sweep(conf, base.Add(tt.at)); got != tt.wantRemoved {
+				t.Fatalf("removed %d, want %d", got, tt.wantRemoved)
+			}
+			if len(l.buckets) != tt.wantBuckets || len(l.failures) != tt.wantFailure {
+				t.Fatalf("left %d buckets and %d failures, want %d and %d", len(l.buckets), len(l.failures), tt.wantBuckets, tt.wantFailure)
+			}
+		})
+	}
+}
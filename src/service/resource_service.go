@@ -145,75 +145,106 @@ func (s *ResourceService) findUniqueFilename(dir, filename string) string {
 // DeleteFile 删除指定路径的文件。
 // 在删除前会进行严格的安全检查，以防止删除受保护的文件。
 func (s *ResourceService) DeleteFile(filePath string) error {
-	// 1. 解析资源根目录（通常是 data），删除目标必须位于该目录内
-	basePath := s.config.Data.Resource.Path
-	if basePath == "" {
-		basePath = "data/"
+	cleanPath, err := s.resolvePath(s.BasePath(), filePath)
+	if err != nil {
+		return err
+	}
+
+	// 检查文件是否存在
+	if _, err := os.Stat(cleanPath); os.IsNotExist(err) {
+		return fmt.Errorf("%w: '%s'", ErrResourceNotFound, filePath)
+	}
+
+	// 执行删除
+	if err := os.Remove(cleanPath); err != nil {
+		return fmt.Errorf("删除文件失败: %w", err)
+	}
+
+	return nil
+}
+
+// ResolveWritablePath 校验写入 basePath 下 filePath 的请求并返回目标的绝对路径。
+// 与删除相同，目标必须位于 basePath 内且不在受保护的目录中；此外扩展名必须在白名单内。
+func (s *ResourceService) ResolveWritablePath(basePath, filePath string) (string, error) {
+	if strings.TrimSpace(basePath) == "" {
+		return "", fmt.Errorf("%w: 未配置目标目录", ErrInvalidResourcePath)
+	}
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(filePath), "."))
+	if !s.isExtensionAllowed(ext) {
+		return "", fmt.Errorf("%w: 文件类型 '%s' 不被允许", ErrProtectedResource, ext)
+	}
+	return s.resolvePath(basePath, filePath)
+}
+
+// BasePath 返回资源目录，未配置时为 data/
+func (s *ResourceService) BasePath() string {
+	if s.config.Data.Resource.Path == "" {
+		return "data/" // 默认路径
 	}
+	return s.config.Data.Resource.Path
+}
+
+// resolvePath 将 filePath 解析为 basePath 下的绝对路径，越出 basePath 或位于受保护目录内时返回错误
+func (s *ResourceService) resolvePath(basePath, filePath string) (string, error) {
+	// 1. 目标必须位于 basePath 内
 	absBasePath, err := filepath.Abs(filepath.Clean(basePath))
 	if err != nil {
-		return fmt.Errorf("获取资源根目录失败: %w", err)
+		return "", fmt.Errorf("获取资源根目录失败: %w", err)
 	}
 
 	relativePath := filepath.Clean(filePath)
 	if relativePath == "." || relativePath == string(filepath.Separator) {
-		return fmt.Errorf("%w: %s", ErrInvalidResourcePath, filePath)
+		return "", fmt.Errorf("%w: %s", ErrInvalidResourcePath, filePath)
 	}
 	// 将绝对输入路径按相对路径处理，避免绕过 basePath 校验
 	relativePath = strings.TrimLeft(relativePath, `/\`)
 
 	cleanPath, err := filepath.Abs(filepath.Join(absBasePath, relativePath))
 	if err != nil {
-		return fmt.Errorf("获取目标绝对路径失败: %w", err)
+		return "", fmt.Errorf("获取目标绝对路径失败: %w", err)
 	}
 	baseWithSep := absBasePath + string(filepath.Separator)
 	if cleanPath != absBasePath && !strings.HasPrefix(cleanPath, baseWithSep) {
-		return fmt.Errorf("%w: %s", ErrInvalidResourcePath, filePath)
+		return "", fmt.Errorf("%w: %s", ErrInvalidResourcePath, filePath)
 	}
 
 	// 2. 检查路径是否在受保护的目录内
-	for _, protectedPath := range s.config.Safe.ExcludePaths {
-		isProtected := false
-		candidates := []string{}
+	for _, candidate := range s.protectedPaths(absBasePath) {
+		candidateWithSep := candidate + string(filepath.Separator)
+		if cleanPath == candidate || strings.HasPrefix(cleanPath, candidateWithSep) {
+			return "", fmt.Errorf("%w: '%s'", ErrProtectedResource, filePath)
+		}
+	}
+	return cleanPath, nil
+}
 
-		absProtectedPath, err := filepath.Abs(filepath.Clean(protectedPath))
-		if err == nil {
+// protectedPaths 返回受保护目录的绝对路径：safe_conf.exclude_paths（含数据库与备份目录）、配置目录与管理面板
+func (s *ResourceService) protectedPaths(absBasePath string) []string {
+	var candidates []string
+	for _, protectedPath := range s.config.Safe.ExcludePaths {
+		if absProtectedPath, err := filepath.Abs(filepath.Clean(protectedPath)); err == nil {
 			candidates = append(candidates, absProtectedPath)
 		}
 
 		// 兼容以资源根为基准的写法（如 "/config"）
 		relativeProtectedPath := strings.TrimLeft(filepath.Clean(protectedPath), `/\`)
 		if relativeProtectedPath != "" && relativeProtectedPath != "." {
-			absProtectedFromBase, err := filepath.Abs(filepath.Join(absBasePath, relativeProtectedPath))
-			if err == nil {
+			if absProtectedFromBase, err := filepath.Abs(filepath.Join(absBasePath, relativeProtectedPath)); err == nil {
 				candidates = append(candidates, absProtectedFromBase)
 			}
 		}
-
-		for _, candidate := range candidates {
-			candidateWithSep := candidate + string(filepath.Separator)
-			if cleanPath == candidate || strings.HasPrefix(cleanPath, candidateWithSep) {
-				isProtected = true
-				break
-			}
-		}
-
-		if isProtected {
-			return fmt.Errorf("%w: '%s'", ErrProtectedResource, filePath)
-		}
 	}
 
-	// 3. 检查文件是否存在
-	if _, err := os.Stat(cleanPath); os.IsNotExist(err) {
-		return fmt.Errorf("%w: '%s'", ErrResourceNotFound, filePath)
+	configPath := s.config.ConfigPath
+	if configPath == "" {
+		configPath = "data/config"
 	}
-
-	// 4. 执行删除
-	if err := os.Remove(cleanPath); err != nil {
-		return fmt.Errorf("删除文件失败: %w", err)
+	for _, protectedPath := range []string{configPath, filepath.Join(s.BasePath(), "panel")} {
+		if absProtectedPath, err := filepath.Abs(protectedPath); err == nil {
+			candidates = append(candidates, absProtectedPath)
+		}
 	}
-
-	return nil
+	return candidates
 }
 
 // GetFileOrDir 检索文件或列出目录的内容。
@@ -0,0 +1,221 @@
+package service
+
+import (
+	"blog_api/src/model"
+	tokenRepositories "blog_api/src/repositories/token"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TokenStore keeps short-lived values under (kind, key) until they expire.
+// Expired entries are never returned; Sweep removes them from storage.
+type TokenStore interface {
+	// Put stores value, replacing any entry under the same kind and key.
+	Put(kind, key, value string, expiresAt int64) error
+	// Get returns the value without removing it.
+	Get(kind, key string) (string, bool, error)
+	// Take returns the value and removes it; of several concurrent calls only one succeeds.
+	Take(kind, key string) (string, bool, error)
+	// Delete removes the entry if present.
+	Delete(kind, key string) error
+	// Sweep removes entries that expired at or before now and reports how many were removed.
+	Sweep(now int64) (int64, error)
+}
+
+// 令牌类型
+const (
+	tokenKindAntiBot   = "antibot"
+	tokenKindEmailCode = "email_code"
+	tokenKindEmail     = "email_token"
+)
+
+var (
+	tokenStoreMu sync.RWMutex
+	tokenStore   TokenStore = NewMemoryTokenStore()
+	sweepCancel  context.CancelFunc
+	sweepDone    chan struct{}
+)
+
+// InitTokenStore 按 safe_conf.token_store 选择令牌存储并启动过期清理（同时清理空闲的限流计数），修改配置后需重启生效
+func InitTokenStore(db *gorm.DB, conf model.SafeConfig) {
+	var store TokenStore
+	switch conf.TokenStore {
+	case "memory":
+		store = NewMemoryTokenStore()
+	case "sqlite":
+		store = NewSQLiteTokenStore(db)
+	default:
+		log.Printf("[token] 未知的 safe_conf.token_store %q，使用 sqlite", conf.TokenStore)
+		store = NewSQLiteTokenStore(db)
+	}
+	SetTokenStore(store)
+	log.Printf("[token] 令牌存储: %s，每 %d 秒清理过期令牌", conf.TokenStore, conf.TokenSweepSeconds)
+
+	StopTokenSweeper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	tokenStoreMu.Lock()
+	sweepCancel, sweepDone = cancel, done
+	tokenStoreMu.Unlock()
+	go runTokenSweeper(ctx, time.Duration(conf.TokenSweepSeconds)*time.Second, done)
+}
+
+// StopTokenSweeper 停止过期清理并等待当前的清理结束
+func StopTokenSweeper() {
+	tokenStoreMu.Lock()
+	cancel, done := sweepCancel, sweepDone
+	sweepCancel, sweepDone = nil, nil
+	tokenStoreMu.Unlock()
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// SetTokenStore 替换令牌存储，已签发的令牌不会迁移
+func SetTokenStore(store TokenStore) {
+	tokenStoreMu.Lock()
+	tokenStore = store
+	tokenStoreMu.Unlock()
+}
+
+func currentTokenStore() TokenStore {
+	tokenStoreMu.RLock()
+	defer tokenStoreMu.RUnlock()
+	return tokenStore
+}
+
+func runTokenSweeper(ctx context.Context, interval time.Duration, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if count, err := currentTokenStore().Sweep(time.Now().Unix()); err != nil {
+				log.Printf("[token][ERR] 清理过期令牌失败: %v", err)
+			} else if count > 0 {
+				log.Printf("[token] 已清理 %d 个过期令牌", count)
+			}
+			sweepRateLimits(time.Now())
+		}
+	}
+}
+
+// hashTokenKey 令牌本身作为键时只保存其哈希，数据库或备份泄露后无法直接使用
+func hashTokenKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+type memoryTokenEntry struct {
+	value     string
+	expiresAt int64
+}
+
+// MemoryTokenStore keeps tokens in process memory; they are lost on restart and not shared between instances.
+type MemoryTokenStore struct {
+	mu      sync.Mutex
+	entries map[[2]string]memoryTokenEntry
+}
+
+// NewMemoryTokenStore creates an empty in-memory token store.
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{entries: make(map[[2]string]memoryTokenEntry)}
+}
+
+func (s *MemoryTokenStore) Put(kind, key, value string, expiresAt int64) error {
+	s.mu.Lock()
+	s.entries[[2]string{kind, key}] = memoryTokenEntry{value: value, expiresAt: expiresAt}
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *MemoryTokenStore) Get(kind, key string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[[2]string{kind, key}]
+	if !ok || entry.expiresAt <= time.Now().Unix() {
+		return "", false, nil
+	}
+	return entry.value, true, nil
+}
+
+func (s *MemoryTokenStore) Take(kind, key string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[[2]string{kind, key}]
+	if !ok {
+		return "", false, nil
+	}
+	delete(s.entries, [2]string{kind, key})
+	if entry.expiresAt <= time.Now().Unix() {
+		return "", false, nil
+	}
+	return entry.value, true, nil
+}
+
+func (s *MemoryTokenStore) Delete(kind, key string) error {
+	s.mu.Lock()
+	delete(s.entries, [2]string{kind, key})
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *MemoryTokenStore) Sweep(now int64) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var count int64
+	for id, entry := range s.entries {
+		if entry.expiresAt <= now {
+			delete(s.entries, id)
+			count++
+		}
+	}
+	return count, nil
+}
+
+// SQLiteTokenStore keeps tokens in the tokens table, so they survive restarts and can be shared
+// by every instance using the same database.
+type SQLiteTokenStore struct {
+	db *gorm.DB
+}
+
+// NewSQLiteTokenStore creates a token store backed by db.
+func NewSQLiteTokenStore(db *gorm.DB) *SQLiteTokenStore {
+	return &SQLiteTokenStore{db: db}
+}
+
+func (s *SQLiteTokenStore) Put(kind, key, value string, expiresAt int64) error {
+	return tokenRepositories.PutToken(s.db, model.Token{
+		Kind:      kind,
+		Key:       key,
+		Value:     value,
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now().Unix(),
+	})
+}
+
+func (s *SQLiteTokenStore) Get(kind, key string) (string, bool, error) {
+	return tokenRepositories.GetToken(s.db, kind, key, time.Now().Unix())
+}
+
+func (s *SQLiteTokenStore) Take(kind, key string) (string, bool, error) {
+	return tokenRepositories.TakeToken(s.db, kind, key, time.Now().Unix())
+}
+
+func (s *SQLiteTokenStore) Delete(kind, key string) error {
+	return tokenRepositories.DeleteToken(s.db, kind, key)
+}
+
+func (s *SQLiteTokenStore) Sweep(now int64) (int64, error) {
+	return tokenRepositories.DeleteExpiredTokens(s.db, now)
+}
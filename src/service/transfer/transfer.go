@@ -0,0 +1,294 @@
+package transferService
+
+import (
+	"archive/zip"
+	"blog_api/src/model"
+	transferRepositories "blog_api/src/repositories/transfer"
+	"blog_api/src/service"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+const (
+	bundleFile    = "bundle.json"
+	resourceDir   = "media/resource/"
+	imageDir      = "media/image/"
+	imageURLPath  = "/image/"
+	maxEntrySize  = 512 << 20 // 单个 zip 条目的大小上限
+	maxTotalSize  = 4 << 30   // zip 中全部条目解压后的总大小上限
+	zipFileHeader = "PK\x03\x04"
+)
+
+var (
+	// ErrInvalidBundle 导入的文件不是有效的导出包
+	ErrInvalidBundle = errors.New("invalid export bundle")
+	// ErrUnsupportedVersion 导出包的格式版本比当前程序新
+	ErrUnsupportedVersion = errors.New("unsupported export bundle version")
+)
+
+// Dirs 本地媒体文件所在的目录
+type Dirs struct {
+	Resource string // data_conf.resource.path，动态媒体的 media_url 相对于此目录
+	Image    string // data_conf.image.path，图片的 /image/ 地址相对于此目录
+	// Resources 校验导入的媒体文件路径，与上传和删除资源使用相同的根目录、受保护目录与扩展名检查
+	Resources *service.ResourceService
+}
+
+// ExportJSON 将全部内容以 JSON 写入 w
+func ExportJSON(db *gorm.DB, w io.Writer) error {
+	bundle, err := transferRepositories.Export(db)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(bundle)
+}
+
+// ExportZip 将全部内容与本地媒体文件打包为 zip 写入 w
+// zip 中包含 bundle.json，动态的本地媒体位于 media/resource/，本地图片位于 media/image/
+func ExportZip(db *gorm.DB, dirs Dirs, w io.Writer) error {
+	bundle, err := transferRepositories.Export(db)
+	if err != nil {
+		return err
+	}
+
+	archive := zip.NewWriter(w)
+	entry, err := archive.Create(bundleFile)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(entry)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(bundle); err != nil {
+		return err
+	}
+
+	written := map[string]bool{}
+	addFile := func(name, src string) error {
+		if written[name] {
+			return nil
+		}
+		written[name] = true
+		file, err := os.Open(src)
+		if err != nil {
+			log.Printf("[Transfer] 跳过无法读取的媒体文件 %s: %v", src, err)
+			return nil
+		}
+		defer file.Close()
+		entry, err := archive.Create(name)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(entry, file)
+		return err
+	}
+
+	for _, moment := range bundle.Moments {
+		for _, media := range moment.Media {
+			rel, ok := localRel(media.IsLocal, media.MediaURL, "/")
+			if !ok {
+				continue
+			}
+			if err := addFile(resourceDir+rel, filepath.Join(dirs.Resource, filepath.FromSlash(rel))); err != nil {
+				return err
+			}
+		}
+	}
+	for _, image := range bundle.Images {
+		rel, ok := localRel(image.IsLocal, image.URL, imageURLPath)
+		if !ok {
+			continue
+		}
+		// local_path 可被修改，只读取图片目录内的文件，否则按 /image/ 地址推导
+		src := image.LocalPath
+		if src == "" || !underDir(dirs.Image, src) {
+			src = filepath.Join(dirs.Image, filepath.FromSlash(rel))
+		}
+		if err := addFile(imageDir+rel, src); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
+
+// Import 合并导入 JSON 或 zip 导出包，zip 中的媒体文件会写入对应目录
+// 已存在且内容不同的文件视为冲突，与记录一样按 opts.Overwrite 处理
+func Import(db *gorm.DB, dirs Dirs, r io.ReaderAt, size int64, opts model.ImportOptions) (*model.ImportReport, error) {
+	header := make([]byte, len(zipFileHeader))
+	n, _ := r.ReadAt(header, 0)
+
+	var archive *zip.Reader
+	var bundleReader io.Reader = io.NewSectionReader(r, 0, size)
+	if n == len(header) && string(header) == zipFileHeader {
+		var err error
+		archive, err = zip.NewReader(r, size)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+		}
+		// 解压时 archive/zip 会校验实际大小不超过条目声明的大小，因此按声明的大小限制总量即可
+		var total uint64
+		for _, file := range archive.File {
+			total += file.UncompressedSize64
+			if file.UncompressedSize64 > maxTotalSize || total > maxTotalSize {
+				return nil, fmt.Errorf("%w: uncompressed size exceeds %d bytes", ErrInvalidBundle, uint64(maxTotalSize))
+			}
+		}
+		file, err := archive.Open(bundleFile)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s is missing", ErrInvalidBundle, bundleFile)
+		}
+		defer file.Close()
+		bundleReader = file
+	}
+
+	var bundle model.ExportBundle
+	if err := json.NewDecoder(bundleReader).Decode(&bundle); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+	}
+	if bundle.Version <= 0 {
+		return nil, fmt.Errorf("%w: version is missing", ErrInvalidBundle)
+	}
+	if bundle.Version > model.ExportVersion {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, bundle.Version)
+	}
+
+	// 本地图片在新实例上的路径由 /image/ 地址推导，不采用导出包中的 local_path
+	for i, image := range bundle.Images {
+		bundle.Images[i].LocalPath = ""
+		if rel, ok := localRel(image.IsLocal, image.URL, imageURLPath); ok {
+			bundle.Images[i].LocalPath = filepath.Join(dirs.Image, filepath.FromSlash(rel))
+		}
+	}
+
+	report := model.NewImportReport(opts.DryRun)
+	if err := transferRepositories.Import(db, &bundle, opts, report); err != nil {
+		return nil, err
+	}
+	if archive != nil {
+		importFiles(archive, dirs, opts, report)
+	}
+	return report, nil
+}
+
+// importFiles 写入 zip 中的媒体文件
+func importFiles(archive *zip.Reader, dirs Dirs, opts model.ImportOptions, report *model.ImportReport) {
+	for _, file := range archive.File {
+		var base, rel string
+		switch {
+		case strings.HasPrefix(file.Name, resourceDir):
+			base, rel = dirs.Resource, strings.TrimPrefix(file.Name, resourceDir)
+		case strings.HasPrefix(file.Name, imageDir):
+			base, rel = dirs.Image, strings.TrimPrefix(file.Name, imageDir)
+		default:
+			continue
+		}
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		clean := path.Clean("/" + rel)
+		if clean == "/" || clean != "/"+rel {
+			report.Count("file").Skipped++
+			report.Errors = append(report.Errors, fmt.Sprintf("file %s: invalid path", file.Name))
+			continue
+		}
+		target, err := dirs.Resources.ResolveWritablePath(base, filepath.FromSlash(rel))
+		if err != nil {
+			report.Count("file").Skipped++
+			report.Errors = append(report.Errors, fmt.Sprintf("file %s: %v", file.Name, err))
+			continue
+		}
+
+		data, err := readEntry(file)
+		if err != nil {
+			report.Count("file").Skipped++
+			report.Errors = append(report.Errors, fmt.Sprintf("file %s: %v", file.Name, err))
+			continue
+		}
+
+		existing, err := os.ReadFile(target)
+		switch {
+		case err == nil && bytes.Equal(existing, data):
+			report.Count("file").Unchanged++
+			continue
+		case err == nil:
+			report.Conflict("file", file.Name, []string{"content"}, opts.Overwrite)
+			if !opts.Overwrite {
+				continue
+			}
+		case errors.Is(err, os.ErrNotExist):
+			report.Count("file").Added++
+		default:
+			report.Count("file").Skipped++
+			report.Errors = append(report.Errors, fmt.Sprintf("file %s: %v", file.Name, err))
+			continue
+		}
+
+		if opts.DryRun {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("file %s: %v", file.Name, err))
+			continue
+		}
+		if err := os.WriteFile(target, data, 0o644); err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("file %s: %v", file.Name, err))
+		}
+	}
+}
+
+func readEntry(file *zip.File) ([]byte, error) {
+	if file.UncompressedSize64 > maxEntrySize {
+		return nil, fmt.Errorf("file is larger than %d bytes", maxEntrySize)
+	}
+	reader, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(io.LimitReader(reader, maxEntrySize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxEntrySize {
+		return nil, fmt.Errorf("file is larger than %d bytes", maxEntrySize)
+	}
+	return data, nil
+}
+
+// localRel 返回本地媒体地址去掉前缀后的相对路径；不是本地文件或路径不安全时返回 false
+func localRel(isLocal int, url, prefix string) (string, bool) {
+	if isLocal != 1 || !strings.HasPrefix(url, prefix) {
+		return "", false
+	}
+	rel := strings.TrimPrefix(url, prefix)
+	clean := path.Clean("/" + rel)
+	if rel == "" || clean != "/"+rel {
+		return "", false
+	}
+	return rel, true
+}
+
+// underDir 判断 name 是否位于 dir 目录内
+func underDir(dir, name string) bool {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	absName, err := filepath.Abs(name)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(absDir, absName)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,223 @@
+package transferService
+
+import (
+	"archive/zip"
+	"blog_api/src/model"
+	"blog_api/src/service"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestImportFiles(t *testing.T) {
+	tests := []struct {
+		name          string
+		entries       map[string]string
+		existing      map[string]string // relative to the data dir
+		opts          model.ImportOptions
+		want          map[string]string // relative to the data dir, "" means absent
+		wantCount     model.ImportCount
+		wantErrors    int
+		wantConflicts int
+	}{
+		{
+			name:      "new files are written below their base dir",
+			entries:   map[string]string{"media/resource/moments/a.png": "a", "media/image/2024/b.png": "b"},
+			want:      map[string]string{"moments/a.png": "a", "image/2024/b.png": "b"},
+			wantCount: model.ImportCount{Added: 2},
+		},
+		{
+			name:      "identical file is unchanged",
+			entries:   map[string]string{"media/resource/a.png": "same"},
+			existing:  map[string]string{"a.png": "same"},
+			want:      map[string]string{"a.png": "same"},
+			wantCount: model.ImportCount{Unchanged: 1},
+		},
+		{
+			name:          "conflict keeps local file by default",
+			entries:       map[string]string{"media/resource/a.png": "new"},
+			existing:      map[string]string{"a.png": "old"},
+			want:          map[string]string{"a.png": "old"},
+			wantCount:     model.ImportCount{Skipped: 1},
+			wantConflicts: 1,
+		},
+		{
+			name:          "conflict is overwritten on request",
+			entries:       map[string]string{"media/resource/a.png": "new"},
+			existing:      map[string]string{"a.png": "old"},
+			opts:          model.ImportOptions{Overwrite: true},
+			want:          map[string]string{"a.png": "new"},
+			wantCount:     model.ImportCount{Updated: 1},
+			wantConflicts: 1,
+		},
+		{
+			name:      "dry run writes nothing",
+			entries:   map[string]string{"media/resource/a.png": "a"},
+			opts:      model.ImportOptions{DryRun: true},
+			want:      map[string]string{"a.png": ""},
+			wantCount: model.ImportCount{Added: 1},
+		},
+		{
+			name: "protected and disallowed targets are rejected even with overwrite",
+			entries: map[string]string{
+				"media/resource/config/system_config.json": "pwn",
+				"media/resource/panel/assets/index.js":     "pwn",
+				"media/resource/backup/a.png":              "pwn",
+				"media/resource/database.db":               "pwn",
+				"media/resource/run.sh":                    "pwn",
+			},
+			existing: map[string]string{
+				"config/system_config.json": "{}",
+				"panel/assets/index.js":     "ok",
+				"database.db":               "db",
+			},
+			opts: model.ImportOptions{Overwrite: true},
+			want: map[string]string{
+				"config/system_config.json": "{}",
+				"panel/assets/index.js":     "ok",
+				"backup/a.png":              "",
+				"database.db":               "db",
+				"run.sh":                    "",
+			},
+			wantCount:  model.ImportCount{Skipped: 5},
+			wantErrors: 5,
+		},
+		{
+			name: "unclean paths are rejected",
+			entries: map[string]string{
+				"media/resource/../escape.png":  "pwn",
+				"media/resource/a/../../b.png":  "pwn",
+				"media/resource//abs.png":       "pwn",
+				"media/image/../database.png":   "pwn",
+				"media/resource/./dot/a.png":    "pwn",
+				"unrelated/ignored/outside.png": "pwn",
+			},
+			want: map[string]string{
+				"../escape.png": "",
+				"../b.png":      "",
+				"abs.png":       "",
+				"database.png":  "",
+				"dot/a.png":     "",
+			},
+			wantCount:  model.ImportCount{Skipped: 5},
+			wantErrors: 5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dataDir := filepath.Join(t.TempDir(), "data")
+			for name, content := range tt.existing {
+				writeTestFile(t, filepath.Join(dataDir, name), content)
+			}
+			cfg := &model.Config{ConfigPath: filepath.Join(dataDir, "config")}
+			cfg.Data.Resource.Path = dataDir
+			cfg.Safe.AllowExtension = []string{"png", "json", "js"}
+			cfg.Safe.ExcludePaths = []string{filepath.Join(dataDir, "database.db"), filepath.Join(dataDir, "backup")}
+			dirs := Dirs{Resource: dataDir, Image: filepath.Join(dataDir, "image"), Resources: service.NewResourceService(cfg)}
+
+			report := model.NewImportReport(tt.opts.DryRun)
+			importFiles(testArchive(t, tt.entries), dirs, tt.opts, report)
+
+			if got := *report.Count("file"); got != tt.wantCount {
+				t.Errorf("count = %+v, want %+v", got, tt.wantCount)
+			}
+			if len(report.Errors) != tt.wantErrors {
+				t.Errorf("errors = %q, want %d", report.Errors, tt.wantErrors)
+			}
+			if len(report.Conflicts) != tt.wantConflicts {
+				t.Errorf("conflicts = %+v, want %d", report.Conflicts, tt.wantConflicts)
+			}
+			for name, want := range tt.want {
+				data, err := os.ReadFile(filepath.Join(dataDir, name))
+				if want == "" {
+					if err == nil {
+						t.Errorf("%s was written", name)
+					}
+					continue
+				}
+				if err != nil || string(data) != want {
+					t.Errorf("%s = %q (%v), want %q", name, data, err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLocalRel(t *testing.T) {
+	tests := []struct {
+		isLocal int
+		url     string
+		prefix  string
+		want    string
+		ok      bool
+	}{
+		{1, "/image/2024/a.png", "/image/", "2024/a.png", true},
+		{0, "/image/2024/a.png", "/image/", "", false},
+		{1, "https://example.com/a.png", "/", "", false},
+		{1, "/image/", "/image/", "", false},
+		{1, "/image/../config/system_config.json", "/image/", "", false},
+		{1, "/moments//a.png", "/", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := localRel(tt.isLocal, tt.url, tt.prefix)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("localRel(%d, %q, %q) = %q, %v; want %q, %v", tt.isLocal, tt.url, tt.prefix, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestUnderDir(t *testing.T) {
+	tests := []struct {
+		dir  string
+		name string
+		want bool
+	}{
+		{"data/image", "data/image/2024/a.png", true},
+		{"data/image", "./data/image/a.png", true},
+		{"data/image", "data/image", false},
+		{"data/image", "data/image/../config/system_config.json", false},
+		{"data/image", "data/images/a.png", false},
+		{"data/image", "/etc/passwd", false},
+		{"data/image", "data/image/..a.png", true},
+	}
+	for _, tt := range tests {
+		if got := underDir(tt.dir, tt.name); got != tt.want {
+			t.Errorf("underDir(%q, %q) = %v, want %v", tt.dir, tt.name, got, tt.want)
+		}
+	}
+}
+
+func testArchive(t *testing.T, entries map[string]string) *zip.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, content := range entries {
+		entry, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := entry.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func writeTestFile(t *testing.T, name, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}